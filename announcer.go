@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	slack "wordassassin/slack"
+	types "wordassassin/types"
+	events "wordassassin/types/events"
+)
+
+// announcer posts the public milestones of each game to the Slack channel it's bound to, as the game makes the news.
+// Games with no channel go unannounced. Announcements go out one at a time, in the order they were made, so a
+// channel reads the way its game went. One that fails is logged and dropped.
+type announcer struct {
+	client  slack.Client
+	logger  *log.Logger
+	mu      sync.Mutex // guards queue
+	queue   []slack.Message
+	wake    chan struct{}
+	pending sync.WaitGroup // announcements still on their way
+}
+
+func newAnnouncer(client slack.Client, logger *log.Logger) *announcer {
+	a := &announcer{client: client, logger: logger, wake: make(chan struct{}, 1)}
+	go a.run()
+	return a
+}
+
+// announce is the GameListener. It's called on the game's actor, so it only queues the announcements
+func (a *announcer) announce(news types.GameNews) {
+	if news.Game.Channel == "" {
+		return
+	}
+	msgs := announcements(news)
+	if len(msgs) == 0 {
+		return
+	}
+	a.mu.Lock()
+	a.pending.Add(len(msgs))
+	a.queue = append(a.queue, msgs...)
+	a.mu.Unlock()
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run posts the queued announcements, for as long as the server runs
+func (a *announcer) run() {
+	for range a.wake {
+		for {
+			a.mu.Lock()
+			if len(a.queue) == 0 {
+				a.mu.Unlock()
+				break
+			}
+			msg := a.queue[0]
+			a.queue = a.queue[1:]
+			a.mu.Unlock()
+			if _, err := a.client.PostMessage(context.Background(), msg.Channel, msg); err != nil {
+				a.logger.Printf("announce: posting to %s failed: %v", msg.Channel, err)
+			}
+			a.pending.Done()
+		}
+	}
+}
+
+// wait returns once every announcement made so far has gone out, or failed to
+func (a *announcer) wait() {
+	a.pending.Wait()
+}
+
+// announcements renders the news of a game for its channel. Only the milestones everyone gets to hear about make it:
+// the game opening, each player joining, the start, each death along with who did it and with what word, and the
+// winner
+func announcements(news types.GameNews) (msgs []slack.Message) {
+	game := news.Game
+	channel, id := game.Channel, game.GetID()
+	switch ev := news.Event.(type) {
+	case *events.GameCreatedEvent:
+		msgs = append(msgs, announcement(channel, fmt.Sprintf("New game %s. Join with /wa join %s", id, id),
+			slack.Header("New game: %s", id),
+			slack.Section("%s is getting a game of Word Assassin together. Join with `/wa join %s`", slack.Mention(game.GameCreator), id),
+			slack.Context("Kill words come from %s. It takes %s to start", game.KillDictionary, countPlayers(game.MinimumPlayers))))
+	case *events.PlayerAddedEvent:
+		msgs = append(msgs, announcement(channel, fmt.Sprintf("%s joined %s", ev.SlackID, id),
+			slack.Section("%s joined *%s*. That's %s so far", slack.Mention(ev.SlackID), id, countPlayers(game.StartPlayers))))
+	case *events.GameStartedEvent:
+		msgs = append(msgs, announcement(channel, fmt.Sprintf("%s has begun", id),
+			slack.Header("%s has begun", id),
+			slack.Section("%d assassins are on the hunt. Your target is in your direct messages, or ask with `/wa target %s`", game.StartPlayers, id)))
+	case *events.KillReportedEvent:
+		victim, found := news.Player(ev.PlayerID)
+		if !found {
+			break
+		}
+		assassin, found := news.Player(victim.KilledBy)
+		if !found {
+			break
+		}
+		msgs = append(msgs, announcement(channel, fmt.Sprintf("%s was assassinated in %s", victim.SlackID, id),
+			slack.Section(":dagger_knife: %s was assassinated by %s. The kill word was *%s*", slack.Mention(victim.SlackID), slack.Mention(assassin.SlackID), victim.KilledWith),
+			slack.Context("%d left standing in %s", game.RemainPlayers, id)))
+		if winner, won := news.Player(game.Winner); won && game.Status == types.Finished {
+			msgs = append(msgs, announcement(channel, fmt.Sprintf("%s won %s", winner.SlackID, id),
+				slack.Header(":trophy: %s is over", id),
+				slack.Section("%s is the last assassin standing, with %d kills", slack.Mention(winner.SlackID), winner.Kills),
+				slack.Context("Played for %s", game.GetDuration().Round(time.Second))))
+		}
+	}
+	return
+}
+
+// announcement makes a message for a channel from its blocks, with the text to show in notifications
+func announcement(channel string, text string, blocks ...slack.Block) slack.Message {
+	return slack.Message{Channel: channel, Text: text, Blocks: blocks}
+}
+
+// countPlayers words a number of players
+func countPlayers(n int) string {
+	if n == 1 {
+		return "1 player"
+	}
+	return fmt.Sprintf("%d players", n)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dao "wordassassin/persistence"
+	"wordassassin/slack"
+)
+
+func TestAnnouncements(t *testing.T) {
+	ctx := context.Background()
+	store := dao.NewMemorySession()
+	logBuf := &bytes.Buffer{}
+	posted := &slack.RecordingClient{}
+	pp := newPlayerPool(t, store)
+	testHandler := NewHandler(newGamePool(t, store, pp), store, log.New(logBuf, "announcer_test: ", 0), posted)
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	_, _, err := testHandler.OnDictionaryCreated(ctx, "afile.txt", words)
+	require.NoError(t, err)
+
+	// Play a game in the channel through to the end, along with one that isn't in a channel
+	for _, gameid := range []string{"public", "private"} {
+		channel := ""
+		if gameid == "public" {
+			channel = "Cgame"
+		}
+		require.NoError(t, testHandler.OnGameCreated(ctx, gameid, "UBOSS", "afile.txt", "melod", channel))
+		for i := 0; i < 5; i++ {
+			_, err = testHandler.OnPlayerAdded(ctx, gameid, fmt.Sprintf("UHIT%d", i), "", "")
+			require.NoError(t, err)
+		}
+		require.NoError(t, testHandler.OnGameStarted(ctx, gameid, "UBOSS"))
+	}
+	var deaths []string
+	for victim := "UHIT0"; ; {
+		require.NoError(t, testHandler.OnKillReported(ctx, "public", victim))
+		dead, _ := pp.GetPlayer("public", slack.SlackID(victim))
+		assassin, _ := pp.GetPlayerByID(dead.KilledBy)
+		deaths = append(deaths, fmt.Sprintf(":dagger_knife: <@%s> was assassinated by <@%s>. The kill word was *%s*", victim, assassin.SlackID, dead.KilledWith))
+		if assassin.Target == "" {
+			break
+		}
+		next, _ := pp.GetPlayerByID(assassin.Target)
+		victim = next.SlackID.ToString()
+	}
+	testHandler.FlushMessages()
+
+	announced := posted.Posted("Cgame")
+	require.Len(t, announced, 12, "Created, 5 joined, started, 4 deaths and the winner")
+	for _, p := range posted.Posted() {
+		if p.Channel[0] != 'D' {
+			require.Equal(t, "Cgame", p.Channel, "Games with no channel aren't announced")
+		}
+	}
+	section := func(i int) string {
+		for _, b := range announced[i].Message.Blocks {
+			if b.Type == "section" {
+				return b.Text.Text
+			}
+		}
+		return ""
+	}
+	require.Equal(t, "header", announced[0].Message.Blocks[0].Type)
+	require.Equal(t, "New game: public", announced[0].Message.Blocks[0].Text.Text)
+	require.Contains(t, section(0), "`/wa join public`")
+	require.Equal(t, "<@UHIT0> joined *public*. That's 1 player so far", section(1))
+	require.Equal(t, "<@UHIT4> joined *public*. That's 5 players so far", section(5))
+	require.Equal(t, "public has begun", announced[6].Message.Text)
+	for i, death := range deaths {
+		require.Equal(t, death, section(7+i))
+	}
+	require.Equal(t, "context", announced[10].Message.Blocks[1].Type)
+	require.Equal(t, ":trophy: public is over", announced[11].Message.Blocks[0].Text.Text)
+	game, _ := testHandler.gPool.GetGame("public")
+	winner, _ := pp.GetPlayerByID(game.Winner)
+	require.Equal(t, fmt.Sprintf("<@%s> is the last assassin standing, with %d kills", winner.SlackID, winner.Kills), section(11))
+	for _, a := range announced {
+		require.NotEmpty(t, a.Message.Text, "Every announcement has text for notifications")
+	}
+
+	t.Run("Slack failures are logged", func(t *testing.T) {
+		posted.FailWith = errors.New("(mock) not_in_channel")
+		defer func() { posted.FailWith = nil }()
+		require.NoError(t, testHandler.OnGameCreated(ctx, "unheard", "UBOSS", "afile.txt", "melod", "Cgame"))
+		testHandler.FlushMessages()
+		require.Contains(t, logBuf.String(), "announce: posting to Cgame failed: (mock) not_in_channel")
+	})
+}
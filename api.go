@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	types "wordassassin/types"
+)
+
+const (
+	// apiPrefix is where the versioned JSON API lives
+	apiPrefix string = "/api/v1"
+	// statusClientClosedRequest is the unofficial status for a request the client gave up on before it was answered
+	statusClientClosedRequest int = 499
+)
+
+// Error codes carried in APIError bodies, so clients don't have to parse messages
+const (
+	errCodeInvalid       string = "invalid_request"
+	errCodeNotAuthorized string = "not_authorized"
+	errCodeNotFound      string = "not_found"
+	errCodeDuplicate     string = "duplicate"
+	errCodeInvalidState  string = "invalid_state"
+	errCodeConflict      string = "conflict"
+	errCodeUnavailable   string = "unavailable"
+	errCodeTimeout       string = "timeout"
+	errCodeCancelled     string = "cancelled"
+	errCodeInternal      string = "internal"
+)
+
+// APIError is the body of every JSON error response
+type APIError struct {
+	Error APIErrorDetail `json:"error"`
+}
+
+// APIErrorDetail says what went wrong, as a stable code for programs and a message for people
+type APIErrorDetail struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Request bodies for the JSON API
+
+type createGameRequest struct {
+	GameID         string `json:"gameId"`
+	Creator        string `json:"creator"`
+	KillDictionary string `json:"killDictionary"`
+	Passcode       string `json:"passcode"`
+	Channel        string `json:"channel"`
+}
+
+type playerRequest struct {
+	SlackID string `json:"slackId"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+}
+
+type wordsRequest struct {
+	DictID string   `json:"dictId"`
+	Words  []string `json:"words"`
+}
+
+// Response bodies for the JSON API, where there's no view to send back
+
+type playerAddedResponse struct {
+	GameID  string `json:"gameId"`
+	SlackID string `json:"slackId"`
+	Token   string `json:"token"`
+}
+
+type wordsResponse struct {
+	DictID   string   `json:"dictId"`
+	Added    int      `json:"added"`
+	Rejected []string `json:"rejected"`
+}
+
+// errorStatus works out the HTTP status and error code for a handler error from its kind. Errors of no known kind
+// are the server's fault. A request that ran out of time, or was given up on, says so rather than blaming the store
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, types.ErrNotAuthorized):
+		return http.StatusForbidden, errCodeNotAuthorized
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, errCodeTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, errCodeCancelled
+	case errors.Is(err, types.ErrStoreUnavailable):
+		return http.StatusServiceUnavailable, errCodeUnavailable
+	case errors.Is(err, types.ErrDuplicate):
+		return http.StatusConflict, errCodeDuplicate
+	case errors.Is(err, types.ErrNotFound):
+		return http.StatusNotFound, errCodeNotFound
+	case errors.Is(err, types.ErrInvalidState):
+		return http.StatusConflict, errCodeInvalidState
+	case errors.Is(err, types.ErrConflict):
+		return http.StatusConflict, errCodeConflict
+	case errors.Is(err, types.ErrInvalidArgument):
+		return http.StatusBadRequest, errCodeInvalid
+	}
+	return http.StatusInternalServerError, errCodeInternal
+}
+
+// wantsJSON decides whether the caller would rather have JSON than HTML. The API always does; elsewhere it's up to
+// the Accept header
+func wantsJSON(c echo.Context) bool {
+	if strings.HasPrefix(c.Request().URL.Path, apiPrefix) {
+		return true
+	}
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	return strings.Contains(accept, echo.MIMEApplicationJSON) && !strings.Contains(accept, "text/html")
+}
+
+// respond sends data as JSON, or the html to people
+func respond(c echo.Context, status int, html string, data interface{}) error {
+	if wantsJSON(c) {
+		return c.JSON(status, data)
+	}
+	return c.HTML(status, html)
+}
+
+// respondError logs a handler error, and sends it with the status that fits it
+func respondError(c echo.Context, op string, err error) error {
+	logger.Printf("%s error: %s", op, err.Error())
+	status, code := errorStatus(err)
+	return respondStatus(c, status, code, err.Error())
+}
+
+// respondStatus sends an error that didn't come from the handler
+func respondStatus(c echo.Context, status int, code string, message string) error {
+	if wantsJSON(c) {
+		return c.JSON(status, APIError{Error: APIErrorDetail{Status: status, Code: code, Message: message}})
+	}
+	return c.HTML(status, message)
+}
+
+// bindBody decodes a JSON request body, answering with a 400 if it can't
+func bindBody(c echo.Context, body interface{}) (ok bool, err error) {
+	if bindErr := c.Bind(body); bindErr != nil {
+		return false, respondStatus(c, http.StatusBadRequest, errCodeInvalid, fmt.Sprintf("Request body is not valid JSON: %v", bindErr))
+	}
+	return true, nil
+}
+
+func apiListGames(c echo.Context) error {
+	return c.JSON(http.StatusOK, handler.GetGameViews())
+}
+
+func apiCreateGame(c echo.Context) error {
+	var req createGameRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	if err := handler.OnGameCreated(c.Request().Context(), req.GameID, req.Creator, req.KillDictionary, req.Passcode, req.Channel); err != nil {
+		return respondError(c, "OnGameCreated", err)
+	}
+	return apiGameView(c, http.StatusCreated, req.GameID)
+}
+
+func apiGetGame(c echo.Context) error {
+	return apiGameView(c, http.StatusOK, c.Param("gameid"))
+}
+
+func apiStartGame(c echo.Context) error {
+	var req playerRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	if err := handler.OnGameStarted(c.Request().Context(), c.Param("gameid"), req.SlackID); err != nil {
+		return respondError(c, "OnGameStarted", err)
+	}
+	return apiGameView(c, http.StatusOK, c.Param("gameid"))
+}
+
+func apiAbortGame(c echo.Context) error {
+	var req playerRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	if err := handler.OnGameAborted(c.Request().Context(), c.Param("gameid"), req.SlackID); err != nil {
+		return respondError(c, "OnGameAborted", err)
+	}
+	return apiGameView(c, http.StatusOK, c.Param("gameid"))
+}
+
+func apiAddPlayer(c echo.Context) error {
+	var req playerRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	gameid := c.Param("gameid")
+	token, err := handler.OnPlayerAdded(c.Request().Context(), gameid, req.SlackID, req.Name, req.Email)
+	if err != nil {
+		return respondError(c, "OnPlayerAdded", err)
+	}
+	return c.JSON(http.StatusCreated, playerAddedResponse{GameID: gameid, SlackID: req.SlackID, Token: token})
+}
+
+func apiRemovePlayer(c echo.Context) error {
+	if ok, err := authorizePlayer(c, c.Param("gameid"), c.Param("slackid")); !ok {
+		return err
+	}
+	if err := handler.OnPlayerRemoved(c.Request().Context(), c.Param("gameid"), c.Param("slackid")); err != nil {
+		return respondError(c, "OnPlayerRemoved", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func apiReportKill(c echo.Context) error {
+	var req playerRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	if ok, err := authorizePlayer(c, c.Param("gameid"), req.SlackID); !ok {
+		return err
+	}
+	if err := handler.OnKillReported(c.Request().Context(), c.Param("gameid"), req.SlackID); err != nil {
+		return respondError(c, "OnKillReported", err)
+	}
+	return apiGameView(c, http.StatusOK, c.Param("gameid"))
+}
+
+func apiListDictionaries(c echo.Context) error {
+	dicts, err := handler.GetDictionaries(c.Request().Context())
+	if err != nil {
+		return respondError(c, "GetDictionaries", err)
+	}
+	return c.JSON(http.StatusOK, dicts)
+}
+
+func apiCreateDictionary(c echo.Context) error {
+	var req wordsRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	added, rejected, err := handler.OnDictionaryCreated(c.Request().Context(), req.DictID, req.Words)
+	if err != nil {
+		return respondError(c, "OnDictionaryCreated", err)
+	}
+	return c.JSON(http.StatusCreated, wordsResponse{DictID: req.DictID, Added: added, Rejected: rejected})
+}
+
+func apiDeleteDictionary(c echo.Context) error {
+	if err := handler.OnDictionaryDeleted(c.Request().Context(), c.Param("dictid")); err != nil {
+		return respondError(c, "OnDictionaryDeleted", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func apiAddWords(c echo.Context) error {
+	var req wordsRequest
+	if ok, err := bindBody(c, &req); !ok {
+		return err
+	}
+	dictid := c.Param("dictid")
+	added, rejected, err := handler.OnWordsAdded(c.Request().Context(), dictid, req.Words)
+	if err != nil {
+		return respondError(c, "OnWordsAdded", err)
+	}
+	return c.JSON(http.StatusOK, wordsResponse{DictID: dictid, Added: added, Rejected: rejected})
+}
+
+func apiRemoveWord(c echo.Context) error {
+	if err := handler.OnWordRemoved(c.Request().Context(), c.Param("dictid"), c.Param("word")); err != nil {
+		return respondError(c, "OnWordRemoved", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// apiGameView answers with the current view of a game, or a 404
+func apiGameView(c echo.Context, status int, gameid string) error {
+	view, exists := handler.GetGameView(gameid)
+	if !exists {
+		return respondStatus(c, http.StatusNotFound, errCodeNotFound, fmt.Sprintf("Game %s not found", gameid))
+	}
+	return c.JSON(status, view)
+}
+
+func setAPIRoutes(e *echo.Echo) {
+	api := e.Group(apiPrefix)
+	api.GET   ("/games", apiListGames)
+	api.POST  ("/games", apiCreateGame)
+	api.GET   ("/games/:gameid", apiGetGame)
+	api.POST  ("/games/:gameid/abort", apiAbortGame)
+	api.POST  ("/games/:gameid/kills", apiReportKill)
+	api.POST  ("/games/:gameid/players", apiAddPlayer)
+	api.DELETE("/games/:gameid/players/:slackid", apiRemovePlayer)
+	api.GET   ("/games/:gameid/players/:slackid/target", getTarget)
+	api.POST  ("/games/:gameid/start", apiStartGame)
+	api.GET   ("/dictionaries", apiListDictionaries)
+	api.POST  ("/dictionaries", apiCreateDictionary)
+	api.DELETE("/dictionaries/:dictid", apiDeleteDictionary)
+	api.POST  ("/dictionaries/:dictid/words", apiAddWords)
+	api.DELETE("/dictionaries/:dictid/words/:word", apiRemoveWord)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+
+	dao "wordassassin/persistence"
+	"wordassassin/types"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"Not found", dao.Errorf(types.ErrNotFound, "The requested GameID: nope doesn't exist on this server"), http.StatusNotFound, errCodeNotFound},
+		{"Duplicate", dao.Errorf(types.ErrDuplicate, "Game dupe already created"), http.StatusConflict, errCodeDuplicate},
+		{"Invalid state", dao.Errorf(types.ErrInvalidState, "GameID: g is not accepting players. State=playing"), http.StatusConflict, errCodeInvalidState},
+		{"Conflict", dao.Errorf(types.ErrConflict, "Update failed. ID=g in collection games was changed since version 3"), http.StatusConflict, errCodeConflict},
+		{"Invalid argument", dao.Errorf(types.ErrInvalidArgument, "The request is missing GameID field"), http.StatusBadRequest, errCodeInvalid},
+		{"Not authorized", dao.Errorf(types.ErrNotAuthorized, "GameID: g cannot be started by non-creator"), http.StatusForbidden, errCodeNotAuthorized},
+		{"Unavailable", dao.Errorf(types.ErrStoreUnavailable, "no reachable servers"), http.StatusServiceUnavailable, errCodeUnavailable},
+		{"Wrapped", fmt.Errorf("OnKillReported: Mongodb write issue: %w", dao.Errorf(types.ErrStoreUnavailable, "Mock error on write")), http.StatusServiceUnavailable, errCodeUnavailable},
+		{"Timed out", fmt.Errorf("OnKillReported: %w", dao.Errorf(types.ErrStoreUnavailable, "Write abandoned: %w", context.DeadlineExceeded)), http.StatusGatewayTimeout, errCodeTimeout},
+		{"Cancelled", dao.Errorf(types.ErrStoreUnavailable, "GameID: g command abandoned: %w", context.Canceled), statusClientClosedRequest, errCodeCancelled},
+		{"Message alone", fmt.Errorf("duplicate key, not found, not authorized"), http.StatusInternalServerError, errCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := errorStatus(tt.err)
+			require.Equal(t, tt.status, status)
+			require.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestAPI_GameFlow(t *testing.T) {
+	e, mm := getServerWithMocks(t)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	decodeError := func(rec *httptest.ResponseRecorder) APIErrorDetail {
+		var body APIError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+		return body.Error
+	}
+
+	rec := call(http.MethodPost, "/api/v1/games", `{"gameId": "apigame", "creator": "UBOSS", "killDictionary": "afile.txt", "passcode": "sesame"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var view GameView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	require.Equal(t, "apigame", view.ID)
+	require.Equal(t, "starting", view.Status)
+	require.NotContains(t, rec.Body.String(), "sesame", "The passcode stays private")
+
+	// Mongo's unique index on events is what catches a game created twice
+	mm.WriteMode = "duplicate"
+	rec = call(http.MethodPost, "/api/v1/games", `{"gameId": "apigame", "creator": "UBOSS", "killDictionary": "afile.txt", "passcode": "sesame"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Equal(t, errCodeDuplicate, decodeError(rec).Code)
+	mm.WriteMode = "positive"
+
+	rec = call(http.MethodPost, "/api/v1/games", `{"creator": "UBOSS"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, errCodeInvalid, decodeError(rec).Code)
+
+	rec = call(http.MethodPost, "/api/v1/games", `not json`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	tokens := map[string]string{}
+	for i := 0; i < 5; i++ {
+		slackid := fmt.Sprintf("UAPI%d", i)
+		rec = call(http.MethodPost, "/api/v1/games/apigame/players", fmt.Sprintf(`{"slackId": "%s", "name": "Player %d"}`, slackid, i))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var added playerAddedResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &added))
+		require.NotEmpty(t, added.Token)
+		tokens[slackid] = added.Token
+	}
+	rec = call(http.MethodPost, "/api/v1/games/nogame/players", `{"slackId": "UAPI9"}`)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(http.MethodPost, "/api/v1/games/apigame/start", `{"slackId": "UNOTBOSS"}`)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = call(http.MethodPost, "/api/v1/games/apigame/start", `{"slackId": "UBOSS"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	require.Equal(t, "playing", view.Status)
+	require.NotNil(t, view.StartTime)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/games/apigame/players/UAPI0/target", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens["UAPI0"])
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var target TargetAssignment
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &target))
+	require.NotEmpty(t, target.KillWord)
+
+	rec = call(http.MethodGet, "/api/v1/games/apigame/players/UAPI0/target", "")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, errCodeNotAuthorized, decodeError(rec).Code)
+
+	asPlayer := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	rec = call(http.MethodPost, "/api/v1/games/apigame/kills", `{"slackId": "UAPI1"}`)
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "Nobody reports a kill without the victim's token")
+	rec = asPlayer(http.MethodPost, "/api/v1/games/apigame/kills", `{"slackId": "UAPI1"}`, tokens["UAPI2"])
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, errCodeNotAuthorized, decodeError(rec).Code)
+	rec = asPlayer(http.MethodPost, "/api/v1/games/apigame/kills", `{"slackId": "UAPI1"}`, tokens["UAPI1"])
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	require.Equal(t, 4, view.RemainPlayers)
+	rec = asPlayer(http.MethodPost, "/api/v1/games/apigame/kills", `{"slackId": "UAPI1"}`, tokens["UAPI1"])
+	require.Equal(t, http.StatusConflict, rec.Code, "Can't die twice")
+	rec = call(http.MethodDelete, "/api/v1/games/apigame/players/UAPI2", "")
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "Nobody leaves a game without the player's token")
+	rec = asPlayer(http.MethodDelete, "/api/v1/games/apigame/players/UAPI2", "", tokens["UAPI3"])
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = asPlayer(http.MethodDelete, "/api/v1/games/apigame/players/UAPI2", "", tokens["UAPI2"])
+	require.Equal(t, http.StatusConflict, rec.Code, "Too late to leave once the game started")
+
+	rec = call(http.MethodGet, "/api/v1/games", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var views []GameView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &views))
+	require.Len(t, views, 1)
+
+	rec = call(http.MethodGet, "/api/v1/games/nogame", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, errCodeNotFound, decodeError(rec).Code)
+}
+
+func TestAPI_ContentNegotiation(t *testing.T) {
+	e, _ := getServerWithMocks(t)
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set(echo.HeaderAccept, accept)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/gamelist", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<h2>Games List</h2>", "People get HTML by default")
+
+	rec = get("/gamelist", "text/html,application/json;q=0.9")
+	require.Contains(t, rec.Body.String(), "<h2>Games List</h2>", "Browsers get HTML")
+
+	rec = get("/gamelist", echo.MIMEApplicationJSON)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "[]", strings.TrimSpace(rec.Body.String()))
+
+	rec = get("/gamestatus/nogame", echo.MIMEApplicationJSON)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Contains(t, rec.Body.String(), `"code":"not_found"`)
+
+	req := httptest.NewRequest(http.MethodPost, "/startgame/nogame/UBOSS", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code, "HTML routes get real status codes too")
+	require.Contains(t, rec.Body.String(), "doesn't exist")
+}
+
+func TestAPI_AbandonedRequests(t *testing.T) {
+	e, mm := getServerWithMocks(t)
+	call := func(ctx context.Context) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/dictionaries", strings.NewReader(`{"dictId": "late", "words": ["apple"]}`))
+		req = req.WithContext(ctx)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := call(ctx)
+	require.Equal(t, statusClientClosedRequest, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"code":"cancelled"`)
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	rec = call(ctx)
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"code":"timeout"`)
+	require.Empty(t, mm.Written, "Nothing is written for a request that has gone")
+}
+
+// getServerWithMocks wires up the server's routes, and the handler behind them, to real pools over mock mongo
+func getServerWithMocks(t *testing.T) (*echo.Echo, *dao.MockMongoSession) {
+	mm := dao.NewMockMongoSession()
+	mm.CollectionResults = map[string][]dao.Persistable{types.CollectionName: mockDictionary("afile.txt", 50)}
+	pp := newPlayerPool(t, mm)
+	logger = log.New(&bytes.Buffer{}, "api_test: ", 0)
+	handler = NewHandler(newGamePool(t, mm, pp), mm, logger)
+	e := echo.New()
+	setRoutes(e)
+	setAPIRoutes(e)
+	return e, mm
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"io"
 	"time"
 	"log"
 
@@ -17,12 +19,21 @@ import (
 type Handler struct {
 	gPool	 types.GamePoolAbstraction
 	mongo 	 persistence.MongoAbstraction
+	events   *types.EventRepository
 	logger   *log.Logger
-	// other stuff?
+	dm       *messenger // nil when there's no Slack client
+	news     *announcer // likewise
+	pending  *pendingKills
+	seen     *presence
 }
 
-// NewHandler creates a handler instance using the injected dependencies (hint, hint: they're for testing)
-func NewHandler(gp types.GamePoolAbstraction, m persistence.MongoAbstraction, l *log.Logger) (h *Handler) {
+// conflictRetries is how many more times a change to a game is tried when it conflicts with a change made elsewhere
+const conflictRetries = 3
+
+// NewHandler creates a handler instance using the injected dependencies (hint, hint: they're for testing). Pass a
+// Slack client to send players their targets in direct messages, when the game starts and whenever they take one over,
+// and announce the milestones of games bound to a channel there, as the game pool makes the news
+func NewHandler(gp types.GamePoolAbstraction, m persistence.MongoAbstraction, l *log.Logger, sc ...slack.Client) (h *Handler) {
 	if gp == nil {
 		panic("GamePool argument is nil")
 	}
@@ -35,49 +46,74 @@ func NewHandler(gp types.GamePoolAbstraction, m persistence.MongoAbstraction, l
 	h = &Handler{
 		gPool: gp,
 		mongo: m,
+		events: types.NewEventRepository(m),
 		logger: l,
+		pending: newPendingKills(),
+		seen: newPresence(presenceWindow),
+	}
+	if len(sc) > 0 && sc[0] != nil {
+		h.dm = newMessenger(sc[0], l)
+		h.news = newAnnouncer(sc[0], l)
+		gp.Subscribe(h.news.announce)
 	}
 	l.Printf("Startup: Handler created")
 	return
 }
 
 // OnGameCreated handles coordination when a game is created for this server.
+// -- The kill dictionary is checked for enough words to support a minimum sized game
 // -- An event is created and persisted to mongo
-// -- The new game is added to the game pool
+// -- The new game is added to the game pool, bound to the Slack channel given, if any. The event is backed out if
+//    that fails
 // Errors:
 // -- validation errors on all params
+// -- kill dictionary missing or too small
 // -- duplicate game created (GameID already exists)
 // -- mongo issue
-func (h Handler) OnGameCreated(gameid, creator, killdict, passcode string) (err error) {
+func (h Handler) OnGameCreated(ctx context.Context, gameid, creator, killdict, passcode, channel string) (err error) {
 	creatorID, err := slack.New(creator)
 	if err != nil {
-		return fmt.Errorf("OnGameCreated: %v", err)
+		return persistence.Errorf(types.ErrInvalidArgument, "OnGameCreated: %w", err)
 	}
 	// Create and persist the event to request a new game
 	var ev events.GameCreatedEvent
 	if ev, err = events.NewGameCreatedEvent(gameid, creatorID, killdict, passcode); err != nil {
-		err = fmt.Errorf("OnGameCreated: %v", err)
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnGameCreated: %w", err)
+		return
+	}
+	ev.Channel = channel
+	// Make sure the dictionary can support a game of at least the minimum size
+	kd, err := types.LoadKillDictionary(ctx, h.mongo, killdict)
+	if err != nil {
+		err = fmt.Errorf("OnGameCreated: %w", err)
+		return
+	}
+	if err = types.ValidDictionary(kd, types.DefaultMinimumPlayers); err != nil {
+		err = fmt.Errorf("OnGameCreated: %w", err)
 		return
 	}
-	if mongoerr := h.mongo.WriteCollection("events", &ev); mongoerr != nil {
+	if mongoerr := h.events.Save(ctx, &ev); mongoerr != nil {
 		// Want to handle errors with more graceful wording for downstream consumers
-		if strings.Contains(mongoerr.Error(), "duplicate") {
-			err = fmt.Errorf("OnGameCreated: Game %s already created", gameid)
+		if errors.Is(mongoerr, types.ErrDuplicate) {
+			err = persistence.Errorf(types.ErrDuplicate, "OnGameCreated: Game %s already created", gameid)
 		} else {
-			err = fmt.Errorf("OnGameCreated: Mongodb issue on GameCreated event write: %v", mongoerr)
+			err = fmt.Errorf("OnGameCreated: Mongodb issue on GameCreated event write: %w", mongoerr)
 		}
 		return
 	}
 
 	// Create and register the game object in the game pool
 	game := types.NewGameFromEvent(ev)
-	if gperr := h.gPool.AddGame(&game); gperr != nil {
+	if gperr := h.gPool.AddGame(ctx, &game); gperr != nil {
+		if delErr := h.events.Delete(persistence.Detach(ctx), ev.GetID()); delErr != nil {
+			h.logger.Printf("OnGameCreated: failed to back out event %s: %v", ev.GetID(), delErr)
+		}
 		// Should catch all dups at the event level
-		if strings.Contains(gperr.Error(), "duplicate") {
+		if errors.Is(gperr, types.ErrDuplicate) {
 			err = fmt.Errorf("OnGameCreated: Something bad happened. GamePool out of sync with mongo events")
 			return
 		}
-		err = fmt.Errorf("OnGameCreated: Issue on GameCreated add to GamePool: %v", gperr)
+		err = fmt.Errorf("OnGameCreated: Issue on GameCreated add to GamePool: %w", gperr)
 		return
 	}
 
@@ -86,29 +122,71 @@ func (h Handler) OnGameCreated(gameid, creator, killdict, passcode string) (err
 
 // OnGameStarted handles activiting a game from the starting stage into playing.
 // Only the original game creator is allowed to start a given gameid.
+// -- An event is created, carrying the seed used to deal targets
+// -- The game is started in the game pool, which writes the event to mongo as it starts the game
+// -- Each player is sent their target, when there's a Slack client
 // Errors:
 // -- gameid empty
 // -- valid slackid
 // -- gameid does not exists 
 // -- gameid not in 'starting' state
 // -- slackid does not match the creating slackid
-func (h *Handler) OnGameStarted(gameid string, creator string) (err error) {
-	// First, make sure there's already a game and it's not started yet
+// -- game already started
+// -- mongo issue
+func (h *Handler) OnGameStarted(ctx context.Context, gameid string, creator string) (err error) {
 	creatorID, err := slack.New(creator)
 	if err != nil {
-		return fmt.Errorf("OnGameStarted: %v", err)
+		return persistence.Errorf(types.ErrInvalidArgument, "OnGameStarted: %w", err)
+	}
+	var ev events.GameStartedEvent
+	if ev, err = events.NewGameStartedEvent(gameid, creatorID, time.Now().UnixNano()); err != nil {
+		return persistence.Errorf(types.ErrInvalidArgument, "OnGameStarted: %w", err)
+	}
+	if err = h.retryConflicts(ctx, "OnGameStarted", func() error {
+		return h.gPool.StartGame(ctx, gameid, ev)
+	}); err != nil {
+		if errors.Is(err, types.ErrDuplicate) {
+			return persistence.Errorf(types.ErrDuplicate, "OnGameStarted: Game %s already started", gameid)
+		}
+		return fmt.Errorf("OnGameStarted: %w", err)
 	}
-	err = h.gPool.StartGame(gameid, creatorID)
+	h.sendTargets(ctx, gameid)
+	return
+}
+
+// OnGameAborted calls off a game that hasn't finished. Only the original game creator is allowed to abort it.
+// -- An event is created
+// -- The game is aborted in the game pool, which writes the event to mongo as it aborts the game
+// Errors:
+// -- gameid empty or invalid slackid
+// -- gameid does not exist, or is already over
+// -- slackid does not match the creating slackid
+// -- mongo issue
+func (h *Handler) OnGameAborted(ctx context.Context, gameid string, creator string) (err error) {
+	creatorID, err := slack.New(creator)
 	if err != nil {
-		return fmt.Errorf("OnGameStarted: %v", err)
+		return persistence.Errorf(types.ErrInvalidArgument, "OnGameAborted: %w", err)
+	}
+	var ev events.GameAbortedEvent
+	if ev, err = events.NewGameAbortedEvent(gameid, creatorID); err != nil {
+		return persistence.Errorf(types.ErrInvalidArgument, "OnGameAborted: %w", err)
+	}
+	if err = h.retryConflicts(ctx, "OnGameAborted", func() error {
+		return h.gPool.AbortGame(ctx, gameid, ev)
+	}); err != nil {
+		if errors.Is(err, types.ErrDuplicate) {
+			return persistence.Errorf(types.ErrDuplicate, "OnGameAborted: Game %s already aborted", gameid)
+		}
+		return fmt.Errorf("OnGameAborted: %w", err)
 	}
 	return
 }
 
 // OnPlayerAdded handles coordination when a player is added to the game:
 // -- A unique player ID is created from the combo of gameid and slackid
-// -- An event is created and persisted to mongo
-// -- The new player is added to the player pool
+// -- A secret token is generated for the player. Only its hash is kept, and the token itself is returned once
+// -- An event is created
+// -- The new player is added to the game pool, which writes the event to mongo as it adds them
 // Errors:
 // -- gameid does not exists 
 // -- gameid not in 'starting' state
@@ -116,32 +194,561 @@ func (h *Handler) OnGameStarted(gameid string, creator string) (err error) {
 // -- duplicate player added
 // -- mongo issue
 // -- gamepool issue
-func (h Handler) OnPlayerAdded(gameid string, slackid string, name string, email string) (err error) {
+func (h Handler) OnPlayerAdded(ctx context.Context, gameid string, slackid string, name string, email string) (token string, err error) {
 	// First, make sure there's already a game and it's accepting players
-	if accepting, acceptErr := h.gPool.CanAddPlayers(gameid); !accepting {
-		err = fmt.Errorf("OnPlayerAdded: game %s: %v", gameid, acceptErr)
+	if accepting, acceptErr := h.gPool.CanAddPlayers(ctx, gameid); !accepting {
+		err = fmt.Errorf("OnPlayerAdded: game %s: %w", gameid, acceptErr)
 		return
 	}
 
-	// Create and persist an event, unless it's a dupe. Rely on PlayerAddEvent ctor to validate inputs
+	// Create the event, unless it's a dupe. Rely on PlayerAddEvent ctor to validate inputs
 	var ev events.PlayerAddedEvent
 	if ev, err = events.NewPlayerAddedEvent(gameid, slackid, name, email); err != nil {
-		err = fmt.Errorf("OnPlayerAdded: %v", err)
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnPlayerAdded: %w", err)
+		return
+	}
+	if token, ev.TokenHash, err = types.NewPlayerToken(); err != nil {
+		err = fmt.Errorf("OnPlayerAdded: Could not generate player token: %w", err)
+		return
+	}
+
+	if gpErr := h.retryConflicts(ctx, "OnPlayerAdded", func() error {
+		return h.gPool.AddPlayerToGame(ctx, gameid, ev)
+	}); gpErr != nil {
+		// Want to handle a dup with more graceful wording for downstream consumers
+		if errors.Is(gpErr, types.ErrDuplicate) {
+			err = persistence.Errorf(types.ErrDuplicate, "OnPlayerAdded: Player %s already added to game %s", slackid, gameid)
+		} else {
+			err = fmt.Errorf("OnPlayerAdded: %w", gpErr)
+		}
+		token = ""
+	}
+	return
+}
+
+// OnPlayerRemoved takes a player back out of a game that hasn't started yet. A removed player can't rejoin the
+// same game, since their PlayerAddedEvent stays in the log.
+// -- An event is created
+// -- The player is removed from the game pool, which writes the event to mongo as it removes them
+// Errors:
+// -- gameid or slackid empty or invalid
+// -- gameid does not exist or is not in 'starting' state
+// -- player not in the game
+// -- mongo issue
+func (h Handler) OnPlayerRemoved(ctx context.Context, gameid string, slackid string) (err error) {
+	var ev events.PlayerRemovedEvent
+	if ev, err = events.NewPlayerRemovedEvent(gameid, slackid); err != nil {
+		return persistence.Errorf(types.ErrInvalidArgument, "OnPlayerRemoved: %w", err)
+	}
+	if err = h.retryConflicts(ctx, "OnPlayerRemoved", func() error {
+		return h.gPool.RemovePlayerFromGame(ctx, gameid, ev)
+	}); err != nil {
+		if errors.Is(err, types.ErrDuplicate) {
+			return persistence.Errorf(types.ErrDuplicate, "OnPlayerRemoved: Player %s already removed from game %s", slackid, gameid)
+		}
+		return fmt.Errorf("OnPlayerRemoved: %w", err)
+	}
+	return
+}
+
+// OnKillReported handles coordination when a player reports their own assassination:
+// -- The game must exist and be in play
+// -- The kill is applied to the game pool, passing the victim's target and kill word to their assassin. The pool
+//    writes the event once the kill is applied, so kills reported together are logged in the order they were made,
+//    and logs the game's completion along with the kill that ends it
+// -- The assassin is sent their new target, when there's a Slack client and the game goes on
+// Errors:
+// -- gameid does not exist or not in 'playing' state
+// -- slackid empty or invalid
+// -- victim already reported dead
+// -- mongo issue
+// -- gamepool issue
+func (h Handler) OnKillReported(ctx context.Context, gameid string, slackid string) (err error) {
+	game, exists := h.gPool.GetGame(gameid)
+	if !exists {
+		err = persistence.Errorf(types.ErrNotFound, "OnKillReported: The requested GameID: %s doesn't exist on this server", gameid)
+		return
+	}
+	if game.Status != types.Playing {
+		err = persistence.Errorf(types.ErrInvalidState, "OnKillReported: game %s is not in play. State=%s", gameid, game.GetStatus())
+		return
+	}
+
+	var ev events.KillReportedEvent
+	if ev, err = events.NewKillReportedEvent(gameid, slackid); err != nil {
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnKillReported: %w", err)
 		return
 	}
 
-	if mongoerr := h.mongo.WriteCollection("events", &ev); mongoerr != nil {
-		// Want to handle a dup write with more graceful wording for downstream consumers
-		if strings.Contains(mongoerr.Error(), "duplicate") {
-			err = fmt.Errorf("OnPlayerAdded: Player %s already added to game %s", slackid, gameid)
+	if gpErr := h.retryConflicts(ctx, "OnKillReported", func() error {
+		return h.gPool.ReportKill(ctx, gameid, ev)
+	}); gpErr != nil {
+		if errors.Is(gpErr, types.ErrDuplicate) {
+			err = persistence.Errorf(types.ErrDuplicate, "OnKillReported: Player %s already reported dead in game %s", slackid, gameid)
 		} else {
-			err = fmt.Errorf("OnPlayerAdded: Mongodb write issue: %v", mongoerr)
+			err = fmt.Errorf("OnKillReported: %w", gpErr)
+		}
+		return
+	}
+
+	// The second to last death closes out the game. Look again, since the pool hands out copies
+	if game, exists = h.gPool.GetGame(gameid); exists && game.Status == types.Finished {
+		h.onGameCompleted(game)
+	} else {
+		h.sendInheritedTarget(ctx, gameid, slackid)
+	}
+	return
+}
+
+// OnDeathClaimed handles a player saying they were assassinated, with the button in their direct messages. The kill
+// doesn't take effect until their assassin confirms it, so the assassin is sent a button to do that with. Claiming
+// again replaces the kill waiting on confirmation.
+// Errors:
+// -- gameid does not exist or not in 'playing' state
+// -- the player isn't alive in the game, or nobody is hunting them
+func (h *Handler) OnDeathClaimed(ctx context.Context, gameid string, victim slack.SlackID) error {
+	assassin, err := h.assassinOf(ctx, "OnDeathClaimed", gameid, victim)
+	if err != nil {
+		return err
+	}
+	h.pending.raise(pendingKill{gameid: gameid, victim: victim, assassin: assassin.SlackID, waitOn: assassin.SlackID})
+	if h.dm != nil {
+		text := fmt.Sprintf("%s says you assassinated them in game %s. Confirm the kill?", slack.Mention(victim), gameid)
+		h.dm.send(ctx, assassin.SlackID, slack.Message{Text: text, Blocks: []slack.Block{
+			slack.Section("%s", text),
+			slack.Actions(slack.NewButton(actionConfirmKill, gameid + "+" + victim.ToString(), "Confirm kill", slack.Primary)),
+		}})
+	}
+	return nil
+}
+
+// OnKillConfirmed handles the confirmation of a kill waiting on it: the assassin confirming a kill their victim
+// claimed, or the victim confirming one spotted in a channel. The kill is then reported as if the victim had reported
+// it themselves, and the other party is told. When the report fails for a reason that may pass, such as the database
+// being down, the kill goes back to waiting so the confirmer can try again.
+// Errors:
+// -- no kill of the victim is waiting on the confirmer
+// -- the confirmer is no longer hunting the victim, such as when they've been killed since
+// -- any of the errors from OnKillReported
+func (h *Handler) OnKillConfirmed(ctx context.Context, gameid string, victim slack.SlackID, confirmer slack.SlackID) error {
+	kill, found := h.pending.take(gameid, victim, confirmer)
+	if !found {
+		return persistence.Errorf(types.ErrNotFound, "OnKillConfirmed: No kill of %s in game %s is waiting on %s to confirm", victim, gameid, confirmer)
+	}
+	if err := h.confirmKill(ctx, kill); err != nil {
+		if !settled(err) {
+			h.pending.restore(kill)
+		}
+		return err
+	}
+	if h.dm != nil && confirmer == victim {
+		h.dm.tell(ctx, kill.assassin, "%s confirmed their death in game %s. Well played", slack.Mention(victim), gameid)
+	} else if h.dm != nil {
+		h.dm.tell(ctx, victim, "%s confirmed your death in game %s. Better luck next time", slack.Mention(kill.assassin), gameid)
+	}
+	return nil
+}
+
+// confirmKill reports a confirmed kill, so long as the assassin who made it is still the one hunting the victim
+func (h *Handler) confirmKill(ctx context.Context, kill pendingKill) error {
+	assassin, err := h.assassinOf(ctx, "OnKillConfirmed", kill.gameid, kill.victim)
+	if err != nil {
+		return err
+	}
+	if assassin.SlackID != kill.assassin {
+		return persistence.Errorf(types.ErrInvalidState, "OnKillConfirmed: %s is no longer hunting %s in game %s", kill.assassin, kill.victim, kill.gameid)
+	}
+	if err = h.OnKillReported(ctx, kill.gameid, kill.victim.ToString()); err != nil {
+		return fmt.Errorf("OnKillConfirmed: %w", err)
+	}
+	return nil
+}
+
+// settled tells whether an error is down to the state of the game or the request itself, so trying again won't help
+func settled(err error) bool {
+	for _, kind := range []error{types.ErrInvalidState, types.ErrNotFound, types.ErrDuplicate, types.ErrNotAuthorized, types.ErrInvalidArgument} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnMessagePosted handles a message typed in a Slack channel, looking for players giving themselves away. When the
+// author is alive in a game in play, says their assassin's kill word, and the assassin has posted in the same channel
+// within presenceWindow, a kill waiting on the author's confirmation is raised. The author is sent a button to
+// confirm it with. Saying the word again while that kill is waiting doesn't ask again.
+// Errors:
+// -- the game pool can't give the players of a game
+func (h *Handler) OnMessagePosted(ctx context.Context, channel string, author slack.SlackID, text string, at time.Time) error {
+	h.seen.saw(channel, author, at)
+	for _, game := range h.gPool.GetGamesList() {
+		if game.Status != types.Playing {
+			continue
+		}
+		assassin, err := h.assassinOf(ctx, "OnMessagePosted", game.ID, author)
+		if errors.Is(err, types.ErrNotFound) || errors.Is(err, types.ErrInvalidState) {
+			continue // not playing in this game, or not alive in it
+		}
+		if err != nil {
+			return err
+		}
+		if !h.seen.present(channel, assassin.SlackID, at) || !slack.Says(text, assassin.KillWord) {
+			continue
+		}
+		kill := pendingKill{gameid: game.ID, victim: author, assassin: assassin.SlackID, waitOn: author}
+		if h.pending.waiting(kill) {
+			continue
+		}
+		h.pending.raise(kill)
+		if h.dm != nil {
+			text := fmt.Sprintf("You said *%s* in <#%s> with %s around. Were you assassinated in game %s?",
+				assassin.KillWord, channel, slack.Mention(assassin.SlackID), game.ID)
+			h.dm.send(ctx, author, slack.Message{Text: text, Blocks: []slack.Block{
+				slack.Section("%s", text),
+				slack.Actions(slack.NewButton(actionConfirmKill, game.ID + "+" + author.ToString(), "Yes, I was assassinated", slack.Danger)),
+			}})
+		}
+	}
+	return nil
+}
+
+// assassinOf finds the player hunting a victim who is still alive in a game in play
+func (h *Handler) assassinOf(ctx context.Context, op string, gameid string, victim slack.SlackID) (*types.Player, error) {
+	game, exists := h.gPool.GetGame(gameid)
+	if !exists {
+		return nil, persistence.Errorf(types.ErrNotFound, "%s: The requested GameID: %s doesn't exist on this server", op, gameid)
+	}
+	if game.Status != types.Playing {
+		return nil, persistence.Errorf(types.ErrInvalidState, "%s: game %s is not in play. State=%s", op, gameid, game.GetStatus())
+	}
+	players, err := h.gPool.GetPlayers(ctx, gameid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	victimid := gameid + "+" + victim.ToString()
+	for _, p := range players {
+		if p.GetID() == victimid && !p.IsAlive() {
+			return nil, persistence.Errorf(types.ErrInvalidState, "%s: %s is no longer alive in game %s", op, victim, gameid)
+		}
+	}
+	for _, p := range players {
+		if p.IsAlive() && p.Target == victimid {
+			return p, nil
+		}
+	}
+	return nil, persistence.Errorf(types.ErrNotFound, "%s: Nobody in game %s is hunting %s", op, gameid, victim)
+}
+
+// retryConflicts runs a change to a game, and runs it again for as long as it conflicts with changes made elsewhere,
+// up to conflictRetries more times. The pool has reloaded the game by then, so each try sees the latest state. After
+// that the change gives up with types.ErrConflict
+func (h Handler) retryConflicts(ctx context.Context, op string, change func() error) (err error) {
+	for try := 0; ; try++ {
+		if err = change(); !errors.Is(err, types.ErrConflict) {
+			return err
+		}
+		if try == conflictRetries || ctx.Err() != nil {
+			h.logger.Printf("%s: giving up after %d conflicts: %v", op, try+1, err)
+			return err
+		}
+		h.logger.Printf("%s: retrying after conflict: %v", op, err)
+	}
+}
+
+// sendTargets direct messages every living player in a game their target. Players whose target can't be found are
+// logged and skipped, since the game has started regardless
+func (h *Handler) sendTargets(ctx context.Context, gameid string) {
+	if h.dm == nil {
+		return
+	}
+	players, err := h.gPool.GetPlayers(ctx, gameid)
+	if err != nil {
+		h.logger.Printf("sendTargets: %v", err)
+		return
+	}
+	for _, p := range players {
+		if p.IsAlive() && p.Target != "" {
+			h.sendTarget(ctx, gameid, p.SlackID)
+		}
+	}
+}
+
+// sendInheritedTarget direct messages the assassin of a reported victim the target they took over
+func (h *Handler) sendInheritedTarget(ctx context.Context, gameid string, victimid string) {
+	if h.dm == nil {
+		return
+	}
+	victim, err := h.gPool.GetPlayer(ctx, gameid + "+" + victimid)
+	if err != nil {
+		h.logger.Printf("sendInheritedTarget: %v", err)
+		return
+	}
+	assassin, err := h.gPool.GetPlayer(ctx, victim.KilledBy)
+	if err != nil {
+		h.logger.Printf("sendInheritedTarget: %v", err)
+		return
+	}
+	h.sendTarget(ctx, gameid, assassin.SlackID)
+}
+
+// sendTarget direct messages a player their current target and kill word, along with the button to claim their own
+// death with
+func (h *Handler) sendTarget(ctx context.Context, gameid string, slackid slack.SlackID) {
+	target, err := h.GetSlackTarget(ctx, gameid, slackid)
+	if err != nil {
+		h.logger.Printf("sendTarget: %v", err)
+		return
+	}
+	text := fmt.Sprintf("Game %s: your target is %s. Your kill word is *%s*", gameid, target.TargetName, target.KillWord)
+	h.dm.send(ctx, slackid, slack.Message{Text: text, Blocks: []slack.Block{
+		slack.Section("%s", text),
+		slack.Context("Been assassinated? Own up here, and your assassin will be asked to confirm it"),
+		slack.Actions(slack.NewButton(actionAssassinated, gameid, "I was assassinated", slack.Danger)),
+	}})
+}
+
+// Tell direct messages a Slack user, when there's a Slack client to do it with
+func (h *Handler) Tell(ctx context.Context, user slack.SlackID, text string) {
+	if h.dm != nil {
+		h.dm.tell(ctx, user, "%s", text)
+	}
+}
+
+// FlushMessages waits for the direct messages and announcements already made to go out
+func (h *Handler) FlushMessages() {
+	if h.dm != nil {
+		h.dm.wait()
+		h.news.wait()
+	}
+}
+
+// onGameCompleted logs the completion of a game. The game pool records it in the event log, along with the kill that
+// finished the game
+func (h Handler) onGameCompleted(game *types.Game) {
+	h.logger.Printf("Game %s won by %s after %s", game.GetID(), game.Winner, game.GetDuration().Round(time.Second))
+}
+
+// OnDictionaryCreated creates a new KillDictionary from an initial list of words. Words that fail validation are
+// reported back rather than failing the whole request.
+// Errors:
+// -- dictid empty
+// -- dictionary already exists
+// -- none of the words were valid
+// -- mongo issue
+// -- the request is cancelled or times out part way. Words added before then stay added
+func (h Handler) OnDictionaryCreated(ctx context.Context, dictid string, words []string) (added int, rejected []string, err error) {
+	if dictid == "" {
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnDictionaryCreated: The request is missing DictID field")
+		return
+	}
+	if existing, _ := types.LoadKillDictionary(ctx, h.mongo, dictid); existing != nil {
+		err = persistence.Errorf(types.ErrDuplicate, "OnDictionaryCreated: KillDictionary %s already exists", dictid)
+		return
+	}
+	kd := types.NewKillDictionary(ctx, h.mongo, dictid)
+	added, rejected = kd.AddWords(ctx, words...)
+	if ctx.Err() != nil {
+		err = persistence.Errorf(types.ErrStoreUnavailable, "OnDictionaryCreated: KillDictionary %s left with %d words: %w", dictid, added, ctx.Err())
+	} else if added == 0 {
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnDictionaryCreated: KillDictionary %s needs at least one valid word", dictid)
+	}
+	return
+}
+
+// OnDictionaryDeleted removes a KillDictionary and all of its words. Dictionaries in use by a game that hasn't
+// finished are left alone.
+// Errors:
+// -- dictionary not found
+// -- dictionary in use by an active game
+// -- mongo issue
+func (h Handler) OnDictionaryDeleted(ctx context.Context, dictid string) (err error) {
+	kd, loadErr := types.LoadKillDictionary(ctx, h.mongo, dictid)
+	if loadErr != nil {
+		return fmt.Errorf("OnDictionaryDeleted: %w", loadErr)
+	}
+	for _, g := range h.gPool.GetGamesList() {
+		if g.KillDictionary == dictid && (g.Status == types.Starting || g.Status == types.Playing) {
+			return persistence.Errorf(types.ErrInvalidState, "OnDictionaryDeleted: KillDictionary %s is in use by game %s", dictid, g.GetID())
 		}
+	}
+	if delErr := kd.Delete(ctx); delErr != nil {
+		return fmt.Errorf("OnDictionaryDeleted: %w", delErr)
+	}
+	return nil
+}
+
+// OnDictionaryImported bulk loads words into a KillDictionary from a text, csv or json source, creating the
+// dictionary if it doesn't exist yet. Lines that can't be added are reported back in the result.
+// Errors:
+// -- dictid empty
+// -- unknown format, or a source that can't be parsed at all
+// -- mongo issue
+func (h Handler) OnDictionaryImported(ctx context.Context, dictid string, format string, r io.Reader) (result types.ImportResult, err error) {
+	if dictid == "" {
+		err = persistence.Errorf(types.ErrInvalidArgument, "OnDictionaryImported: The request is missing DictID field")
+		return
+	}
+	dictFormat, err := types.ParseDictionaryFormat(format)
+	if err != nil {
+		err = fmt.Errorf("OnDictionaryImported: %w", err)
+		return
+	}
+	kd := types.NewKillDictionary(ctx, h.mongo, dictid)
+	if err = kd.RestoreFromMongo(ctx); err != nil {
+		err = fmt.Errorf("OnDictionaryImported: %w", err)
 		return
 	}
+	if result, err = kd.Import(ctx, r, dictFormat); err != nil {
+		err = fmt.Errorf("OnDictionaryImported: %w", err)
+	}
+	return
+}
 
-	if gpErr := h.gPool.AddPlayerToGame(gameid, ev); gpErr != nil {
-		err = fmt.Errorf("OnPlayerAdded: %v", gpErr)
+// OnWordsAdded adds words to an existing KillDictionary. Words that fail validation are reported back rather than
+// failing the whole request.
+// Errors:
+// -- dictionary not found
+// -- mongo issue
+// -- the request is cancelled or times out part way. Words added before then stay added
+func (h Handler) OnWordsAdded(ctx context.Context, dictid string, words []string) (added int, rejected []string, err error) {
+	kd, loadErr := types.LoadKillDictionary(ctx, h.mongo, dictid)
+	if loadErr != nil {
+		err = fmt.Errorf("OnWordsAdded: %w", loadErr)
+		return
+	}
+	added, rejected = kd.AddWords(ctx, words...)
+	if ctx.Err() != nil {
+		err = persistence.Errorf(types.ErrStoreUnavailable, "OnWordsAdded: KillDictionary %s took %d words: %w", dictid, added, ctx.Err())
+	}
+	return
+}
+
+// OnWordRemoved removes a single word from an existing KillDictionary
+// Errors:
+// -- dictionary not found
+// -- word not in the dictionary
+// -- mongo issue
+func (h Handler) OnWordRemoved(ctx context.Context, dictid string, word string) (err error) {
+	kd, loadErr := types.LoadKillDictionary(ctx, h.mongo, dictid)
+	if loadErr != nil {
+		return fmt.Errorf("OnWordRemoved: %w", loadErr)
+	}
+	if rmErr := kd.RemoveWord(ctx, word); rmErr != nil {
+		return fmt.Errorf("OnWordRemoved: %w", rmErr)
+	}
+	return nil
+}
+
+// GetDictionaries summarizes all of the persisted dictionaries and their word counts
+func (h *Handler) GetDictionaries(ctx context.Context) ([]types.DictionarySummary, error) {
+	dicts, err := types.ListKillDictionaries(ctx, h.mongo)
+	if err != nil {
+		return nil, fmt.Errorf("GetDictionaries: %w", err)
+	}
+	if dicts == nil {
+		dicts = []types.DictionarySummary{}
+	}
+	return dicts, nil
+}
+
+// GetDictionaryList provides a listing of all of the persisted dictionaries and their word counts
+func (h *Handler) GetDictionaryList(ctx context.Context) (result string, err error) {
+	dicts, listErr := types.ListKillDictionaries(ctx, h.mongo)
+	if listErr != nil {
+		err = fmt.Errorf("GetDictionaryList: %w", listErr)
+		return
+	}
+	result = "<h2>Dictionary List</h2>\n"
+	result += "  timestamp: " + time.Now().String() + "\n<p>\n"
+	for _, d := range dicts {
+		result += fmt.Sprintf("<li>%s: %d words</li>", d.ID, d.Count)
+	}
+	return
+}
+
+// GetDictionaryExport writes out all of the words in a KillDictionary in text, csv or json format
+// Errors:
+// -- unknown format
+// -- dictionary not found
+// -- mongo issue
+func (h *Handler) GetDictionaryExport(ctx context.Context, dictid string, format string, w io.Writer) (err error) {
+	dictFormat, err := types.ParseDictionaryFormat(format)
+	if err != nil {
+		return fmt.Errorf("GetDictionaryExport: %w", err)
+	}
+	kd, err := types.LoadKillDictionary(ctx, h.mongo, dictid)
+	if err != nil {
+		return fmt.Errorf("GetDictionaryExport: %w", err)
+	}
+	if err = kd.Export(ctx, w, dictFormat); err != nil {
+		return fmt.Errorf("GetDictionaryExport: %w", err)
+	}
+	return nil
+}
+
+// GetTarget tells a player who they're hunting and which word to use. This is the game's core secret, so the caller
+// has to prove they're the player with the token handed out when they joined.
+// Errors:
+// -- unknown gameid or slackid, or a token that doesn't match. These all look the same to the caller
+// -- game not in play
+// -- player is dead
+func (h *Handler) GetTarget(ctx context.Context, gameid, slackid, token string) (result TargetAssignment, err error) {
+	return h.getTarget(ctx, "GetTarget", gameid, slackid, func(player *types.Player) bool {
+		return player.Authenticate(token)
+	})
+}
+
+// AuthenticatePlayer checks the token is the one handed out to the player when they joined. Reporting a kill or
+// leaving a game asks for it, so nobody can do either for someone else
+// Errors:
+// -- unknown gameid or slackid, or a token that doesn't match. These all look the same to the caller
+func (h *Handler) AuthenticatePlayer(ctx context.Context, gameid, slackid, token string) error {
+	player, err := h.gPool.GetPlayer(ctx, gameid + "+" + slackid)
+	if err != nil || !player.Authenticate(token) {
+		return persistence.Errorf(types.ErrNotAuthorized, "AuthenticatePlayer: Not authorized to act for %s in game %s", slackid, gameid)
+	}
+	return nil
+}
+
+// GetSlackTarget is GetTarget for a request Slack has vouched for. Its verified signature proves who is asking, so
+// there's no token to check
+func (h *Handler) GetSlackTarget(ctx context.Context, gameid string, slackid slack.SlackID) (TargetAssignment, error) {
+	return h.getTarget(ctx, "GetSlackTarget", gameid, slackid.ToString(), func(*types.Player) bool {
+		return true
+	})
+}
+
+// getTarget looks up the target for a player that passes the authorized check
+func (h *Handler) getTarget(ctx context.Context, op, gameid, slackid string, authorized func(*types.Player) bool) (result TargetAssignment, err error) {
+	game, exists := h.gPool.GetGame(gameid)
+	player, err := h.gPool.GetPlayer(ctx, gameid + "+" + slackid)
+	if !exists || err != nil || !authorized(player) {
+		err = persistence.Errorf(types.ErrNotAuthorized, "%s: Not authorized to see the target for %s in game %s", op, slackid, gameid)
+		return
+	}
+	if game.Status != types.Playing {
+		err = persistence.Errorf(types.ErrInvalidState, "%s: game %s is not in play. State=%s", op, gameid, game.GetStatus())
+		return
+	}
+	if !player.IsAlive() {
+		err = persistence.Errorf(types.ErrInvalidState, "%s: %s is no longer alive in game %s", op, slackid, gameid)
+		return
+	}
+	target, err := h.gPool.GetPlayer(ctx, player.Target)
+	if err != nil {
+		err = fmt.Errorf("%s: Something bad happened. Target %s is missing: %v", op, player.Target, err)
+		return
+	}
+	result = TargetAssignment{
+		GameID:     gameid,
+		SlackID:    slackid,
+		TargetID:   target.SlackID.ToString(),
+		TargetName: target.Name,
+		KillWord:   player.KillWord,
+	}
+	if result.TargetName == "" {
+		result.TargetName = result.TargetID
 	}
 	return
 }
@@ -153,7 +760,25 @@ func (h *Handler) GetGameStatus(gameid string) (result string, exists bool) {
 	if game, exists = h.gPool.GetGame(gameid); !exists {
 		return
 	}
-	result = game.GetStatusReport()
+	result = game.GetStatusReport() + fmt.Sprintf("   Queued commands: %d\n", h.gPool.QueueDepth(gameid))
+	return
+}
+
+// GetGameView gives the structured view of a game, with an existence check in lieu of error messages
+func (h *Handler) GetGameView(gameid string) (result GameView, exists bool) {
+	var game *types.Game
+	if game, exists = h.gPool.GetGame(gameid); !exists {
+		return
+	}
+	return newGameView(game, h.gPool.QueueDepth(gameid)), true
+}
+
+// GetGameViews gives the structured view of every game in the GamePool, oldest first
+func (h *Handler) GetGameViews() (result []GameView) {
+	result = []GameView{}
+	for _, g := range h.gPool.GetGamesList() {
+		result = append(result, newGameView(g, h.gPool.QueueDepth(g.GetID())))
+	}
 	return
 }
 
@@ -163,7 +788,11 @@ func (h *Handler) GetGamesList() (result string) {
 	result += "  timestamp: " + time.Now().String() + "\n<p>\n"
 	games := h.gPool.GetGamesList()
 	for _, v := range games {
-		line := fmt.Sprintf("<li>%s: %s, %d players</li>", v.GetID(), v.GetStatus(), v.StartPlayers)
+		line := fmt.Sprintf("<li>%s: %s, %d players", v.GetID(), v.GetStatus(), v.StartPlayers)
+		if v.Status == types.Finished {
+			line += fmt.Sprintf(", won by %s in %s", v.Winner, v.GetDuration().Round(time.Second))
+		}
+		line += "</li>"
 		result += line
 	}
 	return
@@ -2,7 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -27,7 +33,9 @@ type gPoolControls struct {
 	addPlayerErr string      	// default: ""
 	canAddErr    string      	// default: ""
 	getGameErr   string      	// default: ""
+	reportKillErr string     	// default: ""
 	startGameErr string      	// default: ""
+	errKind      error       	// default: nil
 }
 
 type gameArgs struct {
@@ -58,6 +66,7 @@ type testArgs struct {
 	h         Handler       	// the default testHandler
 	wantErr   bool          	// required
 	errText   string        	// ""
+	wantKind  error         	// nil, or the kind of error wanted
 	gArgs     gameArgs      	// required for game tests
 	pArgs     playerArgs    	// required for player tests
 	cArgs     commandArgs   	// default nil
@@ -68,7 +77,7 @@ type testArgs struct {
 func TestHandlerCtorPositive(t *testing.T) {
 	mongo := dao.NewMockMongoSession()
 	testPPool := types.PlayerPool{}
-	testGPool := types.NewGamePool(mongo, &testPPool)
+	testGPool := newGamePool(t, mongo, &testPPool)
 	logBuf := &bytes.Buffer{}
 	logLabel := "handler_ctortest: "
 	blog := log.New(logBuf, logLabel, 0)
@@ -82,7 +91,7 @@ func TestHandlerCtorPositive(t *testing.T) {
 func TestHandlerCtorNilPointers(t *testing.T) {
 	mongo := dao.NewMockMongoSession()
 	testPPool := types.PlayerPool{}
-	testGPool := types.NewGamePool(mongo, &testPPool)
+	testGPool := newGamePool(t, mongo, &testPPool)
 	logBuf := &bytes.Buffer{}
 	logLabel := "handler_ctortest: "
 	blog := log.New(logBuf, logLabel, 0)
@@ -108,6 +117,7 @@ func TestHandler_OnPlayerAdded(t *testing.T) {
 		testArgs{name: "invalid game ID",
 			wantErr: true,
 			errText: "OnPlayerAdded: The request is missing GameID",
+			wantKind: types.ErrInvalidArgument,
 			pArgs: playerArgs{
 				gameid:  "",
 				slackid: "UCREATEDME",
@@ -141,31 +151,31 @@ func TestHandler_OnPlayerAdded(t *testing.T) {
 		testArgs{name: "duplicate players",
 			wantErr: true,
 			errText: "OnPlayerAdded: Player UALREADYEXIST already added to game Dupity",
+			wantKind: types.ErrDuplicate,
 			pArgs: playerArgs{
 				gameid:  "Dupity",
 				slackid: "UALREADYEXIST",
 				name:    "fred",
 				email:   "fred@bedrock.org",
 			},
-			mongoCtrl: dao.MongoControls{
-				ConnectMode: "positive",
-				WriteMode:   "duplicate",
-				ReturnVal:   nil,
+			gPoolCtrl: gPoolControls{
+				addPlayerErr: "(mock) already in the log",
+				errKind: types.ErrDuplicate,
 			},
 		},
 		testArgs{name: "mongo issue",
 			wantErr: true,
-			errText: "OnPlayerAdded: Mongodb write issue: Mock error on write",
+			errText: "OnPlayerAdded: (mock) Mock error on write",
+			wantKind: types.ErrStoreUnavailable,
 			pArgs: playerArgs{
 				gameid:  "Dupity",
 				slackid: "UALREADYEXIST",
 				name:    "fred",
 				email:   "fred@bedrock.org",
 			},
-			mongoCtrl: dao.MongoControls{
-				ConnectMode: "positive",
-				WriteMode:   "fail",
-				ReturnVal:   nil,
+			gPoolCtrl: gPoolControls{
+				addPlayerErr: "(mock) Mock error on write",
+				errKind: types.ErrStoreUnavailable,
 			},
 		},
 		testArgs{name: "gamepool issue",
@@ -185,21 +195,27 @@ func TestHandler_OnPlayerAdded(t *testing.T) {
 	// Add player(s) for tests that require pre-existing players
 	preexist, err := events.NewPlayerAddedEvent("targetGame", "UFIRSTDUPE", "George", "me@you.net")
 	require.NoError(t, err, "Failure to create preexisting player in setup")
-	testHandler.gPool.AddPlayerToGame("targetGame", preexist)
+	testHandler.gPool.AddPlayerToGame(context.Background(), "targetGame", preexist)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mongo.SetMongoControlsFromArgs(tt.mongoCtrl)
 			setGPoolControlsFromArgs(gPool, tt.gPoolCtrl)
-			err := testHandler.OnPlayerAdded(tt.pArgs.gameid, tt.pArgs.slackid, tt.pArgs.name, tt.pArgs.email)
+			token, err := testHandler.OnPlayerAdded(context.Background(), tt.pArgs.gameid, tt.pArgs.slackid, tt.pArgs.name, tt.pArgs.email)
 			if tt.wantErr {
 				require.Errorf(t, err, "Was looking for an error containing '%s' but got none", tt.errText)
 				require.Contains(t, err.Error(), "OnPlayerAdded:", "All errors should start with the func name", tt.errText)
 				require.Contains(t, err.Error(), tt.errText, "Got an error but didn't find '%s' in the content", tt.errText)
+				if tt.wantKind != nil {
+					require.True(t, errors.Is(err, tt.wantKind), "Wanted an error of kind '%v'", tt.wantKind)
+				}
+				require.Empty(t, token, "No token is handed out on failure")
 			} else {
 				require.NoErrorf(t, err, "Was expecting successful call, but got err: %v", err)
 				require.Equal(t, tt.pArgs.gameid, gPool.PlayerAdded.GameID, "OnPlayerAdded mock did not recieve the correct gameid" )
 				require.Equal(t, tt.pArgs.slackid, gPool.PlayerAdded.Event.SlackID.ToString(), "OnPlayerAdded mock did not recieve the correct slackid in the event")
+				require.NotEmpty(t, token, "Player should be handed a token")
+				require.Equal(t, types.HashPlayerToken(token), gPool.PlayerAdded.Event.TokenHash, "Event should carry the token hash, not the token")
 			}
 		})
 	}
@@ -235,6 +251,7 @@ func TestHandler_OnGameCreated(t *testing.T) {
 		testArgs{name: "empty creator argument",
 			wantErr: true,
 			errText: "OnGameCreated: A valid Slack ID",
+			wantKind: types.ErrInvalidArgument,
 			gArgs: gameArgs{
 				gameid:   "notblank",
 				creator:  "",
@@ -262,6 +279,17 @@ func TestHandler_OnGameCreated(t *testing.T) {
 				passcode: "",
 			},
 		},
+		testArgs{name: "missing dictionary",
+			wantErr: true,
+			errText: "OnGameCreated: KillDictionary nowords not found",
+			wantKind: types.ErrNotFound,
+			gArgs: gameArgs{
+				gameid:   "notblank",
+				creator:  "UNOTBLANK",
+				killdict: "nowords",
+				passcode: "notBlank",
+			},
+		},
 		testArgs{name: "force NewGameCreatedEvent error",
 			wantErr: true,
 			errText: "valid Slack ID",
@@ -275,6 +303,7 @@ func TestHandler_OnGameCreated(t *testing.T) {
 		testArgs{name: "duplicate gameID (at mongo)",
 			wantErr: true,
 			errText: "dupe_game already created",
+			wantKind: types.ErrDuplicate,
 			gArgs: gameArgs{
 				gameid:   "dupe_game",
 				creator:  "UTESTMASTER",
@@ -303,11 +332,13 @@ func TestHandler_OnGameCreated(t *testing.T) {
 			},
 			gPoolCtrl: gPoolControls{
 				addGameErr: "duplicate",
+				errKind:    types.ErrDuplicate,
 			},
 		},
 		testArgs{name: "mongo fail",
 			wantErr: true,
 			errText: "connect",
+			wantKind: types.ErrStoreUnavailable,
 			gArgs: gameArgs{
 				gameid:   "fail",
 				creator:  "UWILLFAIL",
@@ -344,11 +375,14 @@ func TestHandler_OnGameCreated(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mongo.SetMongoControlsFromArgs(tt.mongoCtrl)
 			setGPoolControlsFromArgs(gPool, tt.gPoolCtrl)
-			err := testHandler.OnGameCreated(tt.gArgs.gameid, tt.gArgs.creator, tt.gArgs.killdict, tt.gArgs.passcode)
+			err := testHandler.OnGameCreated(context.Background(), tt.gArgs.gameid, tt.gArgs.creator, tt.gArgs.killdict, tt.gArgs.passcode, "")
 			if tt.wantErr {
 				require.Errorf(t, err, "Was looking for an error containing '%s' but got none", tt.errText)
 				require.Contains(t, err.Error(), "OnGameCreated:", "All errors should start with the func name", tt.errText)
 				require.Contains(t, err.Error(), tt.errText, "Got an error but didn't find '%s' in the content", tt.errText)
+				if tt.wantKind != nil {
+					require.True(t, errors.Is(err, tt.wantKind), "Wanted an error of kind '%v'", tt.wantKind)
+				}
 			} else {
 				require.NoErrorf(t, err, "Was expecting successful call, but got err: %v", err)
 				require.Equal(t, tt.gArgs.gameid, gPool.GameAdded.Added.ID, "AddGame mock did not recieve the correct gameid" )
@@ -358,6 +392,36 @@ func TestHandler_OnGameCreated(t *testing.T) {
 	}
 }
 
+// TestHandler_OnGameCreated_BackedOut checks a game the pool turns down leaves nothing in the event log, so replay
+// doesn't bring it back
+func TestHandler_OnGameCreated_BackedOut(t *testing.T) {
+	ctx := context.Background()
+	store := dao.NewMemorySession()
+	gPool := &types.MockGamePool{AddGameError: "(mock) game not saved"}
+	testHandler := NewHandler(gPool, store, log.New(&bytes.Buffer{}, "handler_test: ", 0))
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	_, _, err := testHandler.OnDictionaryCreated(ctx, "afile.txt", words)
+	require.NoError(t, err)
+	err = testHandler.OnGameCreated(ctx, "stillborn", "UFRED", "afile.txt", "melod", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "OnGameCreated: Issue on GameCreated add to GamePool: (mock) game not saved")
+	logged, err := types.NewEventRepository(store).Find(ctx, types.AllEvents().ForGame("stillborn"))
+	require.NoError(t, err)
+	require.Empty(t, logged, "The GameCreatedEvent is backed out")
+}
+
+func TestHandler_OnGameCreated_SmallDictionary(t *testing.T) {
+	testHandler, mongo, _, _ := getHandlerWithMocksAndLogger(t)
+	mongo.CollectionResults[types.CollectionName] = mockDictionary("skimpy", 3)
+	err := testHandler.OnGameCreated(context.Background(), "shortchanged", "UFRED", "skimpy", "notBlank", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "OnGameCreated: KillDictionary skimpy has 3 words")
+	require.Contains(t, err.Error(), "Short by 5")
+}
+
 func TestHandler_OnGameStarted(t *testing.T) {
 	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
 	require.NotNil(t, blog, "Placeholder to use blog -- remove when log validation added")
@@ -387,7 +451,7 @@ func TestHandler_OnGameStarted(t *testing.T) {
 		},
 		testArgs{name: "missing game ID",
 			wantErr: true,
-			errText: "OnGameStarted: (mock) not a valid Game ID",
+			errText: "OnGameStarted: The request is missing GameID field",
 			gArgs: gameArgs{
 				gameid:     "game1",
 				creator:    "UNOMATTER",
@@ -396,8 +460,39 @@ func TestHandler_OnGameStarted(t *testing.T) {
 				gameid:  "",
 				creator: "UCREATE",
 			},
+		},
+		testArgs{name: "already started",
+			wantErr: true,
+			errText: "OnGameStarted: Game game1 already started",
+			wantKind: types.ErrDuplicate,
+			gArgs: gameArgs{
+				gameid:     "game1",
+				creator:    "UFRED",
+			},
+			cArgs: commandArgs{
+				gameid:  "game1",
+				creator: "UFRED",
+			},
 			gPoolCtrl: gPoolControls{
-				startGameErr: "(mock) not a valid Game ID",
+				startGameErr: "(mock) already in the log",
+				errKind: types.ErrDuplicate,
+			},
+		},
+		testArgs{name: "mongo failure",
+			wantErr: true,
+			errText: "OnGameStarted: (mock) Mock error on write",
+			wantKind: types.ErrStoreUnavailable,
+			gArgs: gameArgs{
+				gameid:     "game1",
+				creator:    "UFRED",
+			},
+			cArgs: commandArgs{
+				gameid:  "game1",
+				creator: "UFRED",
+			},
+			gPoolCtrl: gPoolControls{
+				startGameErr: "(mock) Mock error on write",
+				errKind: types.ErrStoreUnavailable,
 			},
 		},
 		testArgs{name: "GamePool returns an error",
@@ -420,21 +515,484 @@ func TestHandler_OnGameStarted(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			testGame := newGameFromArgs(tt.gArgs)
-			gPool.AddGame(testGame)
+			gPool.AddGame(context.Background(), testGame)
 			mongo.SetMongoControlsFromArgs(tt.mongoCtrl)
 			setGPoolControlsFromArgs(gPool, tt.gPoolCtrl)
-			err := testHandler.OnGameStarted(tt.cArgs.gameid, tt.cArgs.creator)
+			err := testHandler.OnGameStarted(context.Background(), tt.cArgs.gameid, tt.cArgs.creator)
+			if !tt.wantErr {
+				require.Equal(t, tt.cArgs.gameid+"+started", gPool.GameStarted.Event.GetID(), "GamePool gets the persisted event")
+			}
 			if tt.wantErr {
 				require.Errorf(t, err, "Was looking for an error containing '%s' but got none", tt.errText)
 				require.Contains(t, err.Error(), "OnGameStarted:", "All errors should start with the func name", tt.errText)
 				require.Contains(t, err.Error(), tt.errText, "Got an error but didn't find '%s' in the content", tt.errText)
+				if tt.wantKind != nil {
+					require.True(t, errors.Is(err, tt.wantKind), "Wanted an error of kind '%v'", tt.wantKind)
+				}
+			} else {
+				require.NoErrorf(t, err, "Was expecting successful call, but got err: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandler_OnGameAborted(t *testing.T) {
+	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
+	require.NotNil(t, blog, "Placeholder to use blog -- remove when log validation added")
+
+	t.Run("positive", func(t *testing.T) {
+		mongo.Written = nil
+		require.NoError(t, testHandler.OnGameAborted(context.Background(), "doomed", "UBOSS"))
+		require.Equal(t, "doomed+aborted", gPool.GameAborted.Event.GetID())
+		require.Empty(t, mongo.Written, "The pool logs the event, as it aborts the game")
+	})
+	t.Run("bad Slack ID", func(t *testing.T) {
+		err := testHandler.OnGameAborted(context.Background(), "doomed", "I_no_valido")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnGameAborted: A valid Slack ID")
+	})
+	t.Run("already aborted", func(t *testing.T) {
+		gPool.AbortGameError, gPool.ErrorKind = "(mock) already in the log", types.ErrDuplicate
+		defer func() { gPool.AbortGameError, gPool.ErrorKind = "", nil }()
+		err := testHandler.OnGameAborted(context.Background(), "doomed", "UBOSS")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnGameAborted: Game doomed already aborted")
+	})
+	t.Run("GamePool returns an error", func(t *testing.T) {
+		gPool.AbortGameError = "mock GamePool error message"
+		defer func() { gPool.AbortGameError = "" }()
+		err := testHandler.OnGameAborted(context.Background(), "doomed", "UBOSS")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnGameAborted: mock GamePool error message")
+	})
+}
+
+func TestHandler_OnPlayerRemoved(t *testing.T) {
+	testHandler, _, gPool, blog := getHandlerWithMocksAndLogger(t)
+	require.NotNil(t, blog, "Placeholder to use blog -- remove when log validation added")
+
+	t.Run("positive", func(t *testing.T) {
+		require.NoError(t, testHandler.OnPlayerRemoved(context.Background(), "shrinking", "UQUITTER"))
+		require.Equal(t, "shrinking+UQUITTER", gPool.PlayerRemoved.Event.PlayerID)
+	})
+	t.Run("missing slack ID", func(t *testing.T) {
+		err := testHandler.OnPlayerRemoved(context.Background(), "shrinking", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnPlayerRemoved: The request is missing SlackID field")
+	})
+	t.Run("already removed", func(t *testing.T) {
+		gPool.RemovePlayerError, gPool.ErrorKind = "(mock) already in the log", types.ErrDuplicate
+		defer func() { gPool.RemovePlayerError, gPool.ErrorKind = "", nil }()
+		err := testHandler.OnPlayerRemoved(context.Background(), "shrinking", "UQUITTER")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnPlayerRemoved: Player UQUITTER already removed from game shrinking")
+	})
+	t.Run("GamePool returns an error", func(t *testing.T) {
+		gPool.RemovePlayerError = "mock GamePool error message"
+		defer func() { gPool.RemovePlayerError = "" }()
+		err := testHandler.OnPlayerRemoved(context.Background(), "shrinking", "UQUITTER")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnPlayerRemoved: mock GamePool error message")
+	})
+}
+
+func TestHandler_OnKillReported(t *testing.T) {
+	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
+	require.NotNil(t, blog, "Placeholder to use blog -- remove when log validation added")
+	playingGame := newGameFromArgs(gameArgs{gameid: "killfield", creator: "UBOSS", numPlayers: 6, status: types.Playing})
+	startingGame := newGameFromArgs(gameArgs{gameid: "notyet", creator: "UBOSS", numPlayers: 6})
+	games := []*types.Game{ playingGame, startingGame }
+	tests := []testArgs{
+		testArgs{name: "positive",
+			wantErr: false,
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList: games,
+			},
+		},
+		testArgs{name: "missing game",
+			wantErr: true,
+			errText: "OnKillReported: The requested GameID: nogame doesn't exist",
+			wantKind: types.ErrNotFound,
+			pArgs: playerArgs{
+				gameid:  "nogame",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList:  games,
+				getGameErr: "(mock) missing",
+			},
+		},
+		testArgs{name: "game not in play",
+			wantErr: true,
+			errText: "OnKillReported: game notyet is not in play. State=starting",
+			wantKind: types.ErrInvalidState,
+			pArgs: playerArgs{
+				gameid:  "notyet",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList: games,
+			},
+		},
+		testArgs{name: "invalid slack ID",
+			wantErr: true,
+			errText: "OnKillReported: Player does not have a valid Slack ID",
+			wantKind: types.ErrInvalidArgument,
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "notValid",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList: games,
+			},
+		},
+		testArgs{name: "already reported",
+			wantErr: true,
+			errText: "OnKillReported: Player UVICTIM already reported dead in game killfield",
+			wantKind: types.ErrDuplicate,
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList:     games,
+				reportKillErr: "(mock) already dead",
+				errKind:       types.ErrDuplicate,
+			},
+		},
+		testArgs{name: "mongo issue",
+			wantErr: true,
+			errText: "OnKillReported: (mock) Mock error on write",
+			wantKind: types.ErrStoreUnavailable,
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList:     games,
+				reportKillErr: "(mock) Mock error on write",
+				errKind:       types.ErrStoreUnavailable,
+			},
+		},
+		testArgs{name: "gamepool issue",
+			wantErr: true,
+			errText: "OnKillReported: (mock) no assassin",
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList:     games,
+				reportKillErr: "(mock) no assassin",
+			},
+		},
+		testArgs{name: "conflicts every time",
+			wantErr: true,
+			errText: "OnKillReported: (mock) changed elsewhere",
+			wantKind: types.ErrConflict,
+			pArgs: playerArgs{
+				gameid:  "killfield",
+				slackid: "UVICTIM",
+			},
+			gPoolCtrl: gPoolControls{
+				gamesList:     games,
+				reportKillErr: "(mock) changed elsewhere",
+				errKind:       types.ErrConflict,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mongo.SetMongoControlsFromArgs(tt.mongoCtrl)
+			setGPoolControlsFromArgs(gPool, tt.gPoolCtrl)
+			err := testHandler.OnKillReported(context.Background(), tt.pArgs.gameid, tt.pArgs.slackid)
+			if tt.wantErr {
+				require.Errorf(t, err, "Was looking for an error containing '%s' but got none", tt.errText)
+				require.Contains(t, err.Error(), "OnKillReported:", "All errors should start with the func name", tt.errText)
+				require.Contains(t, err.Error(), tt.errText, "Got an error but didn't find '%s' in the content", tt.errText)
+				if tt.wantKind != nil {
+					require.True(t, errors.Is(err, tt.wantKind), "Wanted an error of kind '%v'", tt.wantKind)
+				}
 			} else {
 				require.NoErrorf(t, err, "Was expecting successful call, but got err: %v", err)
+				require.Equal(t, tt.pArgs.gameid, gPool.KillReported.GameID, "ReportKill mock did not recieve the correct gameid" )
+				require.Equal(t, tt.pArgs.slackid, gPool.KillReported.Event.SlackID.ToString(), "ReportKill mock did not recieve the correct slackid in the event")
 			}
 		})
 	}
 }
 
+// TestHandler_OnKillReported_Conflict has two servers share a store, and report kills along the same chain. The one
+// that is behind finds out when it writes, and tries again against what the other wrote
+func TestHandler_OnKillReported_Conflict(t *testing.T) {
+	ctx := context.Background()
+	store := dao.NewMemorySession()
+	logBuf := &bytes.Buffer{}
+	serve := func() (*Handler, *types.PlayerPool) {
+		pp := newPlayerPool(t, store)
+		return NewHandler(newGamePool(t, store, pp), store, log.New(logBuf, "handler_test: ", 0)), pp
+	}
+	here, herePlayers := serve()
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	_, _, err := here.OnDictionaryCreated(ctx, "afile.txt", words)
+	require.NoError(t, err)
+	require.NoError(t, here.OnGameCreated(ctx, "chain", "UBOSS", "afile.txt", "melod", ""))
+	for i := 0; i < 5; i++ {
+		_, err = here.OnPlayerAdded(ctx, "chain", fmt.Sprintf("UHIT%d", i), "", "")
+		require.NoError(t, err)
+	}
+	require.NoError(t, here.OnGameStarted(ctx, "chain", "UBOSS"))
+	elsewhere, _ := serve()
+
+	victim, _ := herePlayers.GetPlayer("chain", "UHIT0")
+	next, _ := herePlayers.GetPlayerByID(victim.Target)
+	require.NoError(t, here.OnKillReported(ctx, "chain", "UHIT0"))
+	require.NoError(t, elsewhere.OnKillReported(ctx, "chain", next.SlackID.ToString()))
+	require.Contains(t, logBuf.String(), "OnKillReported: retrying after conflict")
+
+	_, stored := serve()
+	killed, err := stored.GetPlayerByID(next.GetID())
+	require.NoError(t, err)
+	require.Equal(t, types.Dead, killed.Status)
+	require.Equal(t, victim.KilledBy, killed.KilledBy, "The first assassin inherited the target, so made the second kill")
+}
+
+func TestHandler_OnKillReported_FinalKill(t *testing.T) {
+	testHandler, _, gPool, blog := getHandlerWithMocksAndLogger(t)
+	lastStand := newGameFromArgs(gameArgs{gameid: "laststand", creator: "UBOSS", numPlayers: 5, status: types.Playing})
+	setGPoolControlsFromArgs(gPool, gPoolControls{gamesList: []*types.Game{ lastStand }})
+	gPool.ReportKillWinner = "laststand+UWINNER"
+
+	t.Run("positive", func(t *testing.T) {
+		err := testHandler.OnKillReported(context.Background(), "laststand", "URUNNERUP")
+		require.NoError(t, err)
+		require.Equal(t, types.Finished, lastStand.Status)
+		require.Contains(t, blog.String(), "Game laststand won by laststand+UWINNER")
+	})
+}
+
+func TestHandler_DirectMessages(t *testing.T) {
+	ctx := context.Background()
+	store := dao.NewMemorySession()
+	logBuf := &bytes.Buffer{}
+	dms := &slack.RecordingClient{}
+	pp := newPlayerPool(t, store)
+	testHandler := NewHandler(newGamePool(t, store, pp), store, log.New(logBuf, "handler_test: ", 0), dms)
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	_, _, err := testHandler.OnDictionaryCreated(ctx, "afile.txt", words)
+	require.NoError(t, err)
+	require.NoError(t, testHandler.OnGameCreated(ctx, "whisper", "UBOSS", "afile.txt", "melod", ""))
+	for i := 0; i < 5; i++ {
+		_, err = testHandler.OnPlayerAdded(ctx, "whisper", fmt.Sprintf("UHIT%d", i), fmt.Sprintf("Hitter %d", i), "")
+		require.NoError(t, err)
+	}
+	testHandler.FlushMessages()
+	require.Empty(t, dms.Posted(), "Nothing to say until the game starts")
+
+	t.Run("Targets sent on start", func(t *testing.T) {
+		require.NoError(t, testHandler.OnGameStarted(ctx, "whisper", "UBOSS"))
+		testHandler.FlushMessages()
+		require.Len(t, dms.Posted(), 5)
+		for i := 0; i < 5; i++ {
+			player, _ := pp.GetPlayer("whisper", slack.SlackID(fmt.Sprintf("UHIT%d", i)))
+			target, _ := pp.GetPlayerByID(player.Target)
+			require.Equal(t, []string{
+				fmt.Sprintf("Game whisper: your target is %s. Your kill word is *%s*", target.Name, player.KillWord),
+			}, dms.DirectMessages(player.SlackID))
+		}
+	})
+	t.Run("Inherited target sent after a kill", func(t *testing.T) {
+		victim, _ := pp.GetPlayer("whisper", "UHIT0")
+		inherited, _ := pp.GetPlayerByID(victim.Target)
+		require.NoError(t, testHandler.OnKillReported(ctx, "whisper", "UHIT0"))
+		testHandler.FlushMessages()
+		require.Len(t, dms.Posted(), 6, "Only the assassin hears about it")
+		assassin, _ := pp.GetPlayerByID(victim.KilledBy)
+		sent := dms.DirectMessages(assassin.SlackID)
+		require.Len(t, sent, 2)
+		require.Equal(t, fmt.Sprintf("Game whisper: your target is %s. Your kill word is *%s*", inherited.Name, assassin.KillWord), sent[1])
+	})
+	t.Run("Slack failures are logged, not returned", func(t *testing.T) {
+		dms.FailWith = errors.New("(mock) channel_not_found")
+		defer func() { dms.FailWith = nil }()
+		require.NoError(t, testHandler.OnKillReported(ctx, "whisper", "UHIT1"))
+		testHandler.FlushMessages()
+		require.Contains(t, logBuf.String(), "direct message to")
+		require.Contains(t, logBuf.String(), "(mock) channel_not_found")
+	})
+}
+
+func TestHandler_OnKillConfirmed(t *testing.T) {
+	ctx := context.Background()
+	store := dao.NewMemorySession()
+	pp := newPlayerPool(t, store)
+	testHandler := NewHandler(newGamePool(t, store, pp), store, log.New(&bytes.Buffer{}, "handler_test: ", 0))
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	_, _, err := testHandler.OnDictionaryCreated(ctx, "afile.txt", words)
+	require.NoError(t, err)
+	require.NoError(t, testHandler.OnGameCreated(ctx, "sworn", "UBOSS", "afile.txt", "melod", ""))
+	for i := 0; i < 5; i++ {
+		_, err = testHandler.OnPlayerAdded(ctx, "sworn", fmt.Sprintf("UHIT%d", i), "", "")
+		require.NoError(t, err)
+	}
+	err = testHandler.OnDeathClaimed(ctx, "sworn", "UHIT0")
+	require.True(t, errors.Is(err, types.ErrInvalidState), "Not before the game starts")
+	require.NoError(t, testHandler.OnGameStarted(ctx, "sworn", "UBOSS"))
+	victim, _ := pp.GetPlayer("sworn", "UHIT0")
+	var assassin *types.Player
+	for i := 0; i < 5; i++ {
+		if p, _ := pp.GetPlayer("sworn", slack.SlackID(fmt.Sprintf("UHIT%d", i))); p.Target == victim.GetID() {
+			assassin = p
+		}
+	}
+
+	require.NoError(t, testHandler.OnDeathClaimed(ctx, "sworn", victim.SlackID))
+	err = testHandler.OnKillConfirmed(ctx, "sworn", victim.SlackID, victim.SlackID)
+	require.True(t, errors.Is(err, types.ErrNotFound), "The victim can't confirm their own claim")
+	// The assassin is killed before they get round to confirming, so the victim is someone else's now
+	require.NoError(t, testHandler.OnKillReported(ctx, "sworn", assassin.SlackID.ToString()))
+	err = testHandler.OnKillConfirmed(ctx, "sworn", victim.SlackID, assassin.SlackID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("OnKillConfirmed: %s is no longer hunting UHIT0 in game sworn", assassin.SlackID))
+	require.True(t, victim.IsAlive())
+	err = testHandler.OnKillConfirmed(ctx, "sworn", victim.SlackID, assassin.SlackID)
+	require.True(t, errors.Is(err, types.ErrNotFound), "A kill that can't go through isn't kept waiting")
+	err = testHandler.OnDeathClaimed(ctx, "sworn", "UNOBODY")
+	require.True(t, errors.Is(err, types.ErrNotFound))
+	require.Contains(t, err.Error(), "OnDeathClaimed: Nobody in game sworn is hunting UNOBODY")
+}
+
+func TestHandler_OnKillConfirmed_TryAgain(t *testing.T) {
+	ctx := context.Background()
+	testHandler, _, gPool, _ := getHandlerWithMocksAndLogger(t)
+	sworn := newGameFromArgs(gameArgs{gameid: "sworn", creator: "UBOSS", numPlayers: 5, status: types.Playing})
+	setGPoolControlsFromArgs(gPool, gPoolControls{gamesList: []*types.Game{sworn}})
+	newPlayer := func(slackid string) *types.Player {
+		pae, _ := events.NewPlayerAddedEvent("sworn", slackid, "", "")
+		p := types.NewPlayerFromEvent(pae)
+		return &p
+	}
+	victim, assassin := newPlayer("UVICTIM"), newPlayer("UHUNTER")
+	assassin.SetTarget(victim.GetID(), "word000")
+	gPool.PlayersToReturn = []*types.Player{victim, assassin}
+
+	require.NoError(t, testHandler.OnDeathClaimed(ctx, "sworn", "UVICTIM"))
+	gPool.ReportKillError = "(mock) store down"
+	gPool.ErrorKind = types.ErrStoreUnavailable
+	err := testHandler.OnKillConfirmed(ctx, "sworn", "UVICTIM", "UHUNTER")
+	require.True(t, errors.Is(err, types.ErrStoreUnavailable), "%v", err)
+
+	gPool.ReportKillError = ""
+	gPool.KillReported = types.KillReportedCall{}
+	require.NoError(t, testHandler.OnKillConfirmed(ctx, "sworn", "UVICTIM", "UHUNTER"), "The kill waited to be confirmed again")
+	require.Equal(t, "sworn", gPool.KillReported.GameID)
+	err = testHandler.OnKillConfirmed(ctx, "sworn", "UVICTIM", "UHUNTER")
+	require.True(t, errors.Is(err, types.ErrNotFound), "Once it's through, it's gone")
+}
+
+// TestHandler_Concurrent hammers a handler backed by the real pools from many goroutines at once. Run it with -race
+// to check the locking; without it, the counts still catch lost updates.
+func TestHandler_Concurrent(t *testing.T) {
+	mm := dao.NewMockMongoSession()
+	mm.CollectionResults = map[string][]dao.Persistable{ types.CollectionName: mockDictionary("afile.txt", 100) }
+	pp := newPlayerPool(t, mm)
+	gp := newGamePool(t, mm, pp)
+	testHandler := NewHandler(gp, mm, log.New(&bytes.Buffer{}, "handler_test: ", 0))
+	const numGames, numPlayers = 4, 20
+	gameid := func(g int) string { return fmt.Sprintf("crowd%d", g) }
+	for g := 0; g < numGames; g++ {
+		require.NoError(t, testHandler.OnGameCreated(context.Background(), gameid(g), "UBOSS", "afile.txt", "sesame", ""))
+	}
+
+	// hammer runs f for every player in every game at once, while readers look on, and counts the successes per game
+	hammer := func(f func(g, p int) error) (succeeded [numGames]int32) {
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					testHandler.GetGamesList()
+					testHandler.GetGameStatus(gameid(0))
+				}
+			}
+		}()
+		for g := 0; g < numGames; g++ {
+			for p := 0; p < numPlayers; p++ {
+				wg.Add(1)
+				go func(g, p int) {
+					defer wg.Done()
+					if f(g, p) == nil {
+						atomic.AddInt32(&succeeded[g], 1)
+					}
+				}(g, p)
+			}
+		}
+		wg.Wait()
+		close(done)
+		return
+	}
+
+	t.Run("joins", func(t *testing.T) {
+		// Every other player tries to join twice. Only one of each pair can get in
+		joined := hammer(func(g, p int) error {
+			_, err := testHandler.OnPlayerAdded(context.Background(), gameid(g), fmt.Sprintf("UPLAYER%d", p/2*2), "", "")
+			return err
+		})
+		for g := 0; g < numGames; g++ {
+			require.Equal(t, int32(numPlayers/2), joined[g], "Duplicate joins are refused")
+			game, _ := gp.GetGame(gameid(g))
+			require.Equal(t, numPlayers/2, game.StartPlayers, "No joins lost or double counted in %s", gameid(g))
+			players, _ := pp.GetAllPlayersInGame(gameid(g))
+			require.Len(t, players, numPlayers/2)
+		}
+	})
+	t.Run("starts", func(t *testing.T) {
+		started := hammer(func(g, p int) error { return testHandler.OnGameStarted(context.Background(), gameid(g), "UBOSS") })
+		for g := 0; g < numGames; g++ {
+			require.Equal(t, int32(1), started[g], "Each game starts once")
+		}
+	})
+	t.Run("kills", func(t *testing.T) {
+		// Everyone but the last player dies, in whatever order the scheduler likes. Each victim is reported twice
+		killed := hammer(func(g, p int) error {
+			if p/2*2 == numPlayers-2 {
+				return fmt.Errorf("survivor")
+			}
+			return testHandler.OnKillReported(context.Background(), gameid(g), fmt.Sprintf("UPLAYER%d", p/2*2))
+		})
+		for g := 0; g < numGames; g++ {
+			require.Equal(t, int32(numPlayers/2-1), killed[g], "Each victim dies once")
+			game, _ := gp.GetGame(gameid(g))
+			require.Equal(t, types.Finished, game.Status)
+			require.Equal(t, 1, game.RemainPlayers)
+			require.Equal(t, fmt.Sprintf("%s+UPLAYER%d", gameid(g), numPlayers-2), game.Winner)
+			players, _ := pp.GetAllPlayersInGame(gameid(g))
+			kills := 0
+			for _, p := range players {
+				kills += p.Kills
+			}
+			require.Equal(t, numPlayers/2-1, kills, "The ring stayed intact in %s", gameid(g))
+		}
+	})
+}
+
 func TestHandler_GetGameStatus(t *testing.T) {
 	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
 	require.NotNil(t, mongo, "Placeholder to use mongo mock -- remove if mocking not needed")
@@ -446,11 +1004,13 @@ func TestHandler_GetGameStatus(t *testing.T) {
 			numPlayers: 7,
 		},
 	)
-	gPool.AddGame(testGame)
+	gPool.AddGame(context.Background(), testGame)
 	t.Run("positive", func(t *testing.T) {
+		gPool.QueueDepthToReturn = 3
 		statusReport, exists := testHandler.GetGameStatus("statusChecker")
 		require.True(t, exists, "Positive test should say the report exists")
 		require.NotNil(t, statusReport, "Status report should exist")
+		require.Contains(t, statusReport, "Queued commands: 3")
 	})
 	t.Run("missing game ID", func(t *testing.T) {
 		setGPoolControlsFromArgs(gPool, gPoolControls{
@@ -462,6 +1022,91 @@ func TestHandler_GetGameStatus(t *testing.T) {
 	})
 }
 
+func TestHandler_GetTarget(t *testing.T) {
+	testHandler, _, gPool, _ := getHandlerWithMocksAndLogger(t)
+	playing := newGameFromArgs(gameArgs{gameid: "hunting", creator: "USOMEONE", numPlayers: 3, status: types.Playing})
+	waiting := newGameFromArgs(gameArgs{gameid: "waiting", creator: "USOMEONE", numPlayers: 3})
+	token, hash, err := types.NewPlayerToken()
+	require.NoError(t, err)
+	newPlayer := func(gameid, slackid, name string) *types.Player {
+		pae, _ := events.NewPlayerAddedEvent(gameid, slackid, name, "")
+		pae.TokenHash = hash
+		p := types.NewPlayerFromEvent(pae)
+		return &p
+	}
+	hunter := newPlayer("hunting", "UHUNTER", "Elmer")
+	hunted := newPlayer("hunting", "UHUNTED", "Bugs")
+	hunter.SetTarget(hunted.GetID(), "wabbit")
+	corpse := newPlayer("hunting", "UCORPSE", "Daffy")
+	corpse.SetTarget(hunted.GetID(), "season")
+	corpse.Status = types.Dead
+	early := newPlayer("waiting", "UHUNTER", "Elmer")
+	lost := newPlayer("hunting", "ULOST", "Porky")
+	lost.SetTarget("hunting+UNOBODY", "duck")
+
+	tests := []struct {
+		name    string
+		gameid  string
+		slackid string
+		token   string
+		errText string
+	}{
+		{"positive", "hunting", "UHUNTER", token, ""},
+		{"wrong token", "hunting", "UHUNTER", "guessing", "Not authorized"},
+		{"blank token", "hunting", "UHUNTER", "", "Not authorized"},
+		{"unknown player", "hunting", "USTRANGER", token, "Not authorized"},
+		{"unknown game", "nogame", "UHUNTER", token, "Not authorized"},
+		{"game not in play", "waiting", "UHUNTER", token, "not in play"},
+		{"dead player", "hunting", "UCORPSE", token, "no longer alive"},
+		{"missing target", "hunting", "ULOST", token, "Target hunting+UNOBODY is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGPoolControlsFromArgs(gPool, gPoolControls{gamesList: []*types.Game{playing, waiting}})
+			if tt.gameid == "nogame" {
+				gPool.GetGameError = "(mock) missing ID"
+			}
+			gPool.PlayersToReturn = []*types.Player{hunter, hunted, corpse, early, lost}
+			assignment, err := testHandler.GetTarget(context.Background(), tt.gameid, tt.slackid, tt.token)
+			if tt.errText != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "GetTarget:", "All errors should start with the func name")
+				require.Contains(t, err.Error(), tt.errText)
+				require.Empty(t, assignment.KillWord, "Nothing leaks on failure")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "UHUNTED", assignment.TargetID)
+				require.Equal(t, "Bugs", assignment.TargetName)
+				require.Equal(t, "wabbit", assignment.KillWord)
+			}
+		})
+	}
+}
+
+func TestHandler_AuthenticatePlayer(t *testing.T) {
+	testHandler, _, gPool, _ := getHandlerWithMocksAndLogger(t)
+	token, hash, err := types.NewPlayerToken()
+	require.NoError(t, err)
+	pae, _ := events.NewPlayerAddedEvent("hunting", "UHUNTED", "Bugs", "")
+	pae.TokenHash = hash
+	hunted := types.NewPlayerFromEvent(pae)
+	gPool.PlayersToReturn = []*types.Player{&hunted}
+
+	require.NoError(t, testHandler.AuthenticatePlayer(context.Background(), "hunting", "UHUNTED", token))
+	for _, tt := range []struct{ name, gameid, slackid, token string }{
+		{"wrong token", "hunting", "UHUNTED", "guessing"},
+		{"blank token", "hunting", "UHUNTED", ""},
+		{"someone else", "hunting", "UHUNTER", token},
+		{"unknown game", "nogame", "UHUNTED", token},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testHandler.AuthenticatePlayer(context.Background(), tt.gameid, tt.slackid, tt.token)
+			require.True(t, errors.Is(err, types.ErrNotAuthorized), "%v", err)
+			require.Contains(t, err.Error(), "AuthenticatePlayer:", "All errors should start with the func name")
+		})
+	}
+}
+
 func TestHandler_GetGamesList(t *testing.T) {
 	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
 	require.NotNil(t, mongo, "Placeholder to use mongo mock -- remove if mocking not needed")
@@ -492,12 +1137,126 @@ func TestHandler_GetGamesList(t *testing.T) {
 		require.Contains(t, gamesList, "list_fodder_1")
 		require.Contains(t, gamesList, "list_fodder_2")
 	})
+	t.Run("finished game shows winner", func(t *testing.T) {
+		testGames[1].Status = types.Playing
+		testGames[1].Finish("list_fodder_2+UCHAMP")
+		gamesList := testHandler.GetGamesList()
+		require.Contains(t, gamesList, "list_fodder_2: finished, 2 players, won by list_fodder_2+UCHAMP in ")
+		require.NotContains(t, gamesList, "list_fodder_1: starting, 1 players, won by")
+	})
+}
+
+func TestHandler_Dictionaries(t *testing.T) {
+	testHandler, mongo, gPool, blog := getHandlerWithMocksAndLogger(t)
+	require.NotNil(t, blog, "Placeholder to use blog -- remove when log validation added")
+	existing := []dao.Persistable{}
+	for _, w := range []string{"alpha", "bravo", "charlie"} {
+		kw, _ := types.NewKillWord("phonetic", w)
+		existing = append(existing, &kw)
+	}
+	mongo.CollectionResults = map[string][]dao.Persistable{ types.CollectionName: existing }
+
+	t.Run("create: positive", func(t *testing.T) {
+		added, rejected, err := testHandler.OnDictionaryCreated(context.Background(), "greek", []string{"alpha", "pi", "gamma"})
+		require.NoError(t, err)
+		require.Equal(t, 2, added)
+		require.Len(t, rejected, 1, "pi is too short")
+	})
+	t.Run("create: already exists", func(t *testing.T) {
+		_, _, err := testHandler.OnDictionaryCreated(context.Background(), "phonetic", []string{"delta"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryCreated: KillDictionary phonetic already exists")
+	})
+	t.Run("create: no valid words", func(t *testing.T) {
+		_, rejected, err := testHandler.OnDictionaryCreated(context.Background(), "tiny", []string{"a", "b"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryCreated: KillDictionary tiny needs at least one valid word")
+		require.Len(t, rejected, 2)
+	})
+	t.Run("create: missing ID", func(t *testing.T) {
+		_, _, err := testHandler.OnDictionaryCreated(context.Background(), "", []string{"delta"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryCreated: The request is missing DictID")
+	})
+	t.Run("add words: positive", func(t *testing.T) {
+		added, rejected, err := testHandler.OnWordsAdded(context.Background(), "phonetic", []string{"delta", "echo"})
+		require.NoError(t, err)
+		require.Equal(t, 2, added)
+		require.Empty(t, rejected)
+	})
+	t.Run("add words: missing dictionary", func(t *testing.T) {
+		_, _, err := testHandler.OnWordsAdded(context.Background(), "nope", []string{"delta"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnWordsAdded: KillDictionary nope not found")
+	})
+	t.Run("remove word: positive", func(t *testing.T) {
+		require.NoError(t, testHandler.OnWordRemoved(context.Background(), "phonetic", "bravo"))
+	})
+	t.Run("remove word: not there", func(t *testing.T) {
+		err := testHandler.OnWordRemoved(context.Background(), "phonetic", "zulu")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnWordRemoved: RemoveWord: zulu is not in KillDictionary phonetic")
+	})
+	t.Run("list", func(t *testing.T) {
+		list, err := testHandler.GetDictionaryList(context.Background())
+		require.NoError(t, err)
+		require.Contains(t, list, "<h2>Dictionary List</h2>")
+		require.Contains(t, list, "<li>phonetic: 3 words</li>")
+	})
+	t.Run("import: into existing dictionary", func(t *testing.T) {
+		result, err := testHandler.OnDictionaryImported(context.Background(), "phonetic", "csv", strings.NewReader("word,category\nalpha,greek\nfoxtrot,dance\n"))
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Added)
+		require.Len(t, result.Rejected, 1)
+		require.Contains(t, result.Rejected[0], "line 2: alpha is a duplicate")
+	})
+	t.Run("import: creates dictionary", func(t *testing.T) {
+		result, err := testHandler.OnDictionaryImported(context.Background(), "nato", "", strings.NewReader("golf\nhotel\n"))
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Added)
+		require.Empty(t, result.Rejected)
+	})
+	t.Run("import: bad format", func(t *testing.T) {
+		_, err := testHandler.OnDictionaryImported(context.Background(), "nato", "xml", strings.NewReader("golf\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryImported: Unknown dictionary format: xml")
+	})
+	t.Run("import: missing ID", func(t *testing.T) {
+		_, err := testHandler.OnDictionaryImported(context.Background(), "", "text", strings.NewReader("golf\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryImported: The request is missing DictID")
+	})
+	t.Run("export: positive", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, testHandler.GetDictionaryExport(context.Background(), "phonetic", "text", &out))
+		require.Equal(t, "alpha\nbravo\ncharlie\n", out.String())
+	})
+	t.Run("export: missing dictionary", func(t *testing.T) {
+		err := testHandler.GetDictionaryExport(context.Background(), "nope", "json", &bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "GetDictionaryExport: KillDictionary nope not found")
+	})
+	t.Run("delete: in use", func(t *testing.T) {
+		inUse := newGameFromArgs(gameArgs{gameid: "wordy", creator: "UBOSS", killdict: "phonetic", status: types.Playing})
+		setGPoolControlsFromArgs(gPool, gPoolControls{gamesList: []*types.Game{ inUse }})
+		err := testHandler.OnDictionaryDeleted(context.Background(), "phonetic")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryDeleted: KillDictionary phonetic is in use by game wordy")
+		inUse.Status = types.Finished
+		require.NoError(t, testHandler.OnDictionaryDeleted(context.Background(), "phonetic"), "Finished games don't hold on to their dictionary")
+	})
+	t.Run("delete: missing dictionary", func(t *testing.T) {
+		err := testHandler.OnDictionaryDeleted(context.Background(), "nope")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OnDictionaryDeleted: KillDictionary nope not found")
+	})
 }
 
 /*** Helpers ***/
 
 func getHandlerWithMocksAndLogger(t *testing.T) (testHandler *Handler, mockMongo *dao.MockMongoSession, mockGPool *types.MockGamePool, logBuf *bytes.Buffer) {
 	mockMongo = dao.NewMockMongoSession()
+	mockMongo.CollectionResults = map[string][]dao.Persistable{ types.CollectionName: mockDictionary("notBlank", 20) }
 	mockGames := []*types.Game{
 		&types.Game{ID: "mockity", GameCreator: "UGOD"},
 	}
@@ -531,17 +1290,47 @@ func newGameFromArgs(args gameArgs) *types.Game {
 	gce, _ := events.NewGameCreatedEvent(args.gameid, slack.NewInline(args.creator), args.killdict, args.passcode)
 	myGame := types.NewGameFromEvent(gce)
 	myGame.StartPlayers = args.numPlayers
+	if args.status != "" {
+		myGame.Status = args.status
+	}
 
 	return &myGame
 }
 
+// mockDictionary builds the persisted form of a dictionary with the requested number of words, for loading into
+// the mongo mock
+// newPlayerPool creates a PlayerPool over the store, failing the test if the players in it can't be restored
+func newPlayerPool(t *testing.T, m dao.MongoAbstraction) *types.PlayerPool {
+	pool, err := types.NewPlayerPool(context.Background(), m)
+	require.NoError(t, err)
+	return pool
+}
+
+// newGamePool is types.NewGamePool for a store the test expects to restore from cleanly
+func newGamePool(t *testing.T, m dao.MongoAbstraction, pp types.PlayerPoolAbstraction) *types.GamePool {
+	pool, err := types.NewGamePool(context.Background(), m, pp)
+	require.NoError(t, err)
+	return pool
+}
+
+func mockDictionary(dictid string, numWords int) []dao.Persistable {
+	words := make([]dao.Persistable, numWords)
+	for i := 0; i < numWords; i++ {
+		kw, _ := types.NewKillWord(dictid, fmt.Sprintf("word%03d", i))
+		words[i] = &kw
+	}
+	return words
+}
+
 func setGPoolControlsFromArgs(gpool *types.MockGamePool, args gPoolControls) {
 	gpool.AddGameError = args.addGameErr
 	gpool.AddPlayerError = args.addPlayerErr
 	gpool.CanAddError = args.canAddErr
 	gpool.GetGameError = args.getGameErr
+	gpool.ReportKillError = args.reportKillErr
 	gpool.StartGameError = args.startGameErr
 	gpool.GamesToReturn = args.gamesList
+	gpool.ErrorKind = args.errKind
 }
 
 /*
@@ -549,13 +1338,13 @@ func createGameWithPlayers(h Handler, gameid string, numPlayers int) {
 	myGame := types.NewGameFromEvent(
 		events.NewGameCreatedEvent(gameid, "@testFunc", "some words", "sesame"),
 	)
-	h.gPool.AddGame(myGame)
+	h.gPool.AddGame(context.Background(), myGame)
 
 	for i := 0; i < numPlayers; i++ {
 		name := string("player#" + i)
 		pae, _ := events.NewPlayerAddedEvent(gameid, "@" + name, name, name + "@some.org")
 		p := types.NewPlayerFromEvent(pae)
-		h.pPool.AddPlayer(p)
+		h.pPool.AddPlayer(context.Background(), p)
 	}
 }
 */
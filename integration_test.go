@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+
+	dao "wordassassin/persistence"
+	"wordassassin/types"
+)
+
+// playGame creates a dictionary and a game of six players over the JSON API, plays it to the end, and deletes the
+// dictionary
+func playGame(t *testing.T, e *echo.Echo) {
+	call := func(method, path, body string, wantStatus int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, wantStatus, rec.Code, "%s %s: %s", method, path, rec.Body.String())
+		return rec
+	}
+
+	words := make([]string, 30)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%02d", i)
+	}
+	wordsJSON, _ := json.Marshal(words)
+	call(http.MethodPost, "/api/v1/dictionaries", fmt.Sprintf(`{"dictId": "memwords", "words": %s}`, wordsJSON), http.StatusCreated)
+	call(http.MethodPost, "/api/v1/dictionaries", `{"dictId": "memwords", "words": ["again"]}`, http.StatusConflict)
+
+	call(http.MethodPost, "/api/v1/games", `{"gameId": "memgame", "creator": "UBOSS", "killDictionary": "memwords", "passcode": "pwd"}`, http.StatusCreated)
+	call(http.MethodPost, "/api/v1/games", `{"gameId": "memgame", "creator": "UBOSS", "killDictionary": "memwords", "passcode": "pwd"}`, http.StatusConflict)
+	tokens := make([]string, 6)
+	for i := range tokens {
+		var added playerAddedResponse
+		rec := call(http.MethodPost, "/api/v1/games/memgame/players", fmt.Sprintf(`{"slackId": "UMEM%d"}`, i), http.StatusCreated)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &added))
+		tokens[i] = added.Token
+	}
+	call(http.MethodPost, "/api/v1/games/memgame/players", `{"slackId": "UMEM0"}`, http.StatusConflict)
+	call(http.MethodPost, "/api/v1/games/memgame/start", `{"slackId": "UBOSS"}`, http.StatusOK)
+	reportKill := func(i int, wantStatus int) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/games/memgame/kills", strings.NewReader(fmt.Sprintf(`{"slackId": "UMEM%d"}`, i)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+tokens[i])
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, wantStatus, rec.Code, "kill UMEM%d: %s", i, rec.Body.String())
+	}
+	for i := 5; i > 0; i-- {
+		reportKill(i, http.StatusOK)
+	}
+	reportKill(0, http.StatusConflict)
+
+	var view GameView
+	require.NoError(t, json.Unmarshal(call(http.MethodGet, "/api/v1/games/memgame", "", http.StatusOK).Body.Bytes(), &view))
+	require.Equal(t, "finished", view.Status)
+	require.Equal(t, "memgame+UMEM0", view.Winner)
+	require.Equal(t, 1, view.RemainPlayers)
+	// Once the game is over its dictionary can go, and the game can still be rebuilt from the events without it
+	call(http.MethodDelete, "/api/v1/dictionaries/memwords", "", http.StatusNoContent)
+}
+
+// TestIntegration_InMemory plays a whole game through the JSON API, with the in-memory store standing in for mongo,
+// then restarts from what was stored
+func TestIntegration_InMemory(t *testing.T) {
+	ms := dao.NewMemorySession()
+	playGame(t, startServer(t, ms))
+	requireRestarts(t, ms)
+}
+
+// TestIntegration_Files plays the same game over the file store, then restarts from the files it left behind
+func TestIntegration_Files(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := dao.NewFileSession(dir, log.New(&bytes.Buffer{}, "integration_test: ", 0), 10)
+	require.NoError(t, err)
+	playGame(t, startServer(t, fs))
+	require.NoError(t, fs.Close())
+
+	fs, err = dao.NewFileSession(dir, log.New(&bytes.Buffer{}, "integration_test: ", 0))
+	require.NoError(t, err)
+	defer fs.Close()
+	requireRestarts(t, fs)
+}
+
+// requireRestarts checks the finished game can be restored from the store, both from the snapshots and the events
+func requireRestarts(t *testing.T, ms dao.MongoAbstraction) {
+	t.Run("Restart from snapshots", func(t *testing.T) {
+		players := newPlayerPool(t, ms)
+		pool := newGamePool(t, ms, players)
+		game, exists := pool.GetGame("memgame")
+		require.True(t, exists)
+		require.Equal(t, types.Finished, game.Status)
+		require.Equal(t, "memgame+UMEM0", game.Winner)
+		inGame, err := players.GetAllPlayersInGame("memgame")
+		require.NoError(t, err)
+		require.Len(t, inGame, 6)
+	})
+	t.Run("Restart from events", func(t *testing.T) {
+		pool, players, err := types.RebuildPools(context.Background(), ms)
+		require.NoError(t, err)
+		game, exists := pool.GetGame("memgame")
+		require.True(t, exists)
+		require.Equal(t, types.Finished, game.Status)
+		require.Equal(t, "memgame+UMEM0", game.Winner)
+		victim, err := players.GetPlayer("memgame", "UMEM1")
+		require.NoError(t, err)
+		require.False(t, victim.IsAlive())
+	})
+}
+
+// startServer wires up the server's routes, and the handler behind them, to real pools over the given store
+func startServer(t *testing.T, m dao.MongoAbstraction) *echo.Echo {
+	players := newPlayerPool(t, m)
+	logger = log.New(&bytes.Buffer{}, "integration_test: ", 0)
+	handler = NewHandler(newGamePool(t, m, players), m, logger)
+	e := echo.New()
+	setRoutes(e)
+	setAPIRoutes(e)
+	return e
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	persistence "wordassassin/persistence"
+	slack "wordassassin/slack"
+)
+
+// messenger sends players direct messages in Slack. Messages go out in the background, so a slow or unreachable Slack
+// never holds up the request that prompted one. Nor does a message that fails, which is logged and dropped. Each user
+// gets their messages one at a time, in the order they were sent, so a new target never arrives ahead of the one it
+// replaces. Different users' messages don't wait on each other.
+type messenger struct {
+	client slack.Client
+	logger *log.Logger
+	mu     sync.Mutex                        // guards queues
+	queues map[slack.SlackID][]directMessage // messages waiting, for each user with a sender running
+	sent   sync.WaitGroup                    // messages still on their way
+}
+
+// directMessage is a message waiting its turn, with the context it's sent under
+type directMessage struct {
+	ctx context.Context
+	msg slack.Message
+}
+
+func newMessenger(client slack.Client, logger *log.Logger) *messenger {
+	return &messenger{client: client, logger: logger, queues: make(map[slack.SlackID][]directMessage)}
+}
+
+// tell direct messages a user some text
+func (m *messenger) tell(ctx context.Context, user slack.SlackID, format string, args ...interface{}) {
+	m.send(ctx, user, slack.Message{Text: fmt.Sprintf(format, args...)})
+}
+
+// send queues a direct message to a user, and starts a sender for them if there isn't one running. It is sent even if
+// the request has gone by then
+func (m *messenger) send(ctx context.Context, user slack.SlackID, msg slack.Message) {
+	m.mu.Lock()
+	m.sent.Add(1)
+	queue, sending := m.queues[user]
+	m.queues[user] = append(queue, directMessage{ctx: persistence.Detach(ctx), msg: msg})
+	m.mu.Unlock()
+	if !sending {
+		go m.run(user)
+	}
+}
+
+// run sends a user's queued messages until there are none left
+func (m *messenger) run(user slack.SlackID) {
+	for {
+		m.mu.Lock()
+		queue := m.queues[user]
+		if len(queue) == 0 {
+			delete(m.queues, user)
+			m.mu.Unlock()
+			return
+		}
+		dm := queue[0]
+		m.queues[user] = queue[1:]
+		m.mu.Unlock()
+		channel, err := m.client.OpenConversation(dm.ctx, user)
+		if err == nil {
+			_, err = m.client.PostMessage(dm.ctx, channel, dm.msg)
+		}
+		if err != nil {
+			m.logger.Printf("send: direct message to %s failed: %v", user, err)
+		}
+		m.sent.Done()
+	}
+}
+
+// wait returns once every message sent so far has gone out, or failed to
+func (m *messenger) wait() {
+	m.sent.Wait()
+}
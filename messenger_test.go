@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	slack "wordassassin/slack"
+)
+
+// heldClient records messages like RecordingClient, except the one with the held text waits until release is closed
+type heldClient struct {
+	*slack.RecordingClient
+	held    string
+	release chan struct{}
+}
+
+func (hc *heldClient) PostMessage(ctx context.Context, channel string, msg slack.Message) (string, error) {
+	if msg.Text == hc.held {
+		<-hc.release
+	}
+	return hc.RecordingClient.PostMessage(ctx, channel, msg)
+}
+
+func TestMessenger_InOrder(t *testing.T) {
+	client := &heldClient{RecordingClient: &slack.RecordingClient{}, held: "Your target is UOLD", release: make(chan struct{})}
+	m := newMessenger(client, log.New(&bytes.Buffer{}, "messenger_test: ", 0))
+	m.tell(context.Background(), "UHUNTER", "Your target is UOLD")
+	m.tell(context.Background(), "UHUNTER", "Your target is UNEW")
+	m.tell(context.Background(), "UBYSTANDER", "Hello")
+
+	require.Eventually(t, func() bool {
+		return len(client.DirectMessages("UBYSTANDER")) == 1
+	}, time.Second, time.Millisecond, "Other users aren't held up")
+	require.Empty(t, client.DirectMessages("UHUNTER"), "The new target waits for the old one")
+	close(client.release)
+	m.wait()
+	require.Equal(t, []string{"Your target is UOLD", "Your target is UNEW"}, client.DirectMessages("UHUNTER"))
+}
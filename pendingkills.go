@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sync"
+
+	slack "wordassassin/slack"
+)
+
+// pendingKill is a kill one party has vouched for, waiting on the other to confirm it
+type pendingKill struct {
+	gameid   string
+	victim   slack.SlackID
+	assassin slack.SlackID
+	waitOn   slack.SlackID // whichever of the two has to confirm
+}
+
+// pendingKills holds the kills waiting on confirmation, one per victim. They're only kept in memory, so a restart
+// forgets them, and the victim has to raise theirs again. It is safe for concurrent use.
+type pendingKills struct {
+	mu    sync.Mutex
+	kills map[string]pendingKill // by the victim's player ID
+}
+
+func newPendingKills() *pendingKills {
+	return &pendingKills{kills: make(map[string]pendingKill)}
+}
+
+// raise records a kill waiting on confirmation, replacing any already waiting for the same victim
+func (p *pendingKills) raise(kill pendingKill) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kills[kill.gameid+"+"+kill.victim.ToString()] = kill
+}
+
+// take removes the kill of the victim waiting on the given party to confirm it, and gives it back. Kills waiting on
+// someone else are left alone
+func (p *pendingKills) take(gameid string, victim slack.SlackID, waitOn slack.SlackID) (kill pendingKill, found bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := gameid + "+" + victim.ToString()
+	if kill, found = p.kills[id]; found && kill.waitOn == waitOn {
+		delete(p.kills, id)
+		return kill, true
+	}
+	return pendingKill{}, false
+}
+
+// restore puts back a kill taken for confirmation that couldn't go through this time, so it can be confirmed again. A
+// kill raised for the same victim in the meantime is newer, and stays
+func (p *pendingKills) restore(kill pendingKill) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := kill.gameid + "+" + kill.victim.ToString()
+	if _, exists := p.kills[id]; !exists {
+		p.kills[id] = kill
+	}
+}
+
+// waiting checks whether this very kill is already waiting on confirmation
+func (p *pendingKills) waiting(kill pendingKill) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.kills[kill.gameid+"+"+kill.victim.ToString()] == kill
+}
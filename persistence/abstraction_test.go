@@ -0,0 +1,330 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+// AbstractionSuite holds every MongoAbstraction to the behavior the MongoSession suite expects of mongo. Unlike that
+// suite it sets up state through the store under test, so it can run against stores that have no other way in
+type AbstractionSuite struct {
+	suite.Suite
+	open  func(t *testing.T) MongoAbstraction
+	store MongoAbstraction
+}
+
+func (a *AbstractionSuite) SetupTest() {
+	a.store = a.open(a.T())
+}
+
+func TestMemorySessionConformance(t *testing.T) {
+	suite.Run(t, &AbstractionSuite{open: func(t *testing.T) MongoAbstraction {
+		return NewMemorySession()
+	}})
+}
+
+func TestFileSessionConformance(t *testing.T) {
+	suite.Run(t, &AbstractionSuite{open: func(t *testing.T) MongoAbstraction {
+		return openFileSession(t, t.TempDir())
+	}})
+}
+
+// TestFileSessionConformance_Reopened reopens the directory after every change, compacting as often as it can, so
+// every check is made against what came back off disk
+func TestFileSessionConformance_Reopened(t *testing.T) {
+	suite.Run(t, &AbstractionSuite{open: func(t *testing.T) MongoAbstraction {
+		return &reopeningSession{t: t, dir: t.TempDir()}
+	}})
+}
+
+func (a *AbstractionSuite) TestDeleteFromCollection() {
+	testEvent := GenericPersistable{ID: "-13", Name: "@wilma.f", ANumber: -13}
+
+	a.T().Run("Positive", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(context.Background(), TestCollection, testEvent))
+		require.NoError(t, a.store.DeleteFromCollection(context.Background(), TestCollection, testEvent.GetID()))
+		_, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
+		require.True(t, errors.Is(err, ErrNotFound), "A deleted id should be not found on fetch. Instead got %v", err)
+		require.Contains(t, err.Error(), "no documents")
+	})
+	a.T().Run("Missing ID", func(t *testing.T) {
+		testID := "I don't exist"
+		err := a.store.DeleteFromCollection(context.Background(), TestCollection, testID)
+		require.True(t, errors.Is(err, ErrNotFound), "Delete on missing ID should be not found. Instead got %v", err)
+		require.Contains(t, err.Error(), fmt.Sprintf("no documents for id=%s", testID))
+	})
+	a.T().Run("CollectionNotExist", func(t *testing.T) {
+		testID := "it matters not"
+		err := a.store.DeleteFromCollection(context.Background(), "garbage", testID)
+		require.True(t, errors.Is(err, ErrNotFound), "Delete on missing collection should be not found. Instead got %v", err)
+		require.Contains(t, err.Error(), fmt.Sprintf("no documents for id=%s", testID))
+	})
+}
+
+func (a *AbstractionSuite) TestFetchAllFromCollection() {
+	testEvents := []GenericPersistable{
+		{ID: "31", Name: "Barney", TimeCreated: time.Unix(0, 0), ANumber: 21},
+		{ID: "41", Name: "Betty", TimeCreated: time.Unix(50000, 0), ANumber: 21},
+		{ID: "51", Name: "Bam Bam", TimeCreated: time.Unix(3000000, 0), ANumber: 44},
+	}
+	for _, v := range testEvents {
+		require.NoError(a.T(), a.store.WriteCollection(context.Background(), TestCollection, v))
+	}
+
+	a.T().Run("Positive", func(t *testing.T) {
+		results, err := a.store.FetchAllFromCollection(context.Background(), TestCollection)
+		require.NoError(t, err)
+		require.Len(t, results, len(testEvents))
+		for i, b := range results {
+			actual := GenericPersistable{}
+			require.NoError(t, actual.Decode(b))
+			require.Equal(t, testEvents[i].GetID(), actual.GetID(), "Results come back in the order written")
+			require.True(t, testEvents[i].TimeCreated.Equal(actual.TimeCreated))
+		}
+	})
+	a.T().Run("Query", func(t *testing.T) {
+		results, err := a.store.FetchFromCollection(context.Background(), TestCollection, bson.M{"anumber": 21})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		count, err := a.store.CountInCollection(context.Background(), TestCollection, bson.M{"name": "Betty"})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	})
+	a.T().Run("CollectionNotExist", func(t *testing.T) {
+		results, err := a.store.FetchAllFromCollection(context.Background(), "garbage")
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+}
+
+func (a *AbstractionSuite) TestFetchIDFromCollection() {
+	testEvent := GenericPersistable{ID: "31", TimeCreated: time.Unix(63667135112, 0), Name: "Barney", ANumber: 31}
+	require.NoError(a.T(), a.store.WriteCollection(context.Background(), TestCollection, testEvent))
+
+	a.T().Run("Positive", func(t *testing.T) {
+		result := GenericPersistable{}
+		resultBytes, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
+		require.NoError(t, err)
+		require.NoError(t, result.Decode(resultBytes))
+		require.Equal(t, testEvent.GetID(), result.GetID())
+		require.True(t, testEvent.TimeCreated.Equal(result.TimeCreated))
+		require.Equal(t, testEvent.Name, result.Name)
+	})
+	a.T().Run("Missing ID", func(t *testing.T) {
+		_, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, "I an I bad, mon")
+		require.True(t, errors.Is(err, ErrNotFound), "Missing id should be not found. Instead got %v", err)
+		require.Contains(t, err.Error(), "no documents")
+	})
+}
+
+func (a *AbstractionSuite) TestUpdateCollection() {
+	testEvent := GenericPersistable{ID: "-13", TimeCreated: time.Unix(63667134985, 13).UTC(), Name: "@wilma.f", ANumber: -13}
+
+	a.T().Run("Positive", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(context.Background(), TestCollection, testEvent))
+		updateEvent := testEvent
+		updateEvent.Name = "@new.name"
+		require.NoError(t, a.store.UpdateCollection(context.Background(), TestCollection, updateEvent))
+		resultBytes, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, updateEvent.GetID())
+		require.NoError(t, err)
+		var actual GenericPersistable
+		require.NoError(t, actual.Decode(resultBytes))
+		require.Equal(t, "@new.name", actual.Name)
+	})
+	a.T().Run("MissingID", func(t *testing.T) {
+		badIDEvent := testEvent
+		badIDEvent.ID = "I b missing"
+		err := a.store.UpdateCollection(context.Background(), TestCollection, badIDEvent)
+		require.True(t, errors.Is(err, ErrNotFound), "Missing ID should be not found on update. Instead got %v", err)
+		require.Contains(t, err.Error(), "no documents")
+	})
+	a.T().Run("CollectionNotExist", func(t *testing.T) {
+		err := a.store.UpdateCollection(context.Background(), "garbage", testEvent)
+		require.True(t, errors.Is(err, ErrNotFound), "Missing collection should be not found on update. Instead got %v", err)
+		require.Contains(t, err.Error(), "no documents")
+	})
+}
+
+func (a *AbstractionSuite) TestUpdateCollection_Versioned() {
+	ctx := context.Background()
+	read := func(t *testing.T, id string) *VersionedPersistable {
+		raw, err := a.store.FetchIDFromCollection(ctx, TestCollection, id)
+		require.NoError(t, err)
+		var got VersionedPersistable
+		require.NoError(t, bson.Unmarshal(raw, &got))
+		return &got
+	}
+
+	a.T().Run("Conflict", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(ctx, TestCollection, &VersionedPersistable{ID: "contested", Name: "original"}))
+		first, second := read(t, "contested"), read(t, "contested")
+		first.Name = "first"
+		require.NoError(t, a.store.UpdateCollection(ctx, TestCollection, first))
+		require.Equal(t, int64(1), first.Version, "The update moves the object on to the version it stored")
+		second.Name = "second"
+		err := a.store.UpdateCollection(ctx, TestCollection, second)
+		require.True(t, errors.Is(err, ErrConflict), "The second writer read a version that's gone. Instead got %v", err)
+		require.Equal(t, int64(0), second.Version, "A failed update leaves the version alone")
+		require.Equal(t, "first", read(t, "contested").Name, "The first update isn't lost")
+
+		second = read(t, "contested")
+		second.Name = "second"
+		require.NoError(t, a.store.UpdateCollection(ctx, TestCollection, second), "Reading again and retrying goes through")
+		require.Equal(t, int64(2), read(t, "contested").Version)
+	})
+	a.T().Run("Unversioned document", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(ctx, TestCollection, GenericPersistable{ID: "legacy", Name: "legacy"}))
+		update := &VersionedPersistable{ID: "legacy", Name: "versioned"}
+		require.NoError(t, a.store.UpdateCollection(ctx, TestCollection, update), "Documents from before versioning are at version 0")
+		require.Equal(t, int64(1), read(t, "legacy").Version)
+	})
+	a.T().Run("MissingID", func(t *testing.T) {
+		missing := &VersionedPersistable{ID: "I b missing", Version: 3}
+		err := a.store.UpdateCollection(ctx, TestCollection, missing)
+		require.True(t, errors.Is(err, ErrNotFound), "Missing ID is not a conflict. Instead got %v", err)
+		require.Equal(t, int64(3), missing.Version)
+	})
+}
+
+func (a *AbstractionSuite) TestWriteCollection() {
+	testEvent := GenericPersistable{ID: "13", Name: "Fred", ANumber: 13}
+
+	a.T().Run("Positive", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(context.Background(), TestCollection, testEvent))
+		_, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
+		require.NoError(t, err, "Failed to validate write for id=%s", testEvent.GetID())
+	})
+	a.T().Run("DuplicateInsertShouldError", func(t *testing.T) {
+		err := a.store.WriteCollection(context.Background(), TestCollection, testEvent)
+		require.True(t, errors.Is(err, ErrDuplicate), "Attempt to insert duplicate should be a duplicate. Instead got %v", err)
+		require.Contains(t, err.Error(), "duplicate")
+	})
+	a.T().Run("CollectionNotExistShouldStillWrite", func(t *testing.T) {
+		require.NoError(t, a.store.WriteCollection(context.Background(), "garbage", testEvent), "Writes should create collection on the fly")
+		count, err := a.store.CountInCollection(context.Background(), "garbage", bson.M{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count, "Record should have been written as only entry")
+	})
+}
+
+func (a *AbstractionSuite) TestWriteManyToCollection() {
+	require.NoError(a.T(), a.store.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "b"}))
+	failed, err := a.store.WriteManyToCollection(context.Background(), TestCollection, []Persistable{
+		GenericPersistable{ID: "a"}, GenericPersistable{ID: "b"}, GenericPersistable{ID: "c"}, GenericPersistable{ID: "a"},
+	})
+	require.NoError(a.T(), err)
+	require.Len(a.T(), failed, 2)
+	require.True(a.T(), errors.Is(failed[1], ErrDuplicate))
+	require.True(a.T(), errors.Is(failed[3], ErrDuplicate), "Duplicates within the batch are caught too")
+	count, err := a.store.CountInCollection(context.Background(), TestCollection, bson.M{})
+	require.NoError(a.T(), err)
+	require.Equal(a.T(), int64(3), count)
+}
+
+func (a *AbstractionSuite) TestCancelledContext() {
+	require.NoError(a.T(), a.store.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "13"}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requireAbandoned := func(t *testing.T, err error) {
+		require.True(t, errors.Is(err, ErrStoreUnavailable), "Expected the store to give up. Instead got %v", err)
+		require.True(t, errors.Is(err, context.Canceled), "The reason should be reachable. Instead got %v", err)
+	}
+
+	a.T().Run("Reads", func(t *testing.T) {
+		_, err := a.store.FetchIDFromCollection(ctx, TestCollection, "13")
+		requireAbandoned(t, err)
+		_, err = a.store.FetchAllFromCollection(ctx, TestCollection)
+		requireAbandoned(t, err)
+	})
+	a.T().Run("Writes", func(t *testing.T) {
+		requireAbandoned(t, a.store.WriteCollection(ctx, TestCollection, GenericPersistable{ID: "14"}))
+		requireAbandoned(t, a.store.UpdateCollection(ctx, TestCollection, GenericPersistable{ID: "13", Name: "changed"}))
+		requireAbandoned(t, a.store.DeleteFromCollection(ctx, TestCollection, "13"))
+		raw, err := a.store.FetchIDFromCollection(context.Background(), TestCollection, "13")
+		require.NoError(t, err, "Nothing was deleted")
+		var got GenericPersistable
+		require.NoError(t, got.Decode(raw))
+		require.Empty(t, got.Name, "Nothing was updated")
+	})
+	a.T().Run("Past deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		_, err := a.store.CountInCollection(ctx, TestCollection, bson.M{})
+		require.True(t, errors.Is(err, context.DeadlineExceeded), "Expected the deadline to be honored. Instead got %v", err)
+	})
+}
+
+/*** Helper functions ***/
+
+func openFileSession(t *testing.T, dir string, compactEvery ...int) *FileSession {
+	fs, err := NewFileSession(dir, discardLogger(), compactEvery...)
+	require.NoError(t, err, "Failed to open FileSession in %s", dir)
+	t.Cleanup(func() { fs.Close() })
+	return fs
+}
+
+// reopeningSession is a FileSession that is closed and opened again after every change
+type reopeningSession struct {
+	t   *testing.T
+	dir string
+	fs  *FileSession
+}
+
+func (r *reopeningSession) session() *FileSession {
+	if r.fs == nil {
+		r.fs = openFileSession(r.t, r.dir, 2)
+	}
+	return r.fs
+}
+
+func (r *reopeningSession) reopen() {
+	require.NoError(r.t, r.fs.Close())
+	r.fs = nil
+}
+
+func (r *reopeningSession) ConnectToMongo(ctx context.Context) error {
+	return r.session().ConnectToMongo(ctx)
+}
+
+func (r *reopeningSession) CountInCollection(ctx context.Context, coll string, query bson.M) (int64, error) {
+	return r.session().CountInCollection(ctx, coll, query)
+}
+
+func (r *reopeningSession) DeleteFromCollection(ctx context.Context, coll string, id string) error {
+	defer r.reopen()
+	return r.session().DeleteFromCollection(ctx, coll, id)
+}
+
+func (r *reopeningSession) FetchAllFromCollection(ctx context.Context, coll string) ([][]byte, error) {
+	return r.session().FetchAllFromCollection(ctx, coll)
+}
+
+func (r *reopeningSession) FetchFromCollection(ctx context.Context, coll string, query bson.M) ([][]byte, error) {
+	return r.session().FetchFromCollection(ctx, coll, query)
+}
+
+func (r *reopeningSession) FetchIDFromCollection(ctx context.Context, coll string, id string) ([]byte, error) {
+	return r.session().FetchIDFromCollection(ctx, coll, id)
+}
+
+func (r *reopeningSession) UpdateCollection(ctx context.Context, coll string, obj Persistable) error {
+	defer r.reopen()
+	return r.session().UpdateCollection(ctx, coll, obj)
+}
+
+func (r *reopeningSession) WriteCollection(ctx context.Context, coll string, obj Persistable) error {
+	defer r.reopen()
+	return r.session().WriteCollection(ctx, coll, obj)
+}
+
+func (r *reopeningSession) WriteManyToCollection(ctx context.Context, coll string, objs []Persistable) (map[int]error, error) {
+	defer r.reopen()
+	return r.session().WriteManyToCollection(ctx, coll, objs)
+}
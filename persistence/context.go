@@ -0,0 +1,29 @@
+package persistence
+
+import (
+	"context"
+	"time"
+)
+
+// Detach gives a context that carries the values of ctx but none of its cancellation or deadline. It's for work that
+// has to finish even though the request that started it has gone, such as backing out a half made change
+func Detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (deadline time.Time, ok bool) { return }
+func (detached) Done() <-chan struct{}                   { return nil }
+func (detached) Err() error                              { return nil }
+func (d detached) Value(key interface{}) interface{}     { return d.parent.Value(key) }
+
+// checkContext fails with ErrStoreUnavailable, wrapping the reason, once ctx is cancelled or past its deadline
+func checkContext(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return Errorf(ErrStoreUnavailable, "%s abandoned: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for the kinds of failure that callers need to tell apart. Errors from this layer, and from the
+// layers built on it, carry one of these so they can be checked with errors.Is rather than by what the message says
+var (
+	// ErrDuplicate means an object with the same ID already exists
+	ErrDuplicate = errors.New("duplicate")
+	// ErrNotFound means there is no object with the ID asked for
+	ErrNotFound = errors.New("not found")
+	// ErrStoreUnavailable means the store can't take requests right now. Trying again later may work
+	ErrStoreUnavailable = errors.New("store unavailable")
+	// ErrConflict means a Versioned object was changed by someone else since it was read. Read it again and retry
+	ErrConflict = errors.New("conflict")
+)
+
+// kindError is an error of a known kind. The message is written for people, and the kind is for code to check
+type kindError struct {
+	kind  error
+	msg   string
+	cause error
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+// Is matches the error's kind. errors.Is goes on to check the cause too
+func (e *kindError) Is(target error) bool { return target == e.kind }
+
+func (e *kindError) Unwrap() error { return e.cause }
+
+// Errorf formats an error of the given kind, which is usually one of the sentinel errors. The message is exactly what
+// format produces. A %w verb in format keeps the wrapped error reachable by errors.Is and errors.As as well
+func Errorf(kind error, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	return &kindError{kind: kind, msg: err.Error(), cause: errors.Unwrap(err)}
+}
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	mgo "gopkg.in/mgo.v2"
+)
+
+func TestErrorf(t *testing.T) {
+	t.Run("Kind", func(t *testing.T) {
+		err := Errorf(ErrNotFound, "no documents for id=%s", "abc")
+		require.EqualError(t, err, "no documents for id=abc")
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.False(t, errors.Is(err, ErrDuplicate))
+	})
+	t.Run("Wrapped", func(t *testing.T) {
+		cause := &mgo.QueryError{Code: 11000, Message: "E11000 duplicate key"}
+		err := Errorf(ErrDuplicate, "%w", cause)
+		require.EqualError(t, err, "E11000 duplicate key", "Wrapping doesn't change the message")
+		require.True(t, errors.Is(err, ErrDuplicate))
+		var qe *mgo.QueryError
+		require.True(t, errors.As(err, &qe), "The cause is still reachable")
+		require.Equal(t, 11000, qe.Code)
+	})
+	t.Run("Rewrapped", func(t *testing.T) {
+		err := fmt.Errorf("Handler: %w", Errorf(ErrStoreUnavailable, "Ping timed out. No DB found"))
+		require.True(t, errors.Is(err, ErrStoreUnavailable), "The kind survives wrapping by callers")
+	})
+}
+
+func TestMockMongoSession_ErrorKinds(t *testing.T) {
+	mm := NewMockMongoSession()
+	mm.WriteMode = "duplicate"
+	require.True(t, errors.Is(mm.WriteCollection(context.Background(), "c", nil), ErrDuplicate))
+	mm.WriteMode = "missing"
+	require.True(t, errors.Is(mm.UpdateCollection(context.Background(), "c", nil), ErrNotFound))
+	require.True(t, errors.Is(mm.DeleteFromCollection(context.Background(), "c", "id"), ErrNotFound))
+	mm.WriteMode = "fail"
+	require.True(t, errors.Is(mm.WriteCollection(context.Background(), "c", nil), ErrStoreUnavailable))
+	mm.ConnectMode = "no connect"
+	require.True(t, errors.Is(mm.WriteCollection(context.Background(), "c", nil), ErrStoreUnavailable))
+}
+
+func TestIsDuplicateKey(t *testing.T) {
+	dup := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: duplicateKeyCode, Message: "E11000 duplicate key"}}}
+	require.True(t, isDuplicateKey(dup))
+	require.True(t, isDuplicateKey(fmt.Errorf("insert: %w", dup)), "The code is found through wrapping")
+	other := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "duplicate looking validation failure"}}}
+	require.False(t, isDuplicateKey(other), "Only the code counts")
+	require.False(t, isDuplicateKey(errors.New("duplicate field in document")), "The message alone never counts")
+}
@@ -0,0 +1,417 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+// FileSession is a MongoAbstraction that keeps its collections in a local directory, for running without a mongo
+// but with data that outlives the process. The collections are held in memory and behave exactly as a MemorySession,
+// and every change is first appended to a log file and synced to disk. Opening the directory loads the last
+// snapshot and replays the log over it; once the log has grown long enough it is compacted into a new snapshot.
+// A crash at any point loses at most the change being written when it happened. A change that has begun to be
+// written is finished even if its context is cancelled meanwhile. It is safe for concurrent use, but only one
+// FileSession may have a directory open at a time.
+type FileSession struct {
+	mu           sync.Mutex // serializes changes, so the log and memory agree on their order
+	mem          *MemorySession
+	dir          string
+	log          *os.File
+	logSize      int64
+	logged       int
+	compactEvery int
+	logger       *log.Logger
+}
+
+// File names within the directory, and how many changes are logged before compacting by default
+const (
+	snapshotFileName    string = "collections.snapshot"
+	logFileName         string = "collections.log"
+	DefaultCompactEvery int    = 1000
+)
+
+// Operations in a log record
+const (
+	opPut    string = "put"
+	opDelete string = "delete"
+)
+
+// logRecord is one change to a collection. On disk it is framed by its length and a CRC32 checksum, both
+// little-endian uint32, so a record cut short by a crash can be recognized and dropped
+type logRecord struct {
+	Op   string   `bson:"op"`
+	Coll string   `bson:"coll"`
+	ID   string   `bson:"id"`
+	Doc  bson.Raw `bson:"doc,omitempty"`
+}
+
+// A record never holds more than one document, which mongo caps at 16MB. A longer length is a damaged header
+const (
+	recordHeaderSize = 8
+	maxRecordSize    = 32 << 20
+)
+
+// NewFileSession opens the store in dir, creating the directory if need be, and loads what's there
+// compactEvery overrides how many changes are logged between compactions
+func NewFileSession(dir string, logger *log.Logger, compactEvery ...int) (fs *FileSession, err error) {
+	fs = &FileSession{
+		mem:          NewMemorySession(),
+		dir:          dir,
+		compactEvery: DefaultCompactEvery,
+		logger:       logger,
+	}
+	if fs.logger == nil {
+		fs.logger = log.New(os.Stdout, "FileSession: ", log.Ldate|log.Ltime)
+	}
+	if len(compactEvery) > 0 && compactEvery[0] > 0 {
+		fs.compactEvery = compactEvery[0]
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, Errorf(ErrStoreUnavailable, "FileSession: can't create %s: %w", dir, err)
+	}
+	// The snapshot is only ever replaced whole, so any damage to it is real damage rather than an interrupted write
+	if _, err = readRecords(filepath.Join(dir, snapshotFileName), fs.apply); err != nil {
+		return nil, Errorf(ErrStoreUnavailable, "FileSession: can't load snapshot in %s: %w", dir, err)
+	}
+	logPath := filepath.Join(dir, logFileName)
+	if fs.logSize, err = readRecords(logPath, func(rec logRecord) {
+		fs.apply(rec)
+		fs.logged++
+	}); err != nil && !errors.Is(err, errTornRecord) {
+		return nil, Errorf(ErrStoreUnavailable, "FileSession: can't load log in %s: %w", dir, err)
+	}
+	if fs.log, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return nil, Errorf(ErrStoreUnavailable, "FileSession: can't open log in %s: %w", dir, err)
+	}
+	// A record cut short is one that was never acknowledged, so it goes
+	if err = fs.log.Truncate(fs.logSize); err != nil {
+		fs.log.Close()
+		return nil, Errorf(ErrStoreUnavailable, "FileSession: can't truncate log in %s: %w", dir, err)
+	}
+	fs.logger.Printf("New FileSession opened in %s with %d logged changes", dir, fs.logged)
+	if fs.logged >= fs.compactEvery {
+		if err = fs.compact(); err != nil {
+			fs.log.Close()
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+// ConnectToMongo has nothing to connect to. It only fails once the session is closed, or ctx is done
+func (fs *FileSession) ConnectToMongo(ctx context.Context) error {
+	if err := checkContext(ctx, "ConnectToMongo"); err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.log == nil {
+		return Errorf(ErrStoreUnavailable, "FileSession: %s is closed", fs.dir)
+	}
+	return nil
+}
+
+// Close releases the log file. Everything written is already on disk, so there's nothing to flush
+func (fs *FileSession) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.log == nil {
+		return nil
+	}
+	err := fs.log.Close()
+	fs.log = nil
+	return err
+}
+
+// Compact writes every collection to a new snapshot and empties the log
+func (fs *FileSession) Compact() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.log == nil {
+		return Errorf(ErrStoreUnavailable, "FileSession: %s is closed", fs.dir)
+	}
+	return fs.compact()
+}
+
+// CountInCollection counts the documents in the collection that match the query
+func (fs *FileSession) CountInCollection(ctx context.Context, coll string, query bson.M) (int64, error) {
+	return fs.mem.CountInCollection(ctx, coll, query)
+}
+
+// DeleteFromCollection removes the document with the given _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+func (fs *FileSession) DeleteFromCollection(ctx context.Context, coll string, id string) error {
+	if err := checkContext(ctx, "DeleteFromCollection"); err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if !fs.mem.has(coll, id) {
+		return Errorf(ErrNotFound, "Delete failed: no documents for id=%s in collection %s", id, coll)
+	}
+	return fs.commit(logRecord{Op: opDelete, Coll: coll, ID: id})
+}
+
+// FetchAllFromCollection fetches every document in the collection
+func (fs *FileSession) FetchAllFromCollection(ctx context.Context, coll string) ([][]byte, error) {
+	return fs.mem.FetchAllFromCollection(ctx, coll)
+}
+
+// FetchFromCollection fetches the documents in the collection that match the query, as MemorySession does
+func (fs *FileSession) FetchFromCollection(ctx context.Context, coll string, query bson.M) ([][]byte, error) {
+	return fs.mem.FetchFromCollection(ctx, coll, query)
+}
+
+// FetchIDFromCollection fetches the document with the given _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+func (fs *FileSession) FetchIDFromCollection(ctx context.Context, coll string, id string) ([]byte, error) {
+	return fs.mem.FetchIDFromCollection(ctx, coll, id)
+}
+
+// UpdateCollection replaces the document with the object's _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+// A Versioned object only replaces a document still at its version, and fails with ErrConflict otherwise
+func (fs *FileSession) UpdateCollection(ctx context.Context, coll string, obj Persistable) (err error) {
+	if err = checkContext(ctx, "UpdateCollection"); err != nil {
+		return err
+	}
+	expected, versioned := nextVersion(obj)
+	if versioned {
+		defer func() {
+			if err != nil {
+				obj.(Versioned).SetVersion(expected)
+			}
+		}()
+	}
+	id, doc, err := toDocument(obj)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	stored := fs.mem.get(coll, id)
+	if stored == nil {
+		return Errorf(ErrNotFound, "Update failed. no documents for ID=%s in collection %s", id, coll)
+	}
+	if versioned && storedVersion(stored) != expected {
+		return conflict(coll, id, expected)
+	}
+	return fs.commit(logRecord{Op: opPut, Coll: coll, ID: id, Doc: doc})
+}
+
+// WriteCollection adds the object to the collection. An object whose _id is already there fails with ErrDuplicate
+func (fs *FileSession) WriteCollection(ctx context.Context, coll string, obj Persistable) error {
+	if err := checkContext(ctx, "WriteCollection"); err != nil {
+		return err
+	}
+	id, doc, err := toDocument(obj)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.mem.has(coll, id) {
+		return Errorf(ErrDuplicate, "Write failed: duplicate key on insert for %s", id)
+	}
+	return fs.commit(logRecord{Op: opPut, Coll: coll, ID: id, Doc: doc})
+}
+
+// WriteManyToCollection adds a batch of objects to the collection. Like an unordered insert in mongo, the write
+// carries on past objects that fail, which are returned keyed by their index in objs. The rest are logged together,
+// so the batch costs a single sync
+func (fs *FileSession) WriteManyToCollection(ctx context.Context, coll string, objs []Persistable) (map[int]error, error) {
+	if err := checkContext(ctx, "WriteManyToCollection"); err != nil {
+		return nil, err
+	}
+	failed := make(map[int]error)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	recs := make([]logRecord, 0, len(objs))
+	batch := make(map[string]bool, len(objs))
+	for i, obj := range objs {
+		id, doc, err := toDocument(obj)
+		if err == nil && (batch[id] || fs.mem.has(coll, id)) {
+			err = Errorf(ErrDuplicate, "Write failed: duplicate key on insert for %s", id)
+		}
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		batch[id] = true
+		recs = append(recs, logRecord{Op: opPut, Coll: coll, ID: id, Doc: doc})
+	}
+	if len(recs) == 0 {
+		return failed, nil
+	}
+	return failed, fs.commit(recs...)
+}
+
+// commit logs the changes, then applies them. The caller holds fs.mu
+func (fs *FileSession) commit(recs ...logRecord) error {
+	if fs.log == nil {
+		return Errorf(ErrStoreUnavailable, "FileSession: %s is closed", fs.dir)
+	}
+	var buf bytes.Buffer
+	for _, rec := range recs {
+		if err := writeRecord(&buf, rec); err != nil {
+			return fmt.Errorf("FileSession: can't encode %s: %w", rec.ID, err)
+		}
+	}
+	if _, err := fs.log.Write(buf.Bytes()); err != nil {
+		return fs.rollback(err)
+	}
+	if err := fs.log.Sync(); err != nil {
+		return fs.rollback(err)
+	}
+	fs.logSize += int64(buf.Len())
+	fs.logged += len(recs)
+	for _, rec := range recs {
+		fs.apply(rec)
+	}
+	if fs.logged >= fs.compactEvery {
+		// The changes are safe in the log either way, so a failed compaction only means trying again next time
+		if err := fs.compact(); err != nil {
+			fs.logger.Printf("Compaction failed: %s", err)
+		}
+	}
+	return nil
+}
+
+// rollback trims whatever part of a failed append made it to the log, so later records aren't stranded behind it
+func (fs *FileSession) rollback(cause error) error {
+	if err := fs.log.Truncate(fs.logSize); err != nil {
+		fs.logger.Printf("Can't trim log after failed write: %s", err)
+	}
+	return Errorf(ErrStoreUnavailable, "FileSession: write to log failed: %w", cause)
+}
+
+// apply makes a logged change in memory. Replaying a change that's already there does nothing, which is what makes
+// a crash between writing a snapshot and emptying the log harmless
+func (fs *FileSession) apply(rec logRecord) {
+	switch rec.Op {
+	case opPut:
+		fs.mem.put(rec.Coll, rec.ID, rec.Doc)
+	case opDelete:
+		fs.mem.remove(rec.Coll, rec.ID)
+	}
+}
+
+// compact writes the snapshot to a temporary file and renames it into place, so there is always one whole snapshot
+// on disk, then empties the log. The caller holds fs.mu
+func (fs *FileSession) compact() error {
+	tmpPath := filepath.Join(fs.dir, snapshotFileName+".tmp")
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return Errorf(ErrStoreUnavailable, "FileSession: can't create snapshot: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	err = fs.mem.each(func(coll string, id string, doc bson.Raw) error {
+		return writeRecord(w, logRecord{Op: opPut, Coll: coll, ID: id, Doc: doc})
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, filepath.Join(fs.dir, snapshotFileName))
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return Errorf(ErrStoreUnavailable, "FileSession: can't write snapshot: %w", err)
+	}
+	syncDir(fs.dir)
+
+	if err = fs.log.Truncate(0); err == nil {
+		err = fs.log.Sync()
+	}
+	if err != nil {
+		return Errorf(ErrStoreUnavailable, "FileSession: can't empty log: %w", err)
+	}
+	fs.logger.Printf("Compacted %d logged changes in %s", fs.logged, fs.dir)
+	fs.logSize = 0
+	fs.logged = 0
+	return nil
+}
+
+// syncDir makes a rename within the directory durable. Not every platform can sync a directory, and the rename
+// itself has already happened, so failures are ignored
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// errTornRecord marks the end of a file where a record is incomplete or fails its checksum
+var errTornRecord = errors.New("torn record")
+
+// writeRecord frames and writes a single record
+func writeRecord(w io.Writer, rec logRecord) error {
+	payload, err := bson.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	if _, err = w.Write(header[:]); err == nil {
+		_, err = w.Write(payload)
+	}
+	return err
+}
+
+// readRecords passes each record in the file to fn, and returns the offset just past the last whole one. A missing
+// file holds no records. Reading stops with errTornRecord at a record that is cut short or fails its checksum
+func readRecords(path string, fn func(logRecord)) (int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var offset int64
+	var header [recordHeaderSize]byte
+	for {
+		if _, err = io.ReadFull(r, header[:]); err == io.EOF {
+			return offset, nil
+		} else if err != nil {
+			return offset, errTornRecord
+		}
+		size := binary.LittleEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return offset, errTornRecord
+		}
+		payload := make([]byte, size)
+		if _, err = io.ReadFull(r, payload); err != nil {
+			return offset, errTornRecord
+		}
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+			return offset, errTornRecord
+		}
+		var rec logRecord
+		if err = bson.Unmarshal(payload, &rec); err != nil {
+			return offset, fmt.Errorf("record at offset %d: %w", offset, err)
+		}
+		fn(rec)
+		offset += int64(recordHeaderSize) + int64(size)
+	}
+}
@@ -0,0 +1,160 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFileSession_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	fs := openFileSession(t, dir)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: fmt.Sprintf("id%d", i), ANumber: i}))
+	}
+	require.NoError(t, fs.UpdateCollection(context.Background(), TestCollection, GenericPersistable{ID: "id1", Name: "updated"}))
+	require.NoError(t, fs.DeleteFromCollection(context.Background(), TestCollection, "id3"))
+	failed, err := fs.WriteManyToCollection(context.Background(), "OtherCollection", []Persistable{GenericPersistable{ID: "x"}, GenericPersistable{ID: "y"}})
+	require.NoError(t, err)
+	require.Empty(t, failed)
+	require.NoError(t, fs.Close())
+
+	fs = openFileSession(t, dir)
+	requireIDs(t, fs, TestCollection, "id0", "id1", "id2", "id4")
+	requireIDs(t, fs, "OtherCollection", "x", "y")
+	raw, err := fs.FetchIDFromCollection(context.Background(), TestCollection, "id1")
+	require.NoError(t, err)
+	var got GenericPersistable
+	require.NoError(t, got.Decode(raw))
+	require.Equal(t, "updated", got.Name)
+}
+
+func TestFileSession_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	fs := openFileSession(t, dir, 4)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: fmt.Sprintf("id%d", i)}))
+	}
+	require.Equal(t, 2, fs.logged, "The log was compacted at 4 and 8 changes")
+	require.FileExists(t, filepath.Join(dir, snapshotFileName))
+	require.NoFileExists(t, filepath.Join(dir, snapshotFileName+".tmp"))
+
+	require.NoError(t, fs.DeleteFromCollection(context.Background(), TestCollection, "id0"))
+	require.NoError(t, fs.Compact())
+	info, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	require.Zero(t, info.Size(), "Compacting empties the log")
+	require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "id0"}))
+	require.NoError(t, fs.Close())
+
+	fs = openFileSession(t, dir, 4)
+	requireIDs(t, fs, TestCollection, "id1", "id2", "id3", "id4", "id5", "id6", "id7", "id8", "id9", "id0")
+}
+
+func TestFileSession_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	fs := openFileSession(t, dir)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: id}))
+	}
+	require.NoError(t, fs.Close())
+	logPath := filepath.Join(dir, logFileName)
+	whole, err := ioutil.ReadFile(logPath)
+	require.NoError(t, err)
+
+	t.Run("Cut short", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(logPath, whole[:len(whole)-5], 0644))
+		fs := openFileSession(t, dir)
+		requireIDs(t, fs, TestCollection, "a", "b")
+		require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "d"}), "Writes carry on after the last whole record")
+		require.NoError(t, fs.Close())
+		requireIDs(t, openFileSession(t, dir), TestCollection, "a", "b", "d")
+	})
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		damaged := append([]byte(nil), whole...)
+		damaged[len(damaged)-2] ^= 0xff
+		require.NoError(t, ioutil.WriteFile(logPath, damaged, 0644))
+		requireIDs(t, openFileSession(t, dir), TestCollection, "a", "b")
+	})
+	t.Run("Header only", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(logPath, append(append([]byte(nil), whole...), 0xff, 0xff, 0xff, 0xff), 0644))
+		requireIDs(t, openFileSession(t, dir), TestCollection, "a", "b", "c")
+	})
+}
+
+// TestFileSession_CrashDuringCompaction leaves the log behind as though the process died after the new snapshot
+// was in place but before the log was emptied
+func TestFileSession_CrashDuringCompaction(t *testing.T) {
+	dir := t.TempDir()
+	fs := openFileSession(t, dir)
+	require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "a"}))
+	require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "b"}))
+	require.NoError(t, fs.UpdateCollection(context.Background(), TestCollection, GenericPersistable{ID: "a", Name: "updated"}))
+	require.NoError(t, fs.DeleteFromCollection(context.Background(), TestCollection, "b"))
+	logPath := filepath.Join(dir, logFileName)
+	stale, err := ioutil.ReadFile(logPath)
+	require.NoError(t, err)
+	require.NoError(t, fs.Compact())
+	require.NoError(t, fs.Close())
+	require.NoError(t, ioutil.WriteFile(logPath, stale, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, snapshotFileName+".tmp"), []byte("half a snapshot"), 0644))
+
+	fs = openFileSession(t, dir)
+	requireIDs(t, fs, TestCollection, "a")
+	raw, err := fs.FetchIDFromCollection(context.Background(), TestCollection, "a")
+	require.NoError(t, err)
+	var got GenericPersistable
+	require.NoError(t, got.Decode(raw))
+	require.Equal(t, "updated", got.Name)
+}
+
+func TestFileSession_Failures(t *testing.T) {
+	t.Run("Closed", func(t *testing.T) {
+		fs := openFileSession(t, t.TempDir())
+		require.NoError(t, fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "a"}))
+		require.NoError(t, fs.Close())
+		require.True(t, errors.Is(fs.ConnectToMongo(context.Background()), ErrStoreUnavailable))
+		require.True(t, errors.Is(fs.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "b"}), ErrStoreUnavailable))
+		require.True(t, errors.Is(fs.Compact(), ErrStoreUnavailable))
+		require.NoError(t, fs.Close(), "Closing twice is harmless")
+	})
+	t.Run("Damaged snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, snapshotFileName), []byte("not a snapshot"), 0644))
+		_, err := NewFileSession(dir, discardLogger())
+		require.True(t, errors.Is(err, ErrStoreUnavailable), "Got %v", err)
+	})
+	t.Run("Not a directory", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, ioutil.WriteFile(file, nil, 0644))
+		_, err := NewFileSession(file, discardLogger())
+		require.True(t, errors.Is(err, ErrStoreUnavailable), "Got %v", err)
+	})
+}
+
+/*** Helper functions ***/
+
+func discardLogger() *log.Logger {
+	return log.New(ioutil.Discard, "filesession_test: ", 0)
+}
+
+// requireIDs checks that the collection holds exactly these IDs, in this order
+func requireIDs(t *testing.T, m MongoAbstraction, coll string, ids ...string) {
+	raws, err := m.FetchFromCollection(context.Background(), coll, bson.M{})
+	require.NoError(t, err)
+	got := make([]string, len(raws))
+	for i, raw := range raws {
+		var obj GenericPersistable
+		require.NoError(t, obj.Decode(raw))
+		got[i] = obj.ID
+	}
+	require.Equal(t, ids, got)
+}
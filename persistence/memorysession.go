@@ -0,0 +1,328 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mongo "go.mongodb.org/mongo-driver/mongo"
+	bson "go.mongodb.org/mongo-driver/bson"
+	bsontype "go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// MemorySession is a MongoAbstraction that keeps every collection in memory. Objects are stored as the same BSON
+// documents mongo would hold, and fail the same ways: a second object with an _id already in the collection is an
+// ErrDuplicate, updates or deletes of a missing _id are ErrNotFound, and updates of a Versioned object that has moved on
+// are ErrConflict. Queries match on equality of every field
+// in the query, with dotted names reaching into subdocuments.
+// Nothing survives the process, which makes it a fit for local development and tests that don't have a mongo to
+// talk to. It is safe for concurrent use.
+type MemorySession struct {
+	mu          sync.RWMutex
+	collections map[string]*memoryCollection
+}
+
+// memoryCollection holds documents by _id, and remembers the order they were written in so fetches come back in
+// the natural order mongo would give them
+type memoryCollection struct {
+	docs  map[string]bson.Raw
+	order []string
+}
+
+// NewMemorySession creates an empty in-memory store
+func NewMemorySession() *MemorySession {
+	return &MemorySession{collections: make(map[string]*memoryCollection)}
+}
+
+// ConnectToMongo has nothing to connect to, so succeeds unless ctx is already done
+func (ms *MemorySession) ConnectToMongo(ctx context.Context) error {
+	return checkContext(ctx, "ConnectToMongo")
+}
+
+// CountInCollection counts the documents in the collection that match the query
+func (ms *MemorySession) CountInCollection(ctx context.Context, coll string, query bson.M) (int64, error) {
+	matches, err := ms.FetchFromCollection(ctx, coll, query)
+	return int64(len(matches)), err
+}
+
+// DeleteFromCollection removes the document with the given _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+func (ms *MemorySession) DeleteFromCollection(ctx context.Context, coll string, id string) error {
+	if err := checkContext(ctx, "DeleteFromCollection"); err != nil {
+		return err
+	}
+	if !ms.remove(coll, id) {
+		return Errorf(ErrNotFound, "Delete failed: no documents for id=%s in collection %s", id, coll)
+	}
+	return nil
+}
+
+// FetchAllFromCollection fetches every document in the collection
+func (ms *MemorySession) FetchAllFromCollection(ctx context.Context, coll string) ([][]byte, error) {
+	return ms.FetchFromCollection(ctx, coll, bson.M{})
+}
+
+// FetchFromCollection fetches the documents in the collection that match the query. Only equality is supported;
+// a query operator such as $in is an error
+func (ms *MemorySession) FetchFromCollection(ctx context.Context, coll string, query bson.M) ([][]byte, error) {
+	if err := checkContext(ctx, "FetchFromCollection"); err != nil {
+		return nil, err
+	}
+	want := make(map[string]bson.RawValue, len(query))
+	for field, value := range query {
+		if strings.HasPrefix(field, "$") {
+			return nil, fmt.Errorf("MemorySession: query operator %s is not supported", field)
+		}
+		rv, err := toRawValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("MemorySession: query on %s: %w", field, err)
+		}
+		if rv.Type == bsontype.EmbeddedDocument {
+			if elems, _ := rv.Document().Elements(); len(elems) > 0 && strings.HasPrefix(elems[0].Key(), "$") {
+				return nil, fmt.Errorf("MemorySession: query operator %s is not supported", elems[0].Key())
+			}
+		}
+		want[field] = rv
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	results := make([][]byte, 0)
+	c := ms.collections[coll]
+	if c == nil {
+		return results, nil
+	}
+	for _, id := range c.order {
+		doc := c.docs[id]
+		if matches(doc, want) {
+			results = append(results, copyBytes(doc))
+		}
+	}
+	return results, nil
+}
+
+// FetchIDFromCollection fetches the document with the given _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+func (ms *MemorySession) FetchIDFromCollection(ctx context.Context, coll string, id string) ([]byte, error) {
+	if err := checkContext(ctx, "FetchIDFromCollection"); err != nil {
+		return nil, err
+	}
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if c := ms.collections[coll]; c != nil && c.docs[id] != nil {
+		return copyBytes(c.docs[id]), nil
+	}
+	return nil, Errorf(ErrNotFound, "%w", mongo.ErrNoDocuments)
+}
+
+// UpdateCollection replaces the document with the object's _id
+// If the ID is not found, returns an ErrNotFound error containing the message "no documents"
+// A Versioned object only replaces a document still at its version, and fails with ErrConflict otherwise
+func (ms *MemorySession) UpdateCollection(ctx context.Context, coll string, obj Persistable) (err error) {
+	if err = checkContext(ctx, "UpdateCollection"); err != nil {
+		return err
+	}
+	expected, versioned := nextVersion(obj)
+	if versioned {
+		defer func() {
+			if err != nil {
+				obj.(Versioned).SetVersion(expected)
+			}
+		}()
+	}
+	id, doc, err := toDocument(obj)
+	if err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	c := ms.collections[coll]
+	if c == nil || c.docs[id] == nil {
+		return Errorf(ErrNotFound, "Update failed. no documents for ID=%s in collection %s", id, coll)
+	}
+	if versioned && storedVersion(c.docs[id]) != expected {
+		return conflict(coll, id, expected)
+	}
+	c.docs[id] = doc
+	return nil
+}
+
+// WriteCollection adds the object to the collection. An object whose _id is already there fails with ErrDuplicate
+func (ms *MemorySession) WriteCollection(ctx context.Context, coll string, obj Persistable) error {
+	if err := checkContext(ctx, "WriteCollection"); err != nil {
+		return err
+	}
+	id, doc, err := toDocument(obj)
+	if err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.insert(coll, id, doc)
+}
+
+// WriteManyToCollection adds a batch of objects to the collection. Like an unordered insert in mongo, the write
+// carries on past objects that fail, which are returned keyed by their index in objs
+func (ms *MemorySession) WriteManyToCollection(ctx context.Context, coll string, objs []Persistable) (map[int]error, error) {
+	if err := checkContext(ctx, "WriteManyToCollection"); err != nil {
+		return nil, err
+	}
+	failed := make(map[int]error)
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for i, obj := range objs {
+		id, doc, err := toDocument(obj)
+		if err == nil {
+			err = ms.insert(coll, id, doc)
+		}
+		if err != nil {
+			failed[i] = err
+		}
+	}
+	return failed, nil
+}
+
+// put stores a document whether or not its _id is already there. A replaced document keeps its place in the order
+func (ms *MemorySession) put(coll string, id string, doc bson.Raw) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if c := ms.collections[coll]; c != nil && c.docs[id] != nil {
+		c.docs[id] = doc
+		return
+	}
+	ms.insert(coll, id, doc)
+}
+
+// remove drops a document, if it's there, and tells whether it was. Checking and dropping under the one lock means
+// only one of two concurrent removes of a document finds it
+func (ms *MemorySession) remove(coll string, id string) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	c := ms.collections[coll]
+	if c == nil || c.docs[id] == nil {
+		return false
+	}
+	delete(c.docs, id)
+	for i, existing := range c.order {
+		if existing == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// get finds the document with the given _id, or nil if the collection doesn't hold one
+func (ms *MemorySession) get(coll string, id string) bson.Raw {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if c := ms.collections[coll]; c != nil {
+		return c.docs[id]
+	}
+	return nil
+}
+
+// has checks whether a collection holds a document with the given _id
+func (ms *MemorySession) has(coll string, id string) bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	c := ms.collections[coll]
+	return c != nil && c.docs[id] != nil
+}
+
+// each visits every document, collection by collection, in the order they were written. It stops at the first error
+func (ms *MemorySession) each(fn func(coll string, id string, doc bson.Raw) error) error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	names := make([]string, 0, len(ms.collections))
+	for name := range ms.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := ms.collections[name]
+		for _, id := range c.order {
+			if err := fn(name, id, c.docs[id]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insert adds a document unless its _id is taken. The caller holds ms.mu
+func (ms *MemorySession) insert(coll string, id string, doc bson.Raw) error {
+	c := ms.collections[coll]
+	if c == nil {
+		c = &memoryCollection{docs: make(map[string]bson.Raw)}
+		ms.collections[coll] = c
+	}
+	if c.docs[id] != nil {
+		return Errorf(ErrDuplicate, "Write failed: duplicate key on insert for %s", id)
+	}
+	c.docs[id] = doc
+	c.order = append(c.order, id)
+	return nil
+}
+
+// toDocument encodes an object the way the mongo driver would, and finds its _id. Objects without an _id field are
+// keyed by GetID
+func toDocument(obj Persistable) (id string, doc bson.Raw, err error) {
+	if doc, err = bson.Marshal(obj); err != nil {
+		return "", nil, fmt.Errorf("MemorySession: can't encode %s: %w", obj.GetID(), err)
+	}
+	id = obj.GetID()
+	if rv, lookupErr := doc.LookupErr("_id"); lookupErr == nil {
+		if s, ok := rv.StringValueOK(); ok {
+			id = s
+		}
+	}
+	return id, doc, nil
+}
+
+// toRawValue encodes a query value the way it would be stored
+func toRawValue(value interface{}) (bson.RawValue, error) {
+	raw, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	return bson.Raw(raw).Lookup("v"), nil
+}
+
+// matches checks a document against every field of a query. Numbers match across int32, int64 and double, as
+// they do in mongo
+func matches(doc bson.Raw, want map[string]bson.RawValue) bool {
+	for field, value := range want {
+		got, err := doc.LookupErr(strings.Split(field, ".")...)
+		if err != nil {
+			return false
+		}
+		if got.Equal(value) {
+			continue
+		}
+		gotNum, gotOK := number(got)
+		wantNum, wantOK := number(value)
+		if !gotOK || !wantOK || gotNum != wantNum {
+			return false
+		}
+	}
+	return true
+}
+
+func number(rv bson.RawValue) (float64, bool) {
+	switch rv.Type {
+	case bsontype.Int32:
+		return float64(rv.Int32()), true
+	case bsontype.Int64:
+		return float64(rv.Int64()), true
+	case bsontype.Double:
+		return rv.Double(), true
+	}
+	return 0, false
+}
+
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
@@ -0,0 +1,176 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMemorySession_WriteAndFetch(t *testing.T) {
+	var ms MongoAbstraction = NewMemorySession()
+	require.NoError(t, ms.ConnectToMongo(context.Background()))
+	now := time.Now().Round(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		obj := GenericPersistable{ID: fmt.Sprintf("id%d", i), TimeCreated: now, Name: "Bob", ANumber: i % 2}
+		require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, obj))
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		err := ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "id0"})
+		require.True(t, errors.Is(err, ErrDuplicate))
+		require.Contains(t, err.Error(), "duplicate key on insert for id0")
+		err = ms.WriteCollection(context.Background(), "OtherCollection", GenericPersistable{ID: "id0"})
+		require.NoError(t, err, "IDs only need to be unique within a collection")
+	})
+	t.Run("FetchID", func(t *testing.T) {
+		raw, err := ms.FetchIDFromCollection(context.Background(), TestCollection, "id3")
+		require.NoError(t, err)
+		var got GenericPersistable
+		require.NoError(t, got.Decode(raw))
+		require.Equal(t, GenericPersistable{ID: "id3", TimeCreated: now.UTC(), Name: "Bob", ANumber: 1}, got)
+		_, err = ms.FetchIDFromCollection(context.Background(), TestCollection, "nope")
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.Contains(t, err.Error(), "no documents")
+	})
+	t.Run("FetchAll keeps write order", func(t *testing.T) {
+		raws, err := ms.FetchAllFromCollection(context.Background(), TestCollection)
+		require.NoError(t, err)
+		require.Len(t, raws, 5)
+		for i, raw := range raws {
+			var got GenericPersistable
+			require.NoError(t, got.Decode(raw))
+			require.Equal(t, fmt.Sprintf("id%d", i), got.ID)
+		}
+		raws, err = ms.FetchAllFromCollection(context.Background(), "NoSuchCollection")
+		require.NoError(t, err)
+		require.Empty(t, raws)
+	})
+	t.Run("Query", func(t *testing.T) {
+		raws, err := ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"anumber": 1})
+		require.NoError(t, err)
+		require.Len(t, raws, 2)
+		raws, err = ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"anumber": int64(0), "name": "Bob"})
+		require.NoError(t, err)
+		require.Len(t, raws, 3, "Numbers match whatever their width")
+		raws, err = ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"anumber": 0, "name": "Alice"})
+		require.NoError(t, err)
+		require.Empty(t, raws, "Every field has to match")
+		raws, err = ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"nosuchfield": "Bob"})
+		require.NoError(t, err)
+		require.Empty(t, raws)
+		count, err := ms.CountInCollection(context.Background(), TestCollection, bson.M{"name": "Bob"})
+		require.NoError(t, err)
+		require.Equal(t, int64(5), count)
+	})
+	t.Run("Query operators", func(t *testing.T) {
+		_, err := ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"anumber": bson.M{"$gt": 0}})
+		require.Error(t, err)
+		_, err = ms.FetchFromCollection(context.Background(), TestCollection, bson.M{"$or": bson.A{}})
+		require.Error(t, err)
+	})
+	t.Run("Fetched bytes are copies", func(t *testing.T) {
+		raw, err := ms.FetchIDFromCollection(context.Background(), TestCollection, "id1")
+		require.NoError(t, err)
+		for i := range raw {
+			raw[i] = 0
+		}
+		raw, err = ms.FetchIDFromCollection(context.Background(), TestCollection, "id1")
+		require.NoError(t, err)
+		var got GenericPersistable
+		require.NoError(t, got.Decode(raw))
+		require.Equal(t, "id1", got.ID)
+	})
+}
+
+func TestMemorySession_UpdateAndDelete(t *testing.T) {
+	ms := NewMemorySession()
+	require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "a", Name: "before"}))
+	require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "b", Name: "other"}))
+
+	require.NoError(t, ms.UpdateCollection(context.Background(), TestCollection, GenericPersistable{ID: "a", Name: "after"}))
+	raw, err := ms.FetchIDFromCollection(context.Background(), TestCollection, "a")
+	require.NoError(t, err)
+	var got GenericPersistable
+	require.NoError(t, got.Decode(raw))
+	require.Equal(t, "after", got.Name)
+
+	err = ms.UpdateCollection(context.Background(), TestCollection, GenericPersistable{ID: "nope"})
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Contains(t, err.Error(), "no documents")
+
+	require.NoError(t, ms.DeleteFromCollection(context.Background(), TestCollection, "a"))
+	_, err = ms.FetchIDFromCollection(context.Background(), TestCollection, "a")
+	require.True(t, errors.Is(err, ErrNotFound))
+	err = ms.DeleteFromCollection(context.Background(), TestCollection, "a")
+	require.True(t, errors.Is(err, ErrNotFound), "Can't delete twice")
+
+	require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "a", Name: "again"}), "A deleted ID can be reused")
+	raws, err := ms.FetchAllFromCollection(context.Background(), TestCollection)
+	require.NoError(t, err)
+	require.Len(t, raws, 2)
+}
+
+func TestMemorySession_WriteMany(t *testing.T) {
+	ms := NewMemorySession()
+	require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: "b"}))
+	failed, err := ms.WriteManyToCollection(context.Background(), TestCollection, []Persistable{
+		GenericPersistable{ID: "a"}, GenericPersistable{ID: "b"}, GenericPersistable{ID: "c"}, GenericPersistable{ID: "a"},
+	})
+	require.NoError(t, err)
+	require.Len(t, failed, 2)
+	require.True(t, errors.Is(failed[1], ErrDuplicate))
+	require.True(t, errors.Is(failed[3], ErrDuplicate), "Duplicates within the batch are caught too")
+	count, err := ms.CountInCollection(context.Background(), TestCollection, bson.M{})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
+
+func TestMemorySession_Concurrent(t *testing.T) {
+	ms := NewMemorySession()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("id%d", i%10)
+			ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: id})
+			ms.UpdateCollection(context.Background(), TestCollection, GenericPersistable{ID: id, ANumber: i})
+			ms.FetchAllFromCollection(context.Background(), TestCollection)
+		}(i)
+	}
+	wg.Wait()
+	count, err := ms.CountInCollection(context.Background(), TestCollection, bson.M{})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), count, "Only one write of each ID wins")
+}
+
+func TestMemorySession_ConcurrentDelete(t *testing.T) {
+	ms := NewMemorySession()
+	for i := 0; i < 100; i++ {
+		require.NoError(t, ms.WriteCollection(context.Background(), TestCollection, GenericPersistable{ID: fmt.Sprintf("id%d", i)}))
+	}
+	var wg sync.WaitGroup
+	var deleted, missing int32
+	for i := 0; i < 800; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := ms.DeleteFromCollection(context.Background(), TestCollection, fmt.Sprintf("id%d", i%100))
+			if err == nil {
+				atomic.AddInt32(&deleted, 1)
+			} else if errors.Is(err, ErrNotFound) {
+				atomic.AddInt32(&missing, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int32(100), deleted, "Only one delete of each ID finds it")
+	require.Equal(t, int32(700), missing)
+}
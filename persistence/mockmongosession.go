@@ -1,13 +1,15 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	bson "go.mongodb.org/mongo-driver/bson"
 	mgo "gopkg.in/mgo.v2"
 )
 
-// MockMongoSession provides a mock abstraction to mongo
+// MockMongoSession provides a mock abstraction to mongo. Its failures carry the same error kinds as MongoSession's
 type MockMongoSession struct {
 	MongoAbstraction
 	ConnectMode  string
@@ -15,6 +17,16 @@ type MockMongoSession struct {
 	WriteMode    string
 	FetchResult  Persistable
 	FetchResults []Persistable
+	// CollectionResults overrides FetchResults for the named collections
+	CollectionResults map[string][]Persistable
+	// DuplicateIDs narrows the 'duplicate' WriteMode to these IDs for batch writes
+	DuplicateIDs map[string]bool
+	// Written and Updated record collection+"/"+id for each successful WriteCollection and UpdateCollection, in
+	// call order
+	Written []string
+	Updated []string
+	// record guards Written and Updated, so the mock can back concurrent tests
+	record sync.Mutex
 }
 
 // NewMockMongoSession provides a mock with default 'positive' behaviors
@@ -28,60 +40,97 @@ func NewMockMongoSession() *MockMongoSession {
 
 //** Mock interface functions **//
 
-// ConnectToMongo mock. Controlled by mm.ConnectMode values 'positive' and 'no connect'
-func (mm *MockMongoSession) ConnectToMongo() error {
+// ConnectToMongo mock. Controlled by mm.ConnectMode values 'positive' and 'no connect'. Every mock call goes through
+// here first, so each fails as the real thing would once ctx is done
+func (mm *MockMongoSession) ConnectToMongo(ctx context.Context) error {
+	if err := checkContext(ctx, "Mock call"); err != nil {
+		return err
+	}
 	switch {
 	case mm.ConnectMode == "positive":
 		return nil
 	case mm.ConnectMode == "no connect":
-		return fmt.Errorf("mocked connection failure: no reachable servers")
+		return Errorf(ErrStoreUnavailable, "mocked connection failure: no reachable servers")
 	}
 	return fmt.Errorf("Unknown mode for ConnectToMongo: %s", mm.ConnectMode)
 }
 
 // WriteCollection mock. Controlled by mm.WriteMode values 'positive', 'fail' and 'duplicate'
-func (mm *MockMongoSession) WriteCollection(collectionName string, object Persistable) error {
-	if err := mm.ConnectToMongo(); err != nil {
+func (mm *MockMongoSession) WriteCollection(ctx context.Context, collectionName string, object Persistable) error {
+	if err := mm.ConnectToMongo(ctx); err != nil {
 		return err
 	}
 	switch {
 	case mm.WriteMode == "positive":
+		mm.record.Lock()
+		mm.Written = append(mm.Written, collectionName+"/"+object.GetID())
+		mm.record.Unlock()
 		return nil
 	case mm.WriteMode == "fail":
-		return fmt.Errorf("Mock error on write")
+		return Errorf(ErrStoreUnavailable, "Mock error on write")
 	case mm.WriteMode == "duplicate":
 		err := mgo.QueryError{
 			Code:    11000,
 			Message: "Mock duplicate on write",
 		}
-		return &err
+		return Errorf(ErrDuplicate, "%w", &err)
 	}
 	return fmt.Errorf("Unknown mode for WriteCollection: %s", mm.WriteMode)
 }
 
-// UpdateCollection mock. Controlled by mm.WriteMode values 'positive', 'fail' and 'missing'
-func (mm *MockMongoSession) UpdateCollection(collectionName string, object Persistable) error {
-	if err := mm.ConnectToMongo(); err != nil {
+// WriteManyToCollection mock. Controlled by mm.WriteMode values 'positive', 'fail' and 'duplicate'. A duplicate
+// fails only the objects listed in DuplicateIDs, or all of them when that is empty
+func (mm *MockMongoSession) WriteManyToCollection(ctx context.Context, collectionName string, objects []Persistable) (map[int]error, error) {
+	if err := mm.ConnectToMongo(ctx); err != nil {
+		return nil, err
+	}
+	failed := make(map[int]error)
+	switch {
+	case mm.WriteMode == "positive":
+		return failed, nil
+	case mm.WriteMode == "fail":
+		return nil, Errorf(ErrStoreUnavailable, "Mock error on write")
+	case mm.WriteMode == "duplicate":
+		for i, obj := range objects {
+			if len(mm.DuplicateIDs) == 0 || mm.DuplicateIDs[obj.GetID()] {
+				failed[i] = Errorf(ErrDuplicate, "Mock duplicate on write for %s", obj.GetID())
+			}
+		}
+		return failed, nil
+	}
+	return nil, fmt.Errorf("Unknown mode for WriteManyToCollection: %s", mm.WriteMode)
+}
+
+// UpdateCollection mock. Controlled by mm.WriteMode values 'positive', 'fail', 'missing' and 'conflict'. Versioned
+// objects move on to their next version when the update is positive
+func (mm *MockMongoSession) UpdateCollection(ctx context.Context, collectionName string, object Persistable) error {
+	if err := mm.ConnectToMongo(ctx); err != nil {
 		return err
 	}
 	switch {
 	case mm.WriteMode == "positive":
+		nextVersion(object)
+		mm.record.Lock()
+		mm.Updated = append(mm.Updated, collectionName+"/"+object.GetID())
+		mm.record.Unlock()
 		return nil
 	case mm.WriteMode == "fail":
-		return fmt.Errorf("Mock error on update")
+		return Errorf(ErrStoreUnavailable, "Mock error on update")
 	case mm.WriteMode == "missing":
 		err := mgo.QueryError{
 			Code:    11000, // TODO: find the right error Code and type
 			Message: "Mock not found on update",
 		}
-		return &err
+		return Errorf(ErrNotFound, "%w", &err)
+	case mm.WriteMode == "conflict":
+		return Errorf(ErrConflict, "Mock conflict on update")
 	}
 	return fmt.Errorf("Unknown mode for UpdateCollection: %s", mm.WriteMode)
 }
 
 // FetchIDFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'
-func (mm *MockMongoSession) FetchIDFromCollection(collectionName string, id string) (result []byte, err error) {
-	if err := mm.ConnectToMongo(); err != nil {
+func (mm *MockMongoSession) FetchIDFromCollection(ctx context.Context, collectionName string, id string) (result []byte, err error) {
+	if err := mm.ConnectToMongo(ctx); err != nil {
 		return nil, err
 	}
 	switch {
@@ -89,53 +138,57 @@ func (mm *MockMongoSession) FetchIDFromCollection(collectionName string, id stri
 		result, err = bson.Marshal(mm.FetchResult)
 		return
 	case mm.QueryMode == "fail":
-		return nil, fmt.Errorf("Mock error on get")
+		return nil, Errorf(ErrStoreUnavailable, "Mock error on get")
 	}
 	return nil, fmt.Errorf("Unknown mode for FetchFromCollection: %s", mm.QueryMode)
 }
 
-// FetchFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'
-func (mm *MockMongoSession) FetchFromCollection(collectionName string, query bson.M) (results [][]byte, err error) {
-	//TODO: implement FetchFromCollection
-	panic("ack!")
-}
-
-// FetchAllFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'
-func (mm *MockMongoSession) FetchAllFromCollection(collectionName string) (results [][]byte, err error) {
-	if err := mm.ConnectToMongo(); err != nil {
+// FetchFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'. The query is not applied;
+// results come from CollectionResults for the collection when set, otherwise from FetchResults
+func (mm *MockMongoSession) FetchFromCollection(ctx context.Context, collectionName string, query bson.M) (results [][]byte, err error) {
+	if err := mm.ConnectToMongo(ctx); err != nil {
 		return nil, err
 	}
 	switch {
 	case mm.QueryMode == "positive":
-		results = make([][]byte,len(mm.FetchResults))
-		for i :=0; i < len(mm.FetchResults); i++ {
-			if results[i], err = bson.Marshal(mm.FetchResults[i]); err != nil {
+		source := mm.FetchResults
+		if fromCollection, exists := mm.CollectionResults[collectionName]; exists {
+			source = fromCollection
+		}
+		results = make([][]byte,len(source))
+		for i :=0; i < len(source); i++ {
+			if results[i], err = bson.Marshal(source[i]); err != nil {
 				return nil, err
 			}
 		}
 		return
 	case mm.QueryMode == "fail":
-		return nil, fmt.Errorf("Mock error on get")
+		return nil, Errorf(ErrStoreUnavailable, "Mock error on get")
 	}
-	return nil, fmt.Errorf("Unknown mode for FetchAllFromCollection: %s", mm.QueryMode)
+	return nil, fmt.Errorf("Unknown mode for FetchFromCollection: %s", mm.QueryMode)
+}
+
+// FetchAllFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'
+func (mm *MockMongoSession) FetchAllFromCollection(ctx context.Context, collectionName string) (results [][]byte, err error) {
+	return mm.FetchFromCollection(ctx, collectionName, bson.M{})
 }
 
 // DeleteFromCollection mock. Controlled by mm.QueryMode values 'positive' and 'fail'
-func (mm *MockMongoSession) DeleteFromCollection(collectionName string, id string) error {
-	if err := mm.ConnectToMongo(); err != nil {
+func (mm *MockMongoSession) DeleteFromCollection(ctx context.Context, collectionName string, id string) error {
+	if err := mm.ConnectToMongo(ctx); err != nil {
 		return err
 	}
 	switch {
 	case mm.WriteMode == "positive":
 		return nil
 	case mm.WriteMode == "fail":
-		return fmt.Errorf("Mock error on delete")
+		return Errorf(ErrStoreUnavailable, "Mock error on delete")
 	case mm.WriteMode == "missing":
 		err := mgo.QueryError{
 			Code:    11000, // TODO: find the right error Code and type
 			Message: "Mock not found on delete",
 		}
-		return &err
+		return Errorf(ErrNotFound, "%w", &err)
 	}
 	return fmt.Errorf("Unknown mode for DeleteFromCollection: %s", mm.QueryMode)
 }
@@ -1,10 +1,12 @@
 package persistence
 
 import (
+	"errors"
 	"strings"
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 	"context"
 
@@ -20,20 +22,39 @@ type Persistable interface {
 //	Decode([]byte) error
 }
 
+// Versioned is a Persistable that guards against lost updates. The version counts the updates made to the object,
+// and is stored in its VersionField. Updates only replace a document still at the version the object was read at,
+// and move both on to the next version. When someone else got there first, the update fails with ErrConflict and
+// nothing is written. Documents stored before versioning count as version 0
+type Versioned interface {
+	Persistable
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
+// VersionField is the document field holding a Versioned object's version
+const VersionField string = "version"
+
 // MongoAbstraction defines the set of DAL functions for accessing this Mongo collection
+// Every call takes the context of the request it serves, and gives up once that is cancelled or past its deadline
+// Failures match ErrDuplicate, ErrNotFound, ErrConflict or ErrStoreUnavailable wherever one of those applies. Giving
+// up matches ErrStoreUnavailable along with context.Canceled or context.DeadlineExceeded
 type MongoAbstraction interface {
-	ConnectToMongo() error
-	CountInCollection(collectionName string, query bson.M) (int64, error)
-	DeleteFromCollection(collectionName string, id string) error
-	FetchAllFromCollection(collectionName string) ([][]byte, error)
-	FetchFromCollection(collectionName string, query bson.M) ([][]byte, error)
-	FetchIDFromCollection(collectionName string, id string) ([]byte,error)
-	UpdateCollection(collectionName string, object Persistable) error
-	WriteCollection(collectionName string, object Persistable) error
+	ConnectToMongo(ctx context.Context) error
+	CountInCollection(ctx context.Context, collectionName string, query bson.M) (int64, error)
+	DeleteFromCollection(ctx context.Context, collectionName string, id string) error
+	FetchAllFromCollection(ctx context.Context, collectionName string) ([][]byte, error)
+	FetchFromCollection(ctx context.Context, collectionName string, query bson.M) ([][]byte, error)
+	FetchIDFromCollection(ctx context.Context, collectionName string, id string) ([]byte,error)
+	UpdateCollection(ctx context.Context, collectionName string, object Persistable) error
+	WriteCollection(ctx context.Context, collectionName string, object Persistable) error
+	WriteManyToCollection(ctx context.Context, collectionName string, objects []Persistable) (map[int]error, error)
 }
 
-// MongoSession defines an instantiation of a Mongo DAL. The session maintains a connected state to Mongodb.
+// MongoSession defines an instantiation of a Mongo DAL. The session holds a mongo client, which keeps its own watch
+// on the server and reconnects as needed, so operations go straight to the server rather than checking first.
 type MongoSession struct {
+	mu             sync.RWMutex // guards session and db, which are replaced if the client is disconnected
 	session        *mongo.Client
 	db             *mongo.Database
 	mongoURL       string
@@ -44,6 +65,7 @@ type MongoSession struct {
 }
 
 // DbName designates the default DB name in mongo
+// DefaultTimeout bounds connecting, and any operation whose context doesn't carry a deadline of its own
 const (
 	DefaultDbName  string        = "defaultDB"
 	DefaultTimeout time.Duration = 10 * time.Second
@@ -72,200 +94,334 @@ func NewMongoSession(mongoURL string, dbName string, logger *log.Logger, overrid
 		return
 	}
 	ms.logger.Printf("New MongoSession established for %s", ms.mongoURL)
-	if  err = ms.ConnectToMongo(); err != nil {
-		err = fmt.Errorf("MongoSession connect failure: %v", err)
+	if  err = ms.ConnectToMongo(context.Background()); err != nil {
+		err = Errorf(ErrStoreUnavailable, "MongoSession connect failure: %w", err)
 	}
 	return
 }
 
-// ConnectToMongo creates a connection to the specified mongodb instance
-func (ms *MongoSession) ConnectToMongo() (err error) {
-	opts := options.Client().SetConnectTimeout(ms.timeoutSeconds).SetAppName("wordassassin").ApplyURI(ms.mongoURL)
-	ms.session, err = mongo.Connect(context.Background(), opts)
-	if err != nil { return }
-	ms.db = ms.session.Database(ms.dbName)
-	err = ms.CheckConnection()
-	return
+// ConnectToMongo creates a client for the specified mongodb instance, and checks that the server answers
+func (ms *MongoSession) ConnectToMongo(ctx context.Context) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.connect(ctx)
 }	
 
-// CheckAndReconnect ensures that there is an active DB connection to mongo. Attempts to reestablish connection if needed
-func (ms *MongoSession) CheckAndReconnect() (err error) {
-	if checkErr := ms.CheckConnection(); checkErr != nil {
-		err = ms.ConnectToMongo()
-	}	
-	return
+// connect replaces the client. The caller holds ms.mu
+// Reads and writes that fail on a network error are retried once by the driver, which covers a server stepping down
+// or a connection dropped between operations
+func (ms *MongoSession) connect(ctx context.Context) (err error) {
+	ctx, cancel := ms.opContext(ctx)
+	defer cancel()
+	opts := options.Client().
+		SetConnectTimeout(ms.timeoutSeconds).
+		SetRetryReads(true).
+		SetRetryWrites(true).
+		SetAppName("wordassassin").
+		ApplyURI(ms.mongoURL)
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil { return }
+	ms.session = client
+	ms.db = client.Database(ms.dbName)
+	return ping(ctx, client)
 }	
 
 // CheckConnection validates if the server can be successfully pinged. Provides the error from the mongo client on false.
-// Uses a hardcoded timeout of 2 seconds. Might want to change that at some point.
-func (ms *MongoSession) CheckConnection() (err error) {
-	if ms.session == nil {
+// The ping gives up at the context's deadline, or after the session's timeout if it has none.
+// Operations don't need to call this first. It's for health checks
+func (ms *MongoSession) CheckConnection(ctx context.Context) (err error) {
+	ms.mu.RLock()
+	client := ms.session
+	ms.mu.RUnlock()
+	if client == nil {
 		panic("CheckConnection called with nil session")
 	}	
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := ms.opContext(ctx)
 	defer cancel()
-	err = ms.session.Ping( ctx, nil )
-	if err != nil && err.Error() == "context deadline exceeded" {
-		err = fmt.Errorf("Ping timed out. No DB found")
-	}	
+	return ping(ctx, client)
+}	
 
+func ping(ctx context.Context, client *mongo.Client) (err error) {
+	if err = client.Ping(ctx, nil); err != nil && errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("Ping timed out. No DB found")
+	}
 	return
-}	
+}
 
 // CountInCollection provides a count of the documents in the specified collection that match the query Document
-func (ms *MongoSession) CountInCollection(coll string, query bson.M) (result int64, err error) {
-	if err := ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("CountInCollection: could not establish mongo connection: %s", err)
-		return 0, err
-	}	
-	
-	myCollection := ms.db.Collection(coll)
-	count, err := myCollection.CountDocuments(context.Background(), &query)
-	if err != nil { 
-		ms.logger.Printf("CountInCollection: %s on Count attempt from %s", err.Error(), coll)
+func (ms *MongoSession) CountInCollection(ctx context.Context, coll string, query bson.M) (result int64, err error) {
+	err = ms.withDB(ctx, "CountInCollection", func(ctx context.Context, db *mongo.Database) (opErr error) {
+		result, opErr = db.Collection(coll).CountDocuments(ctx, &query)
+		if opErr != nil {
+			ms.logger.Printf("CountInCollection: %s on Count attempt from %s", opErr.Error(), coll)
+			return Errorf(ErrStoreUnavailable, "%w", opErr)
+		}
+		return nil
+	})
+	if err != nil {
 		return 0, err
-	}	
-	return count, nil
+	}
+	return result, nil
 }	
 
 // DeleteFromCollection removes the Loc by ID from the specified collection
-// If the ID is not found, logs and then returns an error containing the message "no documents"
-func (ms *MongoSession) DeleteFromCollection(coll string, id string) (err error) {
-	if err = ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("DeleteFromCollection: could not establish mongo connection: %s", err)
-		return
-	}
-
-	myCollection := ms.db.Collection(coll)
-	var dResult *mongo.DeleteResult
-	dResult, err = myCollection.DeleteOne(
-		context.Background(),
-		bson.M{ "_id": id },
-	)
-	if err != nil {
-		ms.logger.Printf("DeleteFromCollection: no mongo connection: %s", err)
-	} else if dResult.DeletedCount == 0 {
-		err = fmt.Errorf("Delete failed: no documents for id=%s in collection %s", id, coll)
-		ms.logger.Printf("DeleteFromCollection: no documents for id=%s in collection %s", id, coll)
-	}
-	
-	return
+// If the ID is not found, logs and then returns an ErrNotFound error containing the message "no documents"
+func (ms *MongoSession) DeleteFromCollection(ctx context.Context, coll string, id string) (err error) {
+	return ms.withDB(ctx, "DeleteFromCollection", func(ctx context.Context, db *mongo.Database) error {
+		dResult, delErr := db.Collection(coll).DeleteOne(
+			ctx,
+			bson.M{ "_id": id },
+		)
+		if delErr != nil {
+			ms.logger.Printf("DeleteFromCollection: no mongo connection: %s", delErr)
+			return Errorf(ErrStoreUnavailable, "%w", delErr)
+		} else if dResult.DeletedCount == 0 {
+			ms.logger.Printf("DeleteFromCollection: no documents for id=%s in collection %s", id, coll)
+			return Errorf(ErrNotFound, "Delete failed: no documents for id=%s in collection %s", id, coll)
+		}
+		return nil
+	})
 }
 
 // FetchAllFromCollection fetches all the Persistables from the specified collection
 // They are returned in an array of the specified type in sample, which is supplied only for typing purposes
-func (ms *MongoSession) FetchAllFromCollection(coll string) (results [][]byte, err error) {
-	return ms.FetchFromCollection(coll, bson.M{})
+func (ms *MongoSession) FetchAllFromCollection(ctx context.Context, coll string) (results [][]byte, err error) {
+	return ms.FetchFromCollection(ctx, coll, bson.M{})
 }	
 
 // FetchFromCollection fetches the Persistables from the specified collection that match the query Document
 // They are returned in an array of the specified type in sample, which is supplied only for typing purposes
-func (ms *MongoSession) FetchFromCollection(coll string, query bson.M) (results [][]byte, err error) {
-	if err := ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("FetchFromCollection: could not establish mongo connection: %s", err)
-		return nil, err
-	}	
-
-	results = make([][]byte,0)
-	myCollection := ms.db.Collection(coll)
-	cur, err := myCollection.Find(context.Background(), &query)
-	if err != nil { 
-		ms.logger.Printf("FetchFromCollection: %s on Find attempt from %s", err.Error(), coll)
-		return nil, err
-	}	
-	defer cur.Close(context.Background())
-	
-	for cur.Next(context.Background()) {
-		var doc bson.M
-		err = cur.Decode(&doc)
-		if err != nil { 
-			ms.logger.Printf("FetchFromCollection: %s on Decode attempt for %s", err.Error(), doc)
-			return nil, err
-		}	
-		var elem []byte
-		elem, err = bson.Marshal(doc)
-		if err != nil {
-			return nil, err
+func (ms *MongoSession) FetchFromCollection(ctx context.Context, coll string, query bson.M) (results [][]byte, err error) {
+	err = ms.withDB(ctx, "FetchFromCollection", func(ctx context.Context, db *mongo.Database) error {
+		results = make([][]byte,0)
+		cur, findErr := db.Collection(coll).Find(ctx, &query)
+		if findErr != nil { 
+			ms.logger.Printf("FetchFromCollection: %s on Find attempt from %s", findErr.Error(), coll)
+			return Errorf(ErrStoreUnavailable, "%w", findErr)
 		}	
-		results = append(results, elem)
-	}	
+		defer cur.Close(ctx)
 	
-	return results, cur.Err()
+		for cur.Next(ctx) {
+			var doc bson.M
+			if decodeErr := cur.Decode(&doc); decodeErr != nil { 
+				ms.logger.Printf("FetchFromCollection: %s on Decode attempt for %s", decodeErr.Error(), doc)
+				return decodeErr
+			}	
+			elem, marshalErr := bson.Marshal(doc)
+			if marshalErr != nil {
+				return marshalErr
+			}	
+			results = append(results, elem)
+		}	
+		if curErr := cur.Err(); curErr != nil {
+			return Errorf(ErrStoreUnavailable, "%w", curErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
 }	
 
 // FetchIDFromCollection fetches the Persistable by ID from the specified collection
-// If the ID is not found, logs and then returns an error containing the message "no documents"
-func (ms *MongoSession) FetchIDFromCollection(coll string, id string) (result []byte, err error) {
-	if err = ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("FetchIDFromCollection: could not establish mongo connection: %s", err)
-		return
-	}	
-	
-	myCollection := ms.db.Collection(coll)
-	var queryResult bson.M
-	err = myCollection.FindOne(
-		context.Background(),
-		bson.M{ "_id": id },
-	).Decode(&queryResult)	
-	if err != nil {
-		ms.logger.Printf("FetchIDFromCollection: %s on Decode attempt for %s", err.Error(), id)
-		return
-	}	
-	result, err = bson.Marshal(queryResult)
+// If the ID is not found, logs and then returns an ErrNotFound error containing the message "no documents"
+func (ms *MongoSession) FetchIDFromCollection(ctx context.Context, coll string, id string) (result []byte, err error) {
+	err = ms.withDB(ctx, "FetchIDFromCollection", func(ctx context.Context, db *mongo.Database) error {
+		var queryResult bson.M
+		findErr := db.Collection(coll).FindOne(
+			ctx,
+			bson.M{ "_id": id },
+		).Decode(&queryResult)	
+		if findErr != nil {
+			ms.logger.Printf("FetchIDFromCollection: %s on Decode attempt for %s", findErr.Error(), id)
+			if errors.Is(findErr, mongo.ErrNoDocuments) {
+				return Errorf(ErrNotFound, "%w", findErr)
+			}
+			return Errorf(ErrStoreUnavailable, "%w", findErr)
+		}	
+		var marshalErr error
+		result, marshalErr = bson.Marshal(queryResult)
+		return marshalErr
+	})
 	return
 }	
 
 // UpdateCollection updates the Persistable object in the specified collection with a matching _id element to the passed in object
-// If the ID is not found, logs and then returns an error containing the message "no documents"
-func (ms *MongoSession) UpdateCollection(coll string, obj Persistable) (err error) {
-	if err = ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("UpdateCollection: could not establish mongo connection: %s", err)
-		return
-	}	
-	
-	myCollection := ms.db.Collection(coll)
-	uResult, replaceErr := myCollection.ReplaceOne(
-		context.Background(),
-		bson.M{ "_id": obj.GetID() },
-		obj,
-	)	
-	if replaceErr != nil {
-		ms.logger.Printf("UpdateCollection: topology is closed on update attempt for %s", obj.GetID())
-		err = fmt.Errorf("Fail to update Document: %s", replaceErr)
-	}	
-	if uResult.MatchedCount == 0 {
-		ms.logger.Printf("UpdateCollection: no documents for ID=%s in collection %s", obj.GetID(), coll)
-		err = fmt.Errorf("Update failed. no documents for ID=%s in collection %s", obj.GetID(), coll)
-	}	
-	
-	return
+// If the ID is not found, logs and then returns an ErrNotFound error containing the message "no documents"
+// A Versioned object only replaces a document still at its version, and fails with ErrConflict otherwise
+func (ms *MongoSession) UpdateCollection(ctx context.Context, coll string, obj Persistable) (err error) {
+	filter := bson.M{ "_id": obj.GetID() }
+	expected, versioned := nextVersion(obj)
+	if versioned {
+		filter[VersionField] = versionQuery(expected)
+		defer func() {
+			if err != nil {
+				obj.(Versioned).SetVersion(expected)
+			}
+		}()
+	}
+	return ms.withDB(ctx, "UpdateCollection", func(ctx context.Context, db *mongo.Database) error {
+		uResult, replaceErr := db.Collection(coll).ReplaceOne(
+			ctx,
+			filter,
+			obj,
+		)	
+		if replaceErr != nil {
+			ms.logger.Printf("UpdateCollection: %s on update attempt for %s", replaceErr, obj.GetID())
+			return Errorf(ErrStoreUnavailable, "Fail to update Document: %w", replaceErr)
+		}	
+		if uResult.MatchedCount == 0 && versioned {
+			// Tell a document that moved on from one that isn't there at all
+			count, countErr := db.Collection(coll).CountDocuments(ctx, bson.M{ "_id": obj.GetID() })
+			if countErr != nil {
+				return Errorf(ErrStoreUnavailable, "Fail to update Document: %w", countErr)
+			}
+			if count > 0 {
+				ms.logger.Printf("UpdateCollection: ID=%s in collection %s is no longer at version %d", obj.GetID(), coll, expected)
+				return conflict(coll, obj.GetID(), expected)
+			}
+		}
+		if uResult.MatchedCount == 0 {
+			ms.logger.Printf("UpdateCollection: no documents for ID=%s in collection %s", obj.GetID(), coll)
+			return Errorf(ErrNotFound, "Update failed. no documents for ID=%s in collection %s", obj.GetID(), coll)
+		}	
+		return nil
+	})
 }	
 
-// WriteCollection writes the specified Persistable object to a given collection
-func (ms *MongoSession) WriteCollection(coll string, obj Persistable) (err error) {
-	if err = ms.CheckAndReconnect(); err != nil {
-		ms.logger.Printf("WriteCollection: could not establish mongo connection: %s", err)
+// WriteCollection writes the specified Persistable object to a given collection. An object whose ID is already there
+// fails with ErrDuplicate
+func (ms *MongoSession) WriteCollection(ctx context.Context, coll string, obj Persistable) (err error) {
+	return ms.withDB(ctx, "WriteCollection", func(ctx context.Context, db *mongo.Database) error {
+		wResult, insErr := db.Collection(coll).InsertOne(ctx, obj)
+		if insErr != nil {
+			if isDuplicateKey(insErr) {
+				ms.logger.Printf("WriteCollection: duplicate key on insert for %s", obj.GetID())
+				return Errorf(ErrDuplicate, "Write failed: duplicate key on insert for %s", obj.GetID())
+			}
+			ms.logger.Printf("WriteCollection: %s on insert attempt for %s", insErr, obj.GetID())
+			return Errorf(ErrStoreUnavailable, "Write failed. %w", insErr)
+		} else if wResult.InsertedID == nil {
+			ms.logger.Printf("WriteCollection: %s not inserted in collection %s", obj.GetID(), coll)
+			return fmt.Errorf("Write failed. %s not inserted in collection %s", obj.GetID(), coll)
+		}
+		return nil
+	})
+}
+
+// WriteManyToCollection writes a batch of Persistable objects to a given collection in a single round trip. The
+// write carries on past objects that fail, such as duplicates. Those are returned in a map keyed by their index in
+// objects, while the error return is reserved for failures of the batch as a whole.
+func (ms *MongoSession) WriteManyToCollection(ctx context.Context, coll string, objs []Persistable) (failed map[int]error, err error) {
+	failed = make(map[int]error)
+	if len(objs) == 0 {
 		return
 	}
-
-	myCollection := ms.db.Collection(coll)
-	wResult, insErr := myCollection.InsertOne(context.Background(), obj)
-	if insErr != nil {
-		if strings.Contains(insErr.Error(), "duplicate") {
-			err = fmt.Errorf("Write failed: duplicate key on insert for %s", obj.GetID())
-			ms.logger.Printf("WriteCollection: duplicate key on insert for %s", obj.GetID())
-		} else {
-			err = fmt.Errorf("Write failed. topology is closed on insert: %s", err)
-			ms.logger.Printf("WriteCollection: topology is closed on insert attempt for %s", obj.GetID())
+	docs := make([]interface{}, len(objs))
+	for i, obj := range objs {
+		docs[i] = obj
+	}
+	err = ms.withDB(ctx, "WriteManyToCollection", func(ctx context.Context, db *mongo.Database) error {
+		_, insErr := db.Collection(coll).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+		if insErr == nil {
+			return nil
+		}
+		bulkErr, ok := insErr.(mongo.BulkWriteException)
+		if !ok || bulkErr.WriteConcernError != nil {
+			ms.logger.Printf("WriteManyToCollection: batch insert failed for collection %s: %s", coll, insErr)
+			return Errorf(ErrStoreUnavailable, "Write failed. batch insert to %s: %w", coll, insErr)
 		}
-	} else if wResult.InsertedID == nil {
-		err = fmt.Errorf("Write failed. %s not inserted in collection %s", obj.GetID(), coll)
-		ms.logger.Printf("WriteCollection: %s not inserted in collection %s", obj.GetID(), coll)
+		for _, we := range bulkErr.WriteErrors {
+			if we.Code == duplicateKeyCode {
+				failed[we.Index] = Errorf(ErrDuplicate, "Write failed: duplicate key on insert for %s", objs[we.Index].GetID())
+			} else {
+				failed[we.Index] = fmt.Errorf("Write failed: %s", we.Message)
+			}
+		}
+		ms.logger.Printf("WriteManyToCollection: %d of %d inserts failed for collection %s", len(failed), len(objs), coll)
+		return nil
+	})
+	if err != nil {
+		failed = nil
 	}
+	return
+}
 
+// withDB runs an operation against the database, bounded by the session's timeout unless ctx has a deadline of its
+// own. The driver rides out dropped connections by itself. Only a client that has been disconnected outright is
+// replaced, after which the operation is tried once more
+// Failures once ctx is done are ErrStoreUnavailable, and carry ctx's error for errors.Is to find
+func (ms *MongoSession) withDB(ctx context.Context, op string, fn func(ctx context.Context, db *mongo.Database) error) (err error) {
+	if err = checkContext(ctx, op); err != nil {
+		return
+	}
+	ctx, cancel := ms.opContext(ctx)
+	defer cancel()
+	ms.mu.RLock()
+	db := ms.db
+	ms.mu.RUnlock()
+	if db != nil {
+		err = fn(ctx, db)
+	}
+	if db == nil || isDisconnected(err) {
+		ms.logger.Printf("%s: client disconnected. Reconnecting to %s", op, ms.mongoURL)
+		if db, err = ms.reconnect(ctx, db); err != nil {
+			ms.logger.Printf("%s: could not establish mongo connection: %s", op, err)
+			return Errorf(ErrStoreUnavailable, "%w", err)
+		}
+		err = fn(ctx, db)
+	}
+	if err != nil && errors.Is(err, ErrStoreUnavailable) && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		err = Errorf(ErrStoreUnavailable, "%s: %w", err.Error(), ctx.Err())
+	}
 	return
 }
 
+// reconnect replaces a disconnected client, unless another operation has replaced it already
+func (ms *MongoSession) reconnect(ctx context.Context, stale *mongo.Database) (*mongo.Database, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.db != stale {
+		return ms.db, nil
+	}
+	if err := ms.connect(ctx); err != nil {
+		return nil, err
+	}
+	return ms.db, nil
+}
+
+// opContext bounds an operation by the session's timeout, unless the caller has set a deadline of their own
+func (ms *MongoSession) opContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, ms.timeoutSeconds)
+}
+
+// isDisconnected tells whether an operation failed because the client itself was disconnected, which the driver
+// won't recover from
+func isDisconnected(err error) bool {
+	return err != nil && (errors.Is(err, mongo.ErrClientDisconnected) || strings.Contains(err.Error(), "topology is closed"))
+}
+
+// duplicateKeyCode is the code the server fails a write with when it breaks a unique index
+const duplicateKeyCode = 11000
+
+// isDuplicateKey tells whether an insert failed on the unique index, which is where duplicates are caught. Only the
+// server's error code is trusted, since the message of any error may mention a duplicate
+func isDuplicateKey(err error) bool {
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		for _, e := range we.WriteErrors {
+			if e.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // func (ms *MongoSession) collectionExists(collName string) bool {
 	// 	names, err := ms.db.ListCollections(context.Background(), bson.Doc{})
 // 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -82,7 +83,7 @@ func (m *MongoSessionSuite) TestCtorInvalidConnectionStringThrowsError() {
 func (m *MongoSessionSuite) TestConnectToMongo() {
 	testMS, err := NewMongoSession(TestMongoURL, TestDbName, m.logger)
 	require.NoError(m.T(), err, "Test failed in creating MongoSession. Err: %s", err)
-	err = testMS.ConnectToMongo()
+	err = testMS.ConnectToMongo(context.Background())
 	require.NoError(m.T(), err, "Sucessful connect throws no error. Instead we got %s", err)
 }
 
@@ -108,13 +109,13 @@ func (m *MongoSessionSuite) TestDeleteFromCollection() {
 		err = AddToMongoCollection(t, m.session, TestCollection, testEvent)
 		require.NoError(t, err, "Test failed in setup adding to collection. Err: %s", err)
 
-		err = testMS.DeleteFromCollection(TestCollection, testEvent.GetID())
+		err = testMS.DeleteFromCollection(context.Background(), TestCollection, testEvent.GetID())
 		require.NoError(t, err, "Successful deletions throw no errors. But this threw: %s", err)
 		// validate that it's actually deleted now
 		// TODO: fix fetchone for more complete mesaging
 		// expectedMsg := fmt.Sprintf("no documents for id=%s", testEvent.GetID())
 		expectedMsg := fmt.Sprintf("no documents")
-		_, fErr := testMS.FetchIDFromCollection(TestCollection, testEvent.GetID())
+		_, fErr := testMS.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
 		require.Error(t, fErr, "A deleted id should throw an error on fetch")
 		require.Contains(t, fErr.Error(), expectedMsg, "The error should be a 'not found' message. Instead it is %s", fErr)
 	})
@@ -122,7 +123,7 @@ func (m *MongoSessionSuite) TestDeleteFromCollection() {
 		testID := "I don't exist"
 		expectedMsg := fmt.Sprintf("no documents for id=%s", testID)
 
-		err = testMS.DeleteFromCollection(TestCollection, testID)
+		err = testMS.DeleteFromCollection(context.Background(), TestCollection, testID)
 		require.Error(t, err, "Delete on missing ID should throw error")
 		require.Containsf(t, err.Error(), expectedMsg, "should specify why it threw on missing ID")
 	})
@@ -130,7 +131,7 @@ func (m *MongoSessionSuite) TestDeleteFromCollection() {
 		testID := "it matters not"
 		expectedMsg := fmt.Sprintf("no documents for id=%s", testID)
 		testBadCollection := "garbage"
-		err = testMS.DeleteFromCollection(testBadCollection, testID)
+		err = testMS.DeleteFromCollection(context.Background(), testBadCollection, testID)
 		require.Error(t, err, "Should get error message when attempt to access non-existent collection")
 		require.Contains(t, err.Error(), expectedMsg, "Looking for the not found phrase, but got: %s", err)
 	})
@@ -140,7 +141,7 @@ func (m *MongoSessionSuite) TestDeleteFromCollection() {
 
 		brokenSession, _ := GetMongoSessionWithLogger(t)
 		err = brokenSession.session.Disconnect(context.Background())
-		err = brokenSession.DeleteFromCollection(TestCollection, testID)
+		err = brokenSession.DeleteFromCollection(context.Background(), TestCollection, testID)
 		require.Error(t, err, "Should get an error for missing ID to prove it hit the server")
 		require.Contains(t, err.Error(), expectedMsg, "Should complain about missing ID")
 	})
@@ -178,7 +179,7 @@ func (m *MongoSessionSuite) TestFetchAllFromCollection() {
 	require.NoError(m.T(), err, "Test failed in creating MongoSession. Err: %s", err)
 
 	m.T().Run("Positive", func(t *testing.T) {
-		results, fErr := testMS.FetchAllFromCollection(TestCollection)
+		results, fErr := testMS.FetchAllFromCollection(context.Background(), TestCollection)
 		require.NoError(t, fErr, "Successful lookup throws no error. Instead we got %s", fErr)
 		require.NotNil(t, results, "Successful lookup has to actually return something")
 		require.Equal(t, len(testEvents), len(results))
@@ -213,7 +214,7 @@ func (m *MongoSessionSuite) TestFetchIDFromCollection() {
 
 	m.T().Run("Positive", func(t *testing.T) {
 		result := GenericPersistable{}
-		resultBytes, fErr := testMS.FetchIDFromCollection(TestCollection, testEvent.GetID())
+		resultBytes, fErr := testMS.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
 		require.NoError(t, fErr, "Successful lookup throws no error. Instead we got %s", err)
 		require.NotNil(t, resultBytes, "Successful lookup has to actually return something")
 		err = result.Decode(resultBytes)
@@ -224,14 +225,14 @@ func (m *MongoSessionSuite) TestFetchIDFromCollection() {
 	})
 	m.T().Run("Missing ID", func(t *testing.T) {
 		badID := "I an I bad, mon"
-		_, fErr := testMS.FetchIDFromCollection(TestCollection, badID)
+		_, fErr := testMS.FetchIDFromCollection(context.Background(), TestCollection, badID)
 		require.Error(t, fErr, "Missing id should throw an error")
 		require.Contains(t, fErr.Error(), "no documents", "Message should give a clue. Instead it is %s", fErr)
 	})
 	m.T().Run("Dropped connection should recover", func(t *testing.T) {
 		brokenSession, _ := GetMongoSessionWithLogger(t)
 		err = brokenSession.session.Disconnect(context.Background())
-		_, fErr := brokenSession.FetchIDFromCollection(TestCollection, testEvent.GetID())
+		_, fErr := brokenSession.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
 		require.NoError(t, fErr)
 	})
 }
@@ -256,10 +257,10 @@ func (m *MongoSessionSuite) TestUpdateCollection() {
 		updateEvent.Name = expected
 		var actual GenericPersistable
 
-		err = testMS.UpdateCollection(TestCollection, updateEvent)
+		err = testMS.UpdateCollection(context.Background(), TestCollection, updateEvent)
 		require.NoError(t, err, "Successful update throws no error. Instead we got %s", err)
 		var resultBytes []byte
-		resultBytes, err = testMS.FetchIDFromCollection(TestCollection, updateEvent.GetID())
+		resultBytes, err = testMS.FetchIDFromCollection(context.Background(), TestCollection, updateEvent.GetID())
 		require.NoError(t, err, "Failed to fetch result: %s", err)
 		err = actual.Decode(resultBytes)
 		require.NoError(t, err, "Failed to decode result bytes: %s", err)
@@ -270,7 +271,7 @@ func (m *MongoSessionSuite) TestUpdateCollection() {
 		*badIDEvent = *testEvent
 		badIDEvent.ID = "I b missing"
 
-		err = testMS.UpdateCollection(TestCollection, badIDEvent)
+		err = testMS.UpdateCollection(context.Background(), TestCollection, badIDEvent)
 		require.Error(t, err, "Missing ID should error on update")
 		require.Contains(t, err.Error(), "no documents", "Looking for message about ID missing, but got: %s", err)
 	})
@@ -285,16 +286,27 @@ func (m *MongoSessionSuite) TestUpdateCollection() {
 			}
 		}
 
-		err = testMS.UpdateCollection(testBadCollection, testEvent)
+		err = testMS.UpdateCollection(context.Background(), testBadCollection, testEvent)
 		require.Error(t, err, "Should get error message when attempt to access non-existent collection")
 		require.Contains(t, err.Error(), "no documents", "Looking for message about not found, but got: %s", err)
 	})
 	m.T().Run("Dropped connection recovers", func(t *testing.T) {
 		brokenSession, _ := GetMongoSessionWithLogger(t)
 		err = brokenSession.session.Disconnect(context.Background())
-		err = brokenSession.UpdateCollection(TestCollection, testEvent)
+		err = brokenSession.UpdateCollection(context.Background(), TestCollection, testEvent)
 		require.NoError(t, err, "Should not get an error on a valid update after reconnect")
 	})
+	m.T().Run("Versioned", func(t *testing.T) {
+		first := &VersionedPersistable{ID: "versioned", Name: "first"}
+		require.NoError(t, testMS.WriteCollection(context.Background(), TestCollection, first))
+		stale := *first
+		require.NoError(t, testMS.UpdateCollection(context.Background(), TestCollection, first))
+		require.Equal(t, int64(1), first.Version)
+		stale.Name = "stale"
+		err = testMS.UpdateCollection(context.Background(), TestCollection, &stale)
+		require.True(t, errors.Is(err, ErrConflict), "Update of a version already replaced should conflict. Instead got %v", err)
+		require.Equal(t, int64(0), stale.Version, "A failed update leaves the version alone")
+	})
 }
 
 func (m *MongoSessionSuite) TestWriteCollection() {
@@ -310,10 +322,10 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 
 	m.T().Run("Positive", func(t *testing.T) {
 		actual := GenericPersistable{}
-		err = testMS.WriteCollection(TestCollection, testEvent)
+		err = testMS.WriteCollection(context.Background(), TestCollection, testEvent)
 		require.NoError(t, err, "Successful write throws no error. Instead we got %s", err)
 
-		actualBytes, fErr := testMS.FetchIDFromCollection(TestCollection, testEvent.GetID())
+		actualBytes, fErr := testMS.FetchIDFromCollection(context.Background(), TestCollection, testEvent.GetID())
 		require.NoError(t, fErr, "Failed to validate write for id=%s", testEvent.GetID())
 		err = actual.Decode(actualBytes)
 	})
@@ -323,7 +335,7 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 		err = AddToMongoCollection(t, m.session, TestCollection, dupTestEvent)
 		require.NoError(t, err, "Test failed in setup adding to collection. Err: %s", err)
 
-		err = testMS.WriteCollection(TestCollection, testEvent)
+		err = testMS.WriteCollection(context.Background(), TestCollection, testEvent)
 		require.Error(t, err, "Attempt to insert duplicate event should throw")
 		require.Contains(t, err.Error(), "duplicate", "Expect error text to mention this")
 	})
@@ -331,7 +343,7 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 		testBadCollection := "garbage"
 		ClearMongoCollection(t, m.session, testBadCollection)
 
-		err = testMS.WriteCollection(testBadCollection, testEvent)
+		err = testMS.WriteCollection(context.Background(), testBadCollection, testEvent)
 		require.NoErrorf(t, err, "Writes should create collection on the fly. Got err: %s", err)
 		writeCount, _ := m.session.DB(TestDbName).C(testBadCollection).Count()
 		require.True(t, writeCount == 1, "Record should have been written as only entry")
@@ -339,7 +351,7 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 	m.T().Run("Dropped connection should recover", func(t *testing.T) {
 		brokenSession, _ := GetMongoSessionWithLogger(t)
 		err = brokenSession.session.Disconnect(context.Background())
-		err = brokenSession.WriteCollection(TestCollection, 
+		err = brokenSession.WriteCollection(context.Background(), TestCollection, 
 			&GenericPersistable {
 				ID:   "-999",
 				Name: "Someone",
@@ -350,6 +362,16 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 	})
 }
 
+// TestConformance runs the checks shared by every MongoAbstraction against mongo itself
+func (m *MongoSessionSuite) TestConformance() {
+	suite.Run(m.T(), &AbstractionSuite{open: func(t *testing.T) MongoAbstraction {
+		ClearMongoCollection(t, m.session, TestCollection)
+		ClearMongoCollection(t, m.session, "garbage")
+		ms, _ := GetMongoSessionWithLogger(t)
+		return ms
+	}})
+}
+
 /*** Helper functions ***/
 
 	func ClearMongoCollection(t *testing.T, session *mgo.Session, collName string) {
@@ -371,7 +393,7 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 		var err error
 		ms, err = NewMongoSession(TestMongoURL, TestDbName, blog, 3)
 		require.NoError(t, err, "Test failed in creating MongoSession. Err: %s", err)
-		err = ms.ConnectToMongo()
+		err = ms.ConnectToMongo(context.Background())
 		require.NoError(t, err, "Test failed in connecting MongoSession. Err: %s", err)
 		require.NotNil(t, ms, "Test failed in setting up session")
 		return
@@ -408,3 +430,25 @@ func (m *MongoSessionSuite) TestWriteCollection() {
 		result, _ := json.Marshal(e)
 		return result
 	}
+
+	// VersionedPersistable is a GenericPersistable guarded against lost updates
+	type VersionedPersistable struct {
+		ID      string `bson:"_id"`
+		Name    string `bson:"name"`
+		Version int64  `bson:"version"`
+	}
+
+	// GetID returns the unique identifer for this object
+	func (v *VersionedPersistable) GetID() string {
+		return v.ID
+	}
+
+	// GetVersion returns the version this object was read at
+	func (v *VersionedPersistable) GetVersion() int64 {
+		return v.Version
+	}
+
+	// SetVersion is for the store to move the version on
+	func (v *VersionedPersistable) SetVersion(version int64) {
+		v.Version = version
+	}
@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+// nextVersion moves a Versioned object on to the version its update will store, and returns the version the stored
+// document must still be at. Callers put the object back to that version if the update doesn't go through. Objects
+// that aren't Versioned are left alone
+func nextVersion(obj Persistable) (expected int64, versioned bool) {
+	v, versioned := obj.(Versioned)
+	if !versioned {
+		return 0, false
+	}
+	expected = v.GetVersion()
+	v.SetVersion(expected + 1)
+	return expected, true
+}
+
+// versionQuery matches a document at the expected version. Version 0 also matches documents from before versioning,
+// which have no version at all
+func versionQuery(expected int64) interface{} {
+	if expected == 0 {
+		return bson.M{"$in": bson.A{int64(0), nil}}
+	}
+	return expected
+}
+
+// storedVersion reads the version a document is at
+func storedVersion(doc bson.Raw) int64 {
+	rv, err := doc.LookupErr(VersionField)
+	if err != nil {
+		return 0
+	}
+	n, _ := number(rv)
+	return int64(n)
+}
+
+// conflict reports an update made against a version of the document that has since been replaced
+func conflict(coll string, id string, expected int64) error {
+	return Errorf(ErrConflict, "Update failed. ID=%s in collection %s was changed since version %d", id, coll, expected)
+}
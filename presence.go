@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	slack "wordassassin/slack"
+)
+
+// presenceWindow is how long after someone last posted in a channel they still count as present there
+const presenceWindow = 30 * time.Minute
+
+// presence remembers who has posted in which channel lately. Like the kills waiting on confirmation, it's only kept
+// in memory, and starts empty after a restart. It is safe for concurrent use.
+type presence struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]map[slack.SlackID]time.Time // by channel, then user
+}
+
+func newPresence(window time.Duration) *presence {
+	return &presence{window: window, seen: make(map[string]map[slack.SlackID]time.Time)}
+}
+
+// saw records a user posting in a channel at a time, and forgets whoever in the channel has been gone too long
+func (p *presence) saw(channel string, user slack.SlackID, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	users, found := p.seen[channel]
+	if !found {
+		users = make(map[slack.SlackID]time.Time)
+		p.seen[channel] = users
+	}
+	for u, last := range users {
+		if at.Sub(last) > p.window {
+			delete(users, u)
+		}
+	}
+	if last, found := users[user]; !found || at.After(last) {
+		users[user] = at
+	}
+}
+
+// present checks whether a user posted in a channel within the window before a time
+func (p *presence) present(channel string, user slack.SlackID, at time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last, found := p.seen[channel][user]
+	return found && at.Sub(last) <= p.window
+}
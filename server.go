@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 
 	dao "wordassassin/persistence"
+	slack "wordassassin/slack"
 	types "wordassassin/types"
 )
 
@@ -18,29 +25,43 @@ const (
 	defaultPort       string = "8080"
 	serverPortEnvName string = "PORT"
 	mongoURLEnvName   string = "MONGOURL"
+	dataDirEnvName    string = "DATADIR"
+	replayEnvName     string = "REPLAYEVENTS"
+	shutdownTimeout   = 30 * time.Second
+	tokenRequired     = "A player token is required as an Authorization: Bearer header"
 )
 
 var (
 	port     string
 	mongoURL string
 	logger   *log.Logger
-	mongo    *dao.MongoSession
+	mongo    dao.MongoAbstraction
 	games    types.GamePoolAbstraction
-	players  types.PlayerPool
+	players  *types.PlayerPool
 	handler  *Handler
 )
 
+func abortGame(c echo.Context) error {
+	gameid := c.Param("gameid")
+	slackid := c.Param("slackid")
+	if err := handler.OnGameAborted(c.Request().Context(), gameid, slackid); err != nil {
+		return respondError(c, "OnGameAborted", err)
+	}
+	message := fmt.Sprintf("Game %s aborted by %s", gameid, slackid)
+	return respondGame(c, message, gameid)
+}
+
 func addPlayer(c echo.Context) error {
 	gameid := c.Param("gameid")
 	slackid := c.Param("slackid")
 	name := c.Param("name")
 	email := c.Param("email")
-	if err := handler.OnPlayerAdded(gameid, slackid, name, email); err != nil {
-		logger.Printf("OnPlayerAdded error: %s", err.Error())
-		return c.HTML(http.StatusInternalServerError, err.Error())
+	token, err := handler.OnPlayerAdded(c.Request().Context(), gameid, slackid, name, email)
+	if err != nil {
+		return respondError(c, "OnPlayerAdded", err)
 	}	
-	message := fmt.Sprintf("Player %s added to game %s", slackid, gameid)
-	return c.HTML(http.StatusOK, message)
+	message := fmt.Sprintf("Player %s added to game %s. Your player token is %s. Keep it secret", slackid, gameid, token)
+	return respond(c, http.StatusOK, message, playerAddedResponse{GameID: gameid, SlackID: slackid, Token: token})
 }
 		
 func createGame(c echo.Context) error {
@@ -49,21 +70,90 @@ func createGame(c echo.Context) error {
 	creator := c.QueryParam("creator")
 	killdict := c.QueryParam("dict")
 	passcode := c.QueryParam("pwd")
+	channel := c.QueryParam("channel")
 
-	if err := handler.OnGameCreated(gameid, creator, killdict, passcode); err != nil {
-		logger.Printf("OnGameCreated error: %s", err.Error())
-		return c.HTML(http.StatusInternalServerError, err.Error())
+	if err := handler.OnGameCreated(c.Request().Context(), gameid, creator, killdict, passcode, channel); err != nil {
+		return respondError(c, "OnGameCreated", err)
 	}
 	message := fmt.Sprintf("<h3>Game Created</h3><p>Game: %s  Creator: %s", gameid, creator)
+	return respondGame(c, message, gameid)
+}
+
+func addWords(c echo.Context) error {
+	dictid := c.Param("dictid")
+	added, rejected, err := handler.OnWordsAdded(c.Request().Context(), dictid, wordsParam(c))
+	if err != nil {
+		return respondError(c, "OnWordsAdded", err)
+	}
+	message := fmt.Sprintf("Added %d words to dictionary %s", added, dictid) + rejectionList(rejected)
+	return respond(c, http.StatusOK, message, wordsResponse{DictID: dictid, Added: added, Rejected: rejected})
+}
+
+func createDictionary(c echo.Context) error {
+	dictid := c.Param("dictid")
+	added, rejected, err := handler.OnDictionaryCreated(c.Request().Context(), dictid, wordsParam(c))
+	if err != nil {
+		if wantsJSON(c) {
+			return respondError(c, "OnDictionaryCreated", err)
+		}
+		// People get to see which words were turned away
+		logger.Printf("OnDictionaryCreated error: %s", err.Error())
+		status, _ := errorStatus(err)
+		return c.HTML(status, err.Error() + rejectionList(rejected))
+	}
+	message := fmt.Sprintf("<h3>Dictionary Created</h3><p>Dictionary: %s  Words: %d", dictid, added) + rejectionList(rejected)
+	return respond(c, http.StatusOK, message, wordsResponse{DictID: dictid, Added: added, Rejected: rejected})
+}
+
+func deleteDictionary(c echo.Context) error {
+	dictid := c.Param("dictid")
+	if err := handler.OnDictionaryDeleted(c.Request().Context(), dictid); err != nil {
+		return respondError(c, "OnDictionaryDeleted", err)
+	}
+	message := fmt.Sprintf("Dictionary %s deleted", dictid)
+	return respond(c, http.StatusOK, message, map[string]string{"dictId": dictid})
+}
+
+func exportDictionary(c echo.Context) error {
+	dictid := c.Param("dictid")
+	format := c.QueryParam("format")
+	var out bytes.Buffer
+	if err := handler.GetDictionaryExport(c.Request().Context(), dictid, format, &out); err != nil {
+		return respondError(c, "GetDictionaryExport", err)
+	}
+	contentType := "text/plain; charset=UTF-8"
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=UTF-8"
+	case "json":
+		contentType = "application/json; charset=UTF-8"
+	}
+	return c.Blob(http.StatusOK, contentType, out.Bytes())
+}
+
+func getDictionaryList(c echo.Context) error {
+	if wantsJSON(c) {
+		return apiListDictionaries(c)
+	}
+	message, err := handler.GetDictionaryList(c.Request().Context())
+	if err != nil {
+		return respondError(c, "GetDictionaryList", err)
+	}
 	return c.HTML(http.StatusOK, message)
 }
 
 func getGameList(c echo.Context) error {
+	if wantsJSON(c) {
+		return apiListGames(c)
+	}
 	return c.HTML(http.StatusOK, handler.GetGamesList())
 }		
 
 func getGameStatus(c echo.Context) error {
 	gameid := c.Param("gameid")
+	if wantsJSON(c) {
+		return apiGameView(c, http.StatusOK, gameid)
+	}
 	if message, exists := handler.GetGameStatus(gameid); exists {
 		return c.HTML(http.StatusOK, message)
 	}	
@@ -71,6 +161,39 @@ func getGameStatus(c echo.Context) error {
 	return c.HTML(http.StatusNotFound, message)
 }	
 
+func getTarget(c echo.Context) error {
+	gameid := c.Param("gameid")
+	slackid := c.Param("slackid")
+	token := bearerToken(c)
+	if token == "" {
+		return respondStatus(c, http.StatusUnauthorized, errCodeNotAuthorized, tokenRequired)
+	}
+	target, err := handler.GetTarget(c.Request().Context(), gameid, slackid, token)
+	if err != nil {
+		return respondError(c, "GetTarget", err)
+	}
+	message := fmt.Sprintf("<h2>Target for %s</h2>Your target is %s. Your kill word is %s", slackid, target.TargetName, target.KillWord)
+	return respond(c, http.StatusOK, message, target)
+}
+
+// bearerToken is the player token sent as an Authorization: Bearer header, if any
+func bearerToken(c echo.Context) string {
+	return strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+}
+
+// authorizePlayer checks the request carries the player's token. When it doesn't, the refusal has already been sent,
+// and its error is returned for the route to pass on
+func authorizePlayer(c echo.Context, gameid, slackid string) (bool, error) {
+	token := bearerToken(c)
+	if token == "" {
+		return false, respondStatus(c, http.StatusUnauthorized, errCodeNotAuthorized, tokenRequired)
+	}
+	if err := handler.AuthenticatePlayer(c.Request().Context(), gameid, slackid, token); err != nil {
+		return false, respondError(c, "AuthenticatePlayer", err)
+	}
+	return true, nil
+}
+
 func healthCheck(c echo.Context) error {
 	return c.HTML(http.StatusOK, "I'm running!")
 }
@@ -78,22 +201,108 @@ func healthCheck(c echo.Context) error {
 func startGame(c echo.Context) error {
 	gameid := c.Param("gameid")
 	slackid := c.Param("slackid")
-	if err := handler.OnGameStarted(gameid, slackid); err != nil {
-		logger.Printf("OnGameStarted error: %s", err.Error())
-		return c.HTML(http.StatusInternalServerError, err.Error())
+	if err := handler.OnGameStarted(c.Request().Context(), gameid, slackid); err != nil {
+		return respondError(c, "OnGameStarted", err)
 	}	
 	message := fmt.Sprintf("Game %s started by %s", gameid, slackid)
-	return c.HTML(http.StatusOK, message)
+	return respondGame(c, message, gameid)
 }	
 
+func importDictionary(c echo.Context) error {
+	dictid := c.Param("dictid")
+	result, err := handler.OnDictionaryImported(c.Request().Context(), dictid, c.QueryParam("format"), c.Request().Body)
+	if err != nil {
+		return respondError(c, "OnDictionaryImported", err)
+	}
+	message := fmt.Sprintf("Imported %d words to dictionary %s", result.Added, dictid) + rejectionList(result.Rejected)
+	return respond(c, http.StatusOK, message, result)
+}
+
+func removePlayer(c echo.Context) error {
+	gameid := c.Param("gameid")
+	slackid := c.Param("slackid")
+	if ok, err := authorizePlayer(c, gameid, slackid); !ok {
+		return err
+	}
+	if err := handler.OnPlayerRemoved(c.Request().Context(), gameid, slackid); err != nil {
+		return respondError(c, "OnPlayerRemoved", err)
+	}
+	message := fmt.Sprintf("Player %s removed from game %s", slackid, gameid)
+	return respondGame(c, message, gameid)
+}
+
+func removeWord(c echo.Context) error {
+	dictid := c.Param("dictid")
+	word := c.Param("word")
+	if err := handler.OnWordRemoved(c.Request().Context(), dictid, word); err != nil {
+		return respondError(c, "OnWordRemoved", err)
+	}
+	message := fmt.Sprintf("Removed %s from dictionary %s", word, dictid)
+	return respond(c, http.StatusOK, message, map[string]string{"dictId": dictid, "word": word})
+}
+
+func reportKill(c echo.Context) error {
+	gameid := c.Param("gameid")
+	slackid := c.Param("slackid")
+	if ok, err := authorizePlayer(c, gameid, slackid); !ok {
+		return err
+	}
+	if err := handler.OnKillReported(c.Request().Context(), gameid, slackid); err != nil {
+		return respondError(c, "OnKillReported", err)
+	}
+	message := fmt.Sprintf("Player %s reported killed in game %s", slackid, gameid)
+	return respondGame(c, message, gameid)
+}
+
+// respondGame follows a change to a game with the message for people, or the game's view for programs
+func respondGame(c echo.Context, message string, gameid string) error {
+	if wantsJSON(c) {
+		return apiGameView(c, http.StatusOK, gameid)
+	}
+	return c.HTML(http.StatusOK, message)
+}
+
+// wordsParam collects the words passed as a comma separated 'words' query param
+func wordsParam(c echo.Context) (words []string) {
+	for _, w := range strings.Split(c.QueryParam("words"), ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	return
+}
+
+// rejectionList renders the words that couldn't be added, if any
+func rejectionList(rejected []string) (result string) {
+	if len(rejected) == 0 {
+		return
+	}
+	result = "<p>Rejected:\n"
+	for _, r := range rejected {
+		result += fmt.Sprintf("<li>%s</li>", r)
+	}
+	return
+}
+
 func setRoutes(e *echo.Echo) {
 	e.GET ("/", healthCheck)
+	e.POST("/abortgame/:gameid/:slackid", abortGame)
 	e.POST("/addplayer/:gameid/:slackid", addPlayer)
+	e.POST("/addwords/:dictid", addWords)
+	e.POST("/createdict/:dictid", createDictionary)
 	e.POST("/creategame/:gameid", createGame)
+	e.POST("/deletedict/:dictid", deleteDictionary)
+	e.GET ("/dictlist", getDictionaryList)
+	e.GET ("/exportdict/:dictid", exportDictionary)
 	e.GET ("/gamestatus/:gameid", getGameStatus)
 	e.GET ("/gamelist", getGameList)
 	e.GET ("/health", healthCheck)
+	e.POST("/importdict/:dictid", importDictionary)
+	e.POST("/removeplayer/:gameid/:slackid", removePlayer)
+	e.POST("/removeword/:dictid/:word", removeWord)
+	e.POST("/reportkill/:gameid/:slackid", reportKill)
 	e.POST("/startgame/:gameid/:slackid", startGame)
+	e.GET ("/target/:gameid/:slackid", getTarget)
 }
 
 func main() {
@@ -105,16 +314,49 @@ func main() {
 	}
 	port = ":" + port
 
-	// MongoURL or bail if not available
-	if mongoURL = os.Getenv(mongoURLEnvName); mongoURL == "" {
-		logger.Fatalf("Can't find Mongo URL env variable: %s", mongoURLEnvName)
+	// Mongo when there's a URL for it, else files in a data directory. Failing both, everything lives in memory, and
+	// is gone when the server stops
+	if mongoURL = os.Getenv(mongoURLEnvName); mongoURL != "" {
+		if mongo, err = dao.NewMongoSession(mongoURL, mongoDB, logger); err != nil {
+			logger.Panicf("NewMongoSession: %s", err)
+		}
+	} else if dataDir := os.Getenv(dataDirEnvName); dataDir != "" {
+		files, err := dao.NewFileSession(dataDir, logger)
+		if err != nil {
+			logger.Panicf("NewFileSession: %s", err)
+		}
+		defer files.Close()
+		mongo = files
+	} else {
+		logger.Printf("Startup: Neither %s nor %s set. Using an in-memory store", mongoURLEnvName, dataDirEnvName)
+		mongo = dao.NewMemorySession()
 	}
-	mongo, err = dao.NewMongoSession(mongoURL, mongoDB, logger)
-	if err != nil { logger.Panicf("NewMongoSession: %s", err)}
 
-	//TODO: Make PlayerPool optional to hide implementation here but still allow dependency injection
-	games = types.NewGamePool(mongo, &types.PlayerPool{})
-	handler = NewHandler(games, mongo, logger)
+	// Restore the rosters along with the games so a restart picks up mid-game. The event log can stand in for the
+	// snapshots when they've drifted
+	var pool *types.GamePool
+	if os.Getenv(replayEnvName) != "" {
+		if pool, players, err = types.RebuildPools(context.Background(), mongo); err != nil {
+			logger.Panicf("RebuildPools: %s", err)
+		}
+		logger.Printf("Startup: Rebuilt %d games from the event log", len(pool.GetGamesList()))
+	} else {
+		if players, err = types.NewPlayerPool(context.Background(), mongo); err != nil {
+			logger.Panicf("NewPlayerPool: %s", err)
+		}
+		if pool, err = types.NewGamePool(context.Background(), mongo, players); err != nil {
+			logger.Panicf("NewGamePool: %s", err)
+		}
+	}
+	games = pool
+	// Players get their targets in Slack when there's a bot to send them
+	var dms slack.Client
+	if token := os.Getenv(slackTokenEnvName); token != "" {
+		dms = slack.NewWebClient(token, "")
+	} else {
+		logger.Printf("Startup: %s not set. Targets won't be sent to players", slackTokenEnvName)
+	}
+	handler = NewHandler(games, mongo, logger, dms)
 
 	//*** Web Server Stuff ***//
 	e := echo.New()
@@ -125,7 +367,31 @@ func main() {
 
 	// Routes
 	setRoutes(e)
+	setAPIRoutes(e)
+	if slackSecret = os.Getenv(slackSecretEnvName); slackSecret != "" {
+		setSlackRoutes(e)
+	} else {
+		logger.Printf("Startup: %s not set. Slack commands are off", slackSecretEnvName)
+	}
 
 	// Start server
-	e.Logger.Fatal(e.Start(port))
+	go func() {
+		if err := e.Start(port); err != nil && err != http.ErrServerClosed {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// On the way down, stop taking requests, then let every game finish the commands already queued
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	logger.Printf("Shutdown: Stopping the server")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		logger.Printf("Shutdown: %s", err)
+	}
+	pool.Drain()
+	logger.Printf("Shutdown: All games drained")
+	handler.FlushMessages()
 }
@@ -0,0 +1,71 @@
+package slack
+
+import (
+	"fmt"
+)
+
+// Block is a Block Kit layout block, keeping the fields the game has a use for. See
+// https://api.slack.com/reference/block-kit/blocks
+type Block struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *Text         `json:"text,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Text object types. Markdown is Slack's own flavor, mrkdwn
+const (
+	PlainText string = "plain_text"
+	Markdown  string = "mrkdwn"
+)
+
+// Header makes a block of large, bold, plain text
+func Header(format string, args ...interface{}) Block {
+	return Block{Type: "header", Text: &Text{Type: PlainText, Text: fmt.Sprintf(format, args...)}}
+}
+
+// Section makes a block of markdown text
+func Section(format string, args ...interface{}) Block {
+	return Block{Type: "section", Text: &Text{Type: Markdown, Text: fmt.Sprintf(format, args...)}}
+}
+
+// Context makes a block of small, grey markdown text, for the details
+func Context(format string, args ...interface{}) Block {
+	return Block{Type: "context", Elements: []interface{}{Text{Type: Markdown, Text: fmt.Sprintf(format, args...)}}}
+}
+
+// Mention formats a user so that Slack shows their name, and lets them know
+func Mention(user SlackID) string {
+	return "<@" + user.ToString() + ">"
+}
+
+// Actions makes a block of interactive elements, such as buttons
+func Actions(elements ...interface{}) Block {
+	return Block{Type: "actions", Elements: elements}
+}
+
+// Button is a Block Kit button. Clicking it posts an interaction with its ActionID and Value
+type Button struct {
+	Type     string `json:"type"`
+	ActionID string `json:"action_id"`
+	Text     Text   `json:"text"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// Button styles. A button with no style is plain
+const (
+	Primary string = "primary"
+	Danger  string = "danger"
+)
+
+// NewButton makes a button with a plain text label
+func NewButton(actionID, value, label, style string) Button {
+	return Button{Type: "button", ActionID: actionID, Text: Text{Type: PlainText, Text: label}, Value: value, Style: style}
+}
@@ -0,0 +1,31 @@
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocks(t *testing.T) {
+	msg := Message{
+		Channel: "C123",
+		Text:    "game1 has begun",
+		Blocks: []Block{
+			Header("%s has begun", "game1"),
+			Section("%s is hunting", Mention("U123")),
+			Context("%d left", 5),
+		},
+	}
+	actual, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"channel": "C123",
+		"text": "game1 has begun",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": "game1 has begun"}},
+			{"type": "section", "text": {"type": "mrkdwn", "text": "<@U123> is hunting"}},
+			{"type": "context", "elements": [{"type": "mrkdwn", "text": "5 left"}]}
+		]
+	}`, string(actual))
+}
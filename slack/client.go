@@ -0,0 +1,156 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is the part of the Slack Web API the game posts through
+type Client interface {
+	// OpenConversation opens the direct message channel with a user, and gives its ID. See conversations.open
+	OpenConversation(ctx context.Context, user SlackID) (channel string, err error)
+	// PostMessage posts to a channel, and gives the posted message's timestamp. See chat.postMessage
+	PostMessage(ctx context.Context, channel string, msg Message) (ts string, err error)
+}
+
+const (
+	// DefaultAPIURL is where the Slack Web API lives
+	DefaultAPIURL string = "https://slack.com/api/"
+	// DefaultRetries is how many more times a call is tried when Slack is busy or can't be reached
+	DefaultRetries int = 3
+	// DefaultBackoff is how long to wait before the first retry. Each retry after waits twice as long as the last
+	DefaultBackoff time.Duration = 500 * time.Millisecond
+)
+
+// WebClient calls the Slack Web API as the bot the token belongs to. Calls that are rate limited, or fail for want
+// of an answer, are tried again with exponential backoff, honoring Slack's Retry-After when it gives one. Calls Slack
+// answered with an error are not. It is safe for concurrent use.
+type WebClient struct {
+	token   string
+	apiURL  string
+	http    *http.Client
+	backoff time.Duration
+}
+
+// NewWebClient creates a client for the bot token. An empty apiURL means DefaultAPIURL. Pass a backoff to
+// override DefaultBackoff
+func NewWebClient(token string, apiURL string, overrideBackoff ...time.Duration) *WebClient {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	wc := &WebClient{
+		token:   token,
+		apiURL:  strings.TrimSuffix(apiURL, "/") + "/",
+		http:    &http.Client{Timeout: 10 * time.Second},
+		backoff: DefaultBackoff,
+	}
+	if len(overrideBackoff) > 0 {
+		wc.backoff = overrideBackoff[0]
+	}
+	return wc
+}
+
+// OpenConversation opens the direct message channel with a user
+func (wc *WebClient) OpenConversation(ctx context.Context, user SlackID) (string, error) {
+	var result struct {
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := wc.call(ctx, "conversations.open", map[string]string{"users": user.ToString()}, &result); err != nil {
+		return "", err
+	}
+	return result.Channel.ID, nil
+}
+
+// PostMessage posts to a channel
+func (wc *WebClient) PostMessage(ctx context.Context, channel string, msg Message) (string, error) {
+	msg.Channel = channel
+	var result struct {
+		TS string `json:"ts"`
+	}
+	if err := wc.call(ctx, "chat.postMessage", msg, &result); err != nil {
+		return "", err
+	}
+	return result.TS, nil
+}
+
+// apiResponse is what every Web API method answers with, alongside its own fields
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// call posts the args to a Web API method as JSON and decodes the answer into result, retrying as needed
+func (wc *WebClient) call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	wait := wc.backoff
+	for try := 0; ; try++ {
+		retryAfter, err := wc.try(ctx, method, body, result)
+		if retryAfter < 0 || try == DefaultRetries {
+			return err
+		}
+		if retryAfter == 0 {
+			retryAfter = wait
+			wait *= 2
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: gave up waiting to retry after %v: %w", method, err, ctx.Err())
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// try makes one attempt at a call. A failure that's worth retrying comes with how long Slack asked to wait, or 0 to
+// back off as usual. Anything else comes with -1
+func (wc *WebClient) try(ctx context.Context, method string, body []byte, result interface{}) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wc.apiURL+method, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", method, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+wc.token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := wc.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, fmt.Errorf("%s: %w", method, err)
+		}
+		return 0, fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", method, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, fmt.Errorf("%s: rate limited", method)
+	case resp.StatusCode >= 500:
+		return 0, fmt.Errorf("%s: %s", method, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return -1, fmt.Errorf("%s: %s", method, resp.Status)
+	}
+	var answer apiResponse
+	if err = json.Unmarshal(raw, &answer); err != nil {
+		return -1, fmt.Errorf("%s: can't decode the answer: %w", method, err)
+	}
+	if !answer.OK {
+		return -1, fmt.Errorf("%s: %s", method, answer.Error)
+	}
+	if err = json.Unmarshal(raw, result); err != nil {
+		return -1, fmt.Errorf("%s: can't decode the answer: %w", method, err)
+	}
+	return -1, nil
+}
@@ -0,0 +1,114 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slackAPI stands in for the Web API. Each call gets the next of the answers, as a status and a body, and the last
+// answer repeats
+func slackAPI(t *testing.T, answers ...string) (server *httptest.Server, calls *int32, lastBody *string) {
+	calls, lastBody = new(int32), new(string)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(r.Body)
+		*lastBody = r.URL.Path + " " + string(body)
+		n := int(atomic.AddInt32(calls, 1)) - 1
+		if n >= len(answers) {
+			n = len(answers) - 1
+		}
+		switch answers[n] {
+		case "429":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(answers[n]))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return
+}
+
+func TestWebClient_PostMessage(t *testing.T) {
+	ok := `{"ok":true,"channel":"D123","ts":"1503435956.000247"}`
+	tests := []struct {
+		name      string
+		answers   []string
+		wantCalls int32
+		errText   string
+	}{
+		{"Positive", []string{ok}, 1, ""},
+		{"Rate limited", []string{"429", ok}, 2, ""},
+		{"Slack down for a moment", []string{"500", "500", ok}, 3, ""},
+		{"Slack down for good", []string{"500"}, int32(DefaultRetries + 1), "chat.postMessage: 500 Internal Server Error"},
+		{"API error isn't retried", []string{`{"ok":false,"error":"channel_not_found"}`}, 1, "chat.postMessage: channel_not_found"},
+		{"Garbled answer", []string{`not json`}, 1, "can't decode the answer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls, body := slackAPI(t, tt.answers...)
+			target := NewWebClient("xoxb-test", server.URL, time.Millisecond)
+			ts, err := target.PostMessage(context.Background(), "D123", Message{Text: "Your target is Bugs"})
+			require.Equal(t, tt.wantCalls, atomic.LoadInt32(calls))
+			if tt.errText != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errText)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "1503435956.000247", ts)
+			require.Equal(t, `/chat.postMessage {"channel":"D123","text":"Your target is Bugs"}`, *body)
+		})
+	}
+}
+
+func TestWebClient_OpenConversation(t *testing.T) {
+	server, _, body := slackAPI(t, `{"ok":true,"channel":{"id":"D069C7QFK"}}`)
+	target := NewWebClient("xoxb-test", server.URL)
+	channel, err := target.OpenConversation(context.Background(), SlackID("U0G9QF9C6"))
+	require.NoError(t, err)
+	require.Equal(t, "D069C7QFK", channel)
+	var args map[string]string
+	require.NoError(t, json.Unmarshal([]byte((*body)[len("/conversations.open "):]), &args))
+	require.Equal(t, map[string]string{"users": "U0G9QF9C6"}, args)
+}
+
+func TestWebClient_Cancelled(t *testing.T) {
+	server, calls, _ := slackAPI(t, "500")
+	target := NewWebClient("xoxb-test", server.URL, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := target.PostMessage(ctx, "D123", Message{Text: "Never mind"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "gave up waiting to retry")
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, int32(1), atomic.LoadInt32(calls), "No retry once the caller has gone")
+}
+
+func TestRecordingClient(t *testing.T) {
+	target := &RecordingClient{}
+	channel, err := target.OpenConversation(context.Background(), SlackID("UHUNTER"))
+	require.NoError(t, err)
+	_, err = target.PostMessage(context.Background(), channel, Message{Text: "first"})
+	require.NoError(t, err)
+	_, err = target.PostMessage(context.Background(), "Cgeneral", Message{Text: "everyone"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first"}, target.DirectMessages("UHUNTER"))
+	require.Len(t, target.Posted(), 2)
+	require.Equal(t, "Cgeneral", target.Posted("Cgeneral")[0].Message.Channel)
+
+	target.FailWith = context.Canceled
+	_, err = target.OpenConversation(context.Background(), SlackID("UHUNTER"))
+	require.Equal(t, context.Canceled, err)
+}
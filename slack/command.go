@@ -0,0 +1,66 @@
+package slack
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Command is a slash command as Slack posts it, keeping the fields the game has a use for
+type Command struct {
+	Command     string
+	Text        string
+	UserID      SlackID
+	UserName    string
+	ChannelID   string
+	TeamID      string
+	ResponseURL string
+}
+
+// ParseCommand reads a slash command from its form encoded body
+// Errors:
+// -- the body isn't form encoded
+// -- the user_id isn't a valid SlackID
+func ParseCommand(body []byte) (cmd Command, err error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return cmd, fmt.Errorf("ParseCommand: %w", err)
+	}
+	cmd = Command{
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		UserName:    form.Get("user_name"),
+		ChannelID:   form.Get("channel_id"),
+		TeamID:      form.Get("team_id"),
+		ResponseURL: form.Get("response_url"),
+	}
+	if cmd.UserID, err = New(form.Get("user_id")); err != nil {
+		return cmd, fmt.Errorf("ParseCommand: user_id %q: %w", form.Get("user_id"), err)
+	}
+	return cmd, nil
+}
+
+// Args splits the text typed after the command into words
+func (c Command) Args() []string {
+	return strings.Fields(c.Text)
+}
+
+// Response types for a reply to a slash command. Ephemeral replies are only shown to the user who typed it
+const (
+	ResponseEphemeral string = "ephemeral"
+	ResponseInChannel string = "in_channel"
+)
+
+// Message is the reply to a slash command, or a message posted through a Client. Channel is only set for the latter.
+// When there are Blocks, Slack shows them instead of the Text, which is left for notifications
+type Message struct {
+	Channel      string  `json:"channel,omitempty"`
+	ResponseType string  `json:"response_type,omitempty"`
+	Text         string  `json:"text"`
+	Blocks       []Block `json:"blocks,omitempty"`
+}
+
+// Ephemeral formats a reply only the user who typed the command gets to see
+func Ephemeral(format string, args ...interface{}) Message {
+	return Message{ResponseType: ResponseEphemeral, Text: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,41 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	t.Run("Recorded", func(t *testing.T) {
+		cmd, err := ParseCommand([]byte(recordedCommand))
+		require.NoError(t, err)
+		require.Equal(t, Command{
+			Command:     "/webhook-collect",
+			UserID:      SlackID("U2CERLKJA"),
+			UserName:    "roadrunner",
+			ChannelID:   "G8PSS9T3V",
+			TeamID:      "T1DC2JH3J",
+			ResponseURL: "https://hooks.slack.com/commands/T1DC2JH3J/397700885554/96rGlfmibIGlgcZRskXaIFfN",
+		}, cmd)
+		require.Empty(t, cmd.Args())
+	})
+	t.Run("Arguments", func(t *testing.T) {
+		cmd, err := ParseCommand([]byte("command=%2Fwa&user_id=UKILLER&text=+join%20%20lunchtime++"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"join", "lunchtime"}, cmd.Args())
+	})
+	t.Run("Bad user", func(t *testing.T) {
+		_, err := ParseCommand([]byte("command=%2Fwa&user_id=%40roadrunner"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `ParseCommand: user_id "@roadrunner"`)
+	})
+	t.Run("Not a form", func(t *testing.T) {
+		_, err := ParseCommand([]byte("user_id=%zz"))
+		require.Error(t, err)
+	})
+}
+
+func TestEphemeral(t *testing.T) {
+	require.Equal(t, Message{ResponseType: "ephemeral", Text: "Game g started"}, Ephemeral("Game %s started", "g"))
+}
@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of Events API request. Slack sends the url_verification challenge once, when the request URL is set up, and
+// wraps every event subscribed to in an event_callback
+const (
+	EventURLVerification string = "url_verification"
+	EventCallback        string = "event_callback"
+)
+
+// EventMessage is the type of event Slack sends for a message posted in a channel the app is in
+const EventMessage string = "message"
+
+// RetryNumHeader is set on an event Slack sends again, having not heard back in time about an earlier delivery
+const RetryNumHeader string = "X-Slack-Retry-Num"
+
+// EventRequest is what Slack posts to the Events API request URL, keeping the fields the game has a use for
+type EventRequest struct {
+	Type      string       `json:"type"`
+	Challenge string       `json:"challenge"`
+	EventID   string       `json:"event_id"`
+	Event     MessageEvent `json:"event"`
+}
+
+// MessageEvent is a message posted in a channel. Messages that aren't plainly typed by someone, such as edits,
+// joins or bot posts, carry a subtype or a bot ID
+type MessageEvent struct {
+	Type    string  `json:"type"`
+	Subtype string  `json:"subtype"`
+	Channel string  `json:"channel"`
+	User    SlackID `json:"user"`
+	BotID   string  `json:"bot_id"`
+	Text    string  `json:"text"`
+	TS      string  `json:"ts"`
+}
+
+// ParseEvent reads an Events API request from its JSON body
+// Errors:
+// -- the body isn't JSON
+// -- the event's user isn't a valid SlackID
+func ParseEvent(body []byte) (req EventRequest, err error) {
+	if err = json.Unmarshal(body, &req); err != nil {
+		return req, fmt.Errorf("ParseEvent: %w", err)
+	}
+	if user := req.Event.User.ToString(); user != "" {
+		if _, err = New(user); err != nil {
+			return req, fmt.Errorf("ParseEvent: user %q: %w", user, err)
+		}
+	}
+	return req, nil
+}
+
+// Typed says whether the event is a message someone typed, rather than one Slack or a bot posted, or an edit
+func (ev MessageEvent) Typed() bool {
+	return ev.Type == EventMessage && ev.Subtype == "" && ev.BotID == "" && ev.User != ""
+}
+
+// Time is when the message was posted, read from its timestamp. A timestamp that can't be read gives the zero time
+func (ev MessageEvent) Time() time.Time {
+	secs := ev.TS
+	if i := strings.Index(secs, "."); i >= 0 {
+		secs = secs[:i]
+	}
+	unix, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordedEvent is a message.channels event in the shape Slack sends it, trimmed of the fields not used
+const recordedEvent string = `{
+	"token": "XXYYZZ",
+	"team_id": "T123ABC456",
+	"api_app_id": "A123ABC456",
+	"event": {
+		"type": "message",
+		"channel": "C123ABC456",
+		"user": "U123ABC456",
+		"text": "Live long and prospect.",
+		"ts": "1355517523.000005",
+		"event_ts": "1355517523.000005",
+		"channel_type": "channel"
+	},
+	"type": "event_callback",
+	"event_id": "Ev123ABC456",
+	"event_time": 1355517523
+}`
+
+func TestParseEvent(t *testing.T) {
+	t.Run("Recorded", func(t *testing.T) {
+		req, err := ParseEvent([]byte(recordedEvent))
+		require.NoError(t, err)
+		require.Equal(t, EventRequest{
+			Type:    EventCallback,
+			EventID: "Ev123ABC456",
+			Event: MessageEvent{
+				Type:    EventMessage,
+				Channel: "C123ABC456",
+				User:    SlackID("U123ABC456"),
+				Text:    "Live long and prospect.",
+				TS:      "1355517523.000005",
+			},
+		}, req)
+		require.True(t, req.Event.Typed())
+		require.Equal(t, time.Unix(1355517523, 0), req.Event.Time())
+	})
+	t.Run("Challenge", func(t *testing.T) {
+		req, err := ParseEvent([]byte(`{"token": "Jhj5dZrVaK7ZwHHjRyZWjbDl", "challenge": "3eZbrw1aB", "type": "url_verification"}`))
+		require.NoError(t, err)
+		require.Equal(t, EventURLVerification, req.Type)
+		require.Equal(t, "3eZbrw1aB", req.Challenge)
+	})
+	t.Run("Not typed", func(t *testing.T) {
+		for _, ev := range []MessageEvent{
+			{Type: EventMessage, Subtype: "message_changed", User: "U123"},
+			{Type: EventMessage, Subtype: "bot_message", BotID: "B123"},
+			{Type: EventMessage, BotID: "B123", User: "U123"},
+			{Type: "reaction_added", User: "U123"},
+		} {
+			require.False(t, ev.Typed(), "%+v", ev)
+		}
+	})
+	t.Run("Bad timestamp", func(t *testing.T) {
+		require.True(t, MessageEvent{TS: "soon"}.Time().IsZero())
+	})
+	t.Run("Not JSON", func(t *testing.T) {
+		_, err := ParseEvent([]byte(`{nope`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ParseEvent:")
+	})
+	t.Run("Bad user", func(t *testing.T) {
+		_, err := ParseEvent([]byte(`{"type": "event_callback", "event": {"type": "message", "user": "@amy"}}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `ParseEvent: user "@amy"`)
+	})
+}
@@ -0,0 +1,67 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// InteractionBlockActions is the type of interaction Slack posts when someone clicks a button in a message
+const InteractionBlockActions string = "block_actions"
+
+// Interaction is what Slack posts when someone interacts with a message, keeping the fields the game has a use for
+type Interaction struct {
+	Type        string
+	UserID      SlackID
+	UserName    string
+	ChannelID   string
+	ResponseURL string
+	Actions     []Action
+}
+
+// Action is one of the things done in an interaction, such as a button click. The value is the button's own
+type Action struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id"`
+	Value    string `json:"value"`
+}
+
+// interactionPayload is the part of the JSON payload Interaction is read from
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	ResponseURL string   `json:"response_url"`
+	Actions     []Action `json:"actions"`
+}
+
+// ParseInteraction reads an interaction from its form encoded body, which carries it as JSON in the payload field
+// Errors:
+// -- the body isn't form encoded, or the payload isn't JSON
+// -- the user isn't a valid SlackID
+func ParseInteraction(body []byte) (in Interaction, err error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return in, fmt.Errorf("ParseInteraction: %w", err)
+	}
+	var payload interactionPayload
+	if err = json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		return in, fmt.Errorf("ParseInteraction: payload: %w", err)
+	}
+	in = Interaction{
+		Type:        payload.Type,
+		UserName:    payload.User.Username,
+		ChannelID:   payload.Channel.ID,
+		ResponseURL: payload.ResponseURL,
+		Actions:     payload.Actions,
+	}
+	if in.UserID, err = New(payload.User.ID); err != nil {
+		return in, fmt.Errorf("ParseInteraction: user %q: %w", payload.User.ID, err)
+	}
+	return in, nil
+}
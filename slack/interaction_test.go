@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordedInteraction is a block_actions payload in the shape Slack sends it, trimmed of the fields not used
+const recordedInteraction string = `{
+	"type": "block_actions",
+	"user": {"id": "U0CA5", "username": "amy.mcdee", "name": "amy.mcdee", "team_id": "T3MDE"},
+	"api_app_id": "A0CA5",
+	"token": "Shh_its_a_seekrit",
+	"container": {"type": "message", "text": "The contents of the original message where the action originated"},
+	"trigger_id": "12466734323.1395872398",
+	"team": {"id": "T0CAG", "domain": "acme-creamery"},
+	"channel": {"id": "D0CA5", "name": "directmessage"},
+	"response_url": "https://www.postresponsestome.com/T123567/1509734234",
+	"actions": [
+		{
+			"action_id": "wa_assassinated",
+			"block_id": "=qXel",
+			"text": {"type": "plain_text", "text": "I was assassinated", "emoji": true},
+			"value": "lunchtime",
+			"style": "danger",
+			"type": "button",
+			"action_ts": "1548426417.840180"
+		}
+	]
+}`
+
+func TestParseInteraction(t *testing.T) {
+	t.Run("Recorded", func(t *testing.T) {
+		in, err := ParseInteraction([]byte(url.Values{"payload": {recordedInteraction}}.Encode()))
+		require.NoError(t, err)
+		require.Equal(t, Interaction{
+			Type:        InteractionBlockActions,
+			UserID:      SlackID("U0CA5"),
+			UserName:    "amy.mcdee",
+			ChannelID:   "D0CA5",
+			ResponseURL: "https://www.postresponsestome.com/T123567/1509734234",
+			Actions:     []Action{{ActionID: "wa_assassinated", BlockID: "=qXel", Value: "lunchtime"}},
+		}, in)
+	})
+	t.Run("Payload isn't JSON", func(t *testing.T) {
+		_, err := ParseInteraction([]byte("payload=%7Bnope"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ParseInteraction: payload:")
+	})
+	t.Run("No payload", func(t *testing.T) {
+		_, err := ParseInteraction([]byte("command=%2Fwa"))
+		require.Error(t, err)
+	})
+	t.Run("Bad user", func(t *testing.T) {
+		_, err := ParseInteraction([]byte(url.Values{"payload": {`{"type": "block_actions", "user": {"id": "@amy"}}`}}.Encode()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `ParseInteraction: user "@amy"`)
+	})
+	t.Run("Not a form", func(t *testing.T) {
+		_, err := ParseInteraction([]byte("payload=%zz"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PostedMessage is a message a RecordingClient was asked to post
+type PostedMessage struct {
+	Channel string
+	Message Message
+}
+
+// RecordingClient is a Client for tests. It posts nowhere, and records what it was asked to post instead. The direct
+// message channel with a user is "D" followed by their ID. It is safe for concurrent use.
+type RecordingClient struct {
+	// FailWith, when set, fails every call with it
+	FailWith error
+	mu       sync.Mutex
+	posted   []PostedMessage
+}
+
+// OpenConversation mock. Names the channel after the user
+func (rc *RecordingClient) OpenConversation(ctx context.Context, user SlackID) (string, error) {
+	if rc.FailWith != nil {
+		return "", rc.FailWith
+	}
+	return "D" + user.ToString(), nil
+}
+
+// PostMessage mock. Records the message, and numbers it for its timestamp
+func (rc *RecordingClient) PostMessage(ctx context.Context, channel string, msg Message) (string, error) {
+	if rc.FailWith != nil {
+		return "", rc.FailWith
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	msg.Channel = channel
+	rc.posted = append(rc.posted, PostedMessage{Channel: channel, Message: msg})
+	return fmt.Sprintf("%d.000000", len(rc.posted)), nil
+}
+
+// Posted lists the messages posted to the channels given, in the order they were posted. With no channels given,
+// every message is listed
+func (rc *RecordingClient) Posted(channels ...string) (result []PostedMessage) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, p := range rc.posted {
+		if len(channels) == 0 || contains(channels, p.Channel) {
+			result = append(result, p)
+		}
+	}
+	return
+}
+
+// DirectMessages lists the text of the messages posted to a user's direct message channel
+func (rc *RecordingClient) DirectMessages(user SlackID) (texts []string) {
+	for _, p := range rc.Posted("D" + user.ToString()) {
+		texts = append(texts, p.Message.Text)
+	}
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
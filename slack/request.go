@@ -0,0 +1,58 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers Slack signs its requests with
+const (
+	SignatureHeader string = "X-Slack-Signature"
+	TimestampHeader string = "X-Slack-Request-Timestamp"
+)
+
+const (
+	// MaxRequestAge is how far a signed request's timestamp may be from now before it's taken for a replay
+	MaxRequestAge = 5 * time.Minute
+	// signatureVersion prefixes both the signed content and the signature
+	signatureVersion string = "v0"
+)
+
+// ErrUnverified means a request can't be shown to have come from Slack
+var ErrUnverified = errors.New("unverified request")
+
+// Verify checks that a request came from Slack. The signature header must be the HMAC-SHA256 of the version, the
+// timestamp and the raw body, keyed with the app's signing secret, and the timestamp must be within MaxRequestAge
+// of now. Every failure matches ErrUnverified.
+func Verify(secret string, header http.Header, body []byte, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no signing secret to check against", ErrUnverified)
+	}
+	stamp := header.Get(TimestampHeader)
+	seconds, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad %s %q", ErrUnverified, TimestampHeader, stamp)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > MaxRequestAge || age < -MaxRequestAge {
+		return fmt.Errorf("%w: timestamp is %s off", ErrUnverified, age.Round(time.Second))
+	}
+	want := Sign(secret, stamp, body)
+	if !hmac.Equal([]byte(header.Get(SignatureHeader)), []byte(want)) {
+		return fmt.Errorf("%w: signature doesn't match", ErrUnverified)
+	}
+	return nil
+}
+
+// Sign produces the signature Slack sends with a request made at the timestamp, given in seconds since the epoch
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
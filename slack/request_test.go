@@ -0,0 +1,64 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A slash command as recorded from Slack, along with the secret and headers it was signed with
+const (
+	recordedSecret    string = "8f742231b10e8888abcd99yyyzzz85a5"
+	recordedTimestamp string = "1531420618"
+	recordedSignature string = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	recordedCommand   string = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&" +
+		"channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&" +
+		"response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&" +
+		"trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+)
+
+func TestVerify(t *testing.T) {
+	recordedAt := time.Unix(1531420618, 0)
+	header := func(timestamp, signature string) http.Header {
+		h := http.Header{}
+		h.Set(TimestampHeader, timestamp)
+		h.Set(SignatureHeader, signature)
+		return h
+	}
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		body    string
+		now     time.Time
+		wantErr string // "" denotes no error expected
+	}{
+		{"Recorded", recordedSecret, header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt, ""},
+		{"A little late", recordedSecret, header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt.Add(MaxRequestAge), ""},
+		{"Replayed", recordedSecret, header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt.Add(MaxRequestAge + time.Second), "timestamp is 5m1s off"},
+		{"From the future", recordedSecret, header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt.Add(-time.Hour), "timestamp is -1h0m0s off"},
+		{"Tampered body", recordedSecret, header(recordedTimestamp, recordedSignature), recordedCommand + "&text=start", recordedAt, "signature doesn't match"},
+		{"Wrong secret", "not the secret", header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt, "signature doesn't match"},
+		{"Unsigned", recordedSecret, header(recordedTimestamp, ""), recordedCommand, recordedAt, "signature doesn't match"},
+		{"No timestamp", recordedSecret, header("", recordedSignature), recordedCommand, recordedAt, "bad X-Slack-Request-Timestamp"},
+		{"No secret", "", header(recordedTimestamp, recordedSignature), recordedCommand, recordedAt, "no signing secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, []byte(tt.body), tt.now)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, ErrUnverified), "Expected an unverified request. Instead got %v", err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	require.Equal(t, recordedSignature, Sign(recordedSecret, recordedTimestamp, []byte(recordedCommand)))
+}
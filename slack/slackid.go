@@ -12,7 +12,7 @@ type SlackID string
 // It returns whether the string is valid and includes the reason if invalid.
 func Validate(id string) (valid bool, reason error) {
 	valid = true
-	validSlackid := regexp.MustCompile(`^[U|W][a-zA-Z0=9]+`)
+	validSlackid := regexp.MustCompile(`^[UW][a-zA-Z0-9]+$`)
 	if !validSlackid.MatchString(id) {
 		valid = false
 		reason = fmt.Errorf("A valid Slack ID must start with either a 'U' or 'W' and consist of only alphanumberics")
@@ -21,6 +21,12 @@ func TestValidate(t *testing.T) {
 			wantValid: true,
 			wantErr: "",
 		},
+		{
+			name: "Digits are alphanumerics too",
+			args: args{"U2CERLKJA"},
+			wantValid: true,
+			wantErr: "",
+		},
 		{
 			name: "@ prefix should fail",
 			args: args{"@UBVALID"},
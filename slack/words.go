@@ -0,0 +1,52 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// markup matches Slack's angle bracketed markup: mentions, channels and links, each with an optional label
+var markup = regexp.MustCompile(`<([^<>|]*)(?:\|([^<>]*))?>`)
+
+// escapes are the characters Slack escapes in message text
+var escapes = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// Words splits message text into its words, in lower case. Mentions of people and channels are dropped, and links
+// count only for the label they show, so the words are the ones a reader sees typed. Anything that isn't a letter or
+// digit separates words, so "pine-apple's" is "pine", "apple" and "s".
+func Words(text string) []string {
+	text = markup.ReplaceAllStringFunc(text, func(m string) string {
+		parts := markup.FindStringSubmatch(m)
+		if strings.HasPrefix(parts[1], "@") || strings.HasPrefix(parts[1], "#") || strings.HasPrefix(parts[1], "!") {
+			return " "
+		}
+		return " " + parts[2] + " "
+	})
+	return strings.FieldsFunc(strings.ToLower(escapes.Replace(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Says checks whether message text contains a word, whole and regardless of case. A word with several parts, such as
+// "ice-cream", is said when its parts are said one after another
+func Says(text string, word string) bool {
+	want := Words(word)
+	if len(want) == 0 {
+		return false
+	}
+	said := Words(text)
+	for i := 0; i+len(want) <= len(said); i++ {
+		match := true
+		for j := range want {
+			if said[i+j] != want[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
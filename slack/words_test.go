@@ -0,0 +1,38 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	require.Equal(t, []string{"hey", "fancy", "some", "pine", "apple", "s"},
+		Words("Hey <@U0CA5>, fancy some <#C0CA5|lunch> PINE-apple's?"))
+	require.Equal(t, []string{"see", "the", "menu", "or", "this", "a", "b"},
+		Words("See <https://example.com/apple|the menu> or <https://example.com/apple> this: a &amp; b"))
+	require.Empty(t, Words(" -- "))
+}
+
+func TestSays(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		word string
+		says bool
+	}{
+		{"I'd love an apple", "apple", true},
+		{"APPLE!", "apple", true},
+		{"Apple's the best", "apple", true},
+		{"Pineapple, please", "apple", false},
+		{"apples", "apple", false},
+		{"<@UAPPLE> hello", "apple", false},
+		{"<https://apple.com|the shop>", "apple", false},
+		{"<https://shop.com|an apple>", "apple", true},
+		{"Ice cream", "ice-cream", true},
+		{"ice-cream", "Ice Cream", true},
+		{"ice, then cream", "ice-cream", false},
+		{"anything", "", false},
+	} {
+		require.Equal(t, tc.says, Says(tc.text, tc.word), "%q says %q", tc.text, tc.word)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+
+	slack "wordassassin/slack"
+)
+
+const (
+	slackSecretEnvName string = "SLACK_SIGNING_SECRET"
+	slackTokenEnvName  string = "SLACK_BOT_TOKEN"
+	// slackMaxBody bounds how much of a request is read before it's known to be from Slack
+	slackMaxBody int64  = 1 << 20
+	slackUsage   string = "Usage:\n" +
+		"`/wa create <game> <dictionary> <passcode>` starts signing players up for a new game\n" +
+		"`/wa join <game>` signs you up\n" +
+		"`/wa start <game>` deals out targets. Only the creator can\n" +
+		"`/wa target <game>` tells you who you're hunting, and with which word\n" +
+		"`/wa dead <game>` reports that you've been assassinated\n" +
+		"`/wa status <game>` shows how the game is going"
+)
+
+// slackSecret is the signing secret Slack requests are verified with. The Slack routes are only set when there is one
+var slackSecret string
+
+// slackCommand answers the /wa slash command. Requests that can't be shown to come from Slack are turned away.
+// Everything else gets an ephemeral reply, failures included, since Slack only shows the user replies that are OK
+func slackCommand(c echo.Context) error {
+	body, err := verifiedSlackBody(c)
+	if err != nil {
+		logger.Printf("slackCommand: %s", err.Error())
+		return c.String(http.StatusUnauthorized, "Request not verified")
+	}
+	cmd, err := slack.ParseCommand(body)
+	if err != nil {
+		return c.JSON(http.StatusOK, slackReply("ParseCommand", err, ""))
+	}
+	return c.JSON(http.StatusOK, runSlackCommand(c.Request().Context(), cmd))
+}
+
+// verifiedSlackBody reads the body of a request, and checks that Slack signed it
+func verifiedSlackBody(c echo.Context) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request().Body, slackMaxBody))
+	if err != nil {
+		return nil, err
+	}
+	return body, slack.Verify(slackSecret, c.Request().Header, body, time.Now())
+}
+
+// runSlackCommand hands a /wa subcommand to the handler on behalf of the Slack user who typed it
+func runSlackCommand(ctx context.Context, cmd slack.Command) slack.Message {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return slack.Ephemeral(slackUsage)
+	}
+	gameid, user := args[1], cmd.UserID
+	switch strings.ToLower(args[0]) {
+	case "create":
+		if len(args) < 4 {
+			break
+		}
+		err := handler.OnGameCreated(ctx, gameid, user.ToString(), args[2], args[3], cmd.ChannelID)
+		return slackReply("OnGameCreated", err, "Game %s created. Players can sign up with `/wa join %s`", gameid, gameid)
+	case "join":
+		token, err := handler.OnPlayerAdded(ctx, gameid, user.ToString(), cmd.UserName, "")
+		return slackReply("OnPlayerAdded", err, "You're in game %s. Your player token is %s. Keep it secret", gameid, token)
+	case "start":
+		err := handler.OnGameStarted(ctx, gameid, user.ToString())
+		return slackReply("OnGameStarted", err, "Game %s started. Players can see their targets with `/wa target %s`", gameid, gameid)
+	case "target":
+		target, err := handler.GetSlackTarget(ctx, gameid, user)
+		return slackReply("GetSlackTarget", err, "Your target is %s. Your kill word is *%s*", target.TargetName, target.KillWord)
+	case "dead":
+		err := handler.OnKillReported(ctx, gameid, user.ToString())
+		return slackReply("OnKillReported", err, "Your death in game %s is recorded. Better luck next time", gameid)
+	case "status":
+		report, exists := handler.GetGameStatus(gameid)
+		if !exists {
+			return slack.Ephemeral("Game %s not found", gameid)
+		}
+		return slack.Ephemeral("```%s```", report)
+	}
+	return slack.Ephemeral(slackUsage)
+}
+
+// slackReply words the reply to a subcommand: what went wrong if it failed, otherwise the message
+func slackReply(op string, err error, format string, args ...interface{}) slack.Message {
+	if err != nil {
+		logger.Printf("%s error: %s", op, err.Error())
+		return slack.Ephemeral("Sorry, that didn't work. %s", err.Error())
+	}
+	return slack.Ephemeral(format, args...)
+}
+
+func setSlackRoutes(e *echo.Echo) {
+	e.POST("/slack/command", slackCommand)
+	e.POST("/slack/interactions", slackInteraction)
+	e.POST("/slack/events", slackEvent)
+}
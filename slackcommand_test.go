@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+
+	slack "wordassassin/slack"
+)
+
+const testSlackSecret string = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func TestSlackCommand(t *testing.T) {
+	e := getSlackServerWithMocks(t)
+	run := func(user string, text string) slack.Message {
+		rec := postSlackCommand(e, user, text, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var reply slack.Message
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reply), rec.Body.String())
+		require.Equal(t, slack.ResponseEphemeral, reply.ResponseType, "Only the player who typed it sees the reply")
+		return reply
+	}
+
+	reply := run("UBOSS", "create lunchtime afile.txt sesame")
+	require.Equal(t, "Game lunchtime created. Players can sign up with `/wa join lunchtime`", reply.Text)
+	reply = run("UBOSS", "create lunchtime afile.txt sesame")
+	require.Contains(t, reply.Text, "Sorry, that didn't work. OnGameCreated:")
+	players := []string{"UBOSS", "UHIT1", "UHIT2", "UHIT3", "UHIT4"}
+	for _, p := range players {
+		reply = run(p, "join lunchtime")
+		require.Contains(t, reply.Text, "You're in game lunchtime. Your player token is ")
+	}
+	reply = run("UHIT1", "start lunchtime")
+	require.Contains(t, reply.Text, "cannot be started by non-creator", "Only the creator starts the game")
+	reply = run("UBOSS", "start lunchtime")
+	require.Equal(t, "Game lunchtime started. Players can see their targets with `/wa target lunchtime`", reply.Text)
+
+	reply = run("UHIT1", "target lunchtime")
+	require.Regexp(t, `^Your target is u\w+\. Your kill word is \*word\d+\*$`, reply.Text, "No token needed from Slack")
+	reply = run("UHIT1", "dead lunchtime")
+	require.Equal(t, "Your death in game lunchtime is recorded. Better luck next time", reply.Text)
+	reply = run("UHIT1", "target lunchtime")
+	require.Contains(t, reply.Text, "UHIT1 is no longer alive in game lunchtime")
+	reply = run("UHIT2", "STATUS lunchtime")
+	require.Contains(t, reply.Text, "```")
+	require.Contains(t, reply.Text, "lunchtime")
+	reply = run("UHIT2", "status nogame")
+	require.Equal(t, "Game nogame not found", reply.Text)
+
+	for _, text := range []string{"", "status", "create lunchtime", "dance lunchtime"} {
+		reply = run("UHIT2", text)
+		require.Equal(t, slackUsage, reply.Text, "%q gets the usage", text)
+	}
+	reply = run("UBOSS", "create openhouse afile.txt")
+	require.Equal(t, slackUsage, reply.Text, "A game needs a passcode")
+	_, exists := handler.GetGameStatus("openhouse")
+	require.False(t, exists)
+}
+
+func TestSlackCommand_Unverified(t *testing.T) {
+	e := getSlackServerWithMocks(t)
+	tests := []struct {
+		name   string
+		at     time.Time
+		secret string
+	}{
+		{"Wrong secret", time.Now(), "not the secret"},
+		{"Replayed", time.Now().Add(-slack.MaxRequestAge - time.Minute), testSlackSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postSlackCommand(e, "UBOSS", "create sneaky afile.txt", tt.at, tt.secret)
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+			_, exists := handler.GetGameStatus("sneaky")
+			require.False(t, exists, "Nothing is done for a request that isn't verified")
+		})
+	}
+	t.Run("Bad user", func(t *testing.T) {
+		rec := postSlackCommand(e, "@roadrunner", "join lunchtime", time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Sorry, that didn't work. ParseCommand: user_id")
+	})
+}
+
+// getSlackServerWithMocks is getServerWithMocks with the Slack routes, and the test signing secret
+func getSlackServerWithMocks(t *testing.T) *echo.Echo {
+	e, _ := getServerWithMocks(t)
+	slackSecret = testSlackSecret
+	setSlackRoutes(e)
+	return e
+}
+
+// postSlackCommand sends /wa with the text as Slack would, signed with the secret at the given time
+func postSlackCommand(e *echo.Echo, user string, text string, at time.Time, secret string) *httptest.ResponseRecorder {
+	body := url.Values{
+		"token":        {"xyzz0WbapA4vBCDEFasx0q6G"},
+		"team_id":      {"T1DC2JH3J"},
+		"channel_id":   {"C2147483705"},
+		"user_id":      {user},
+		"user_name":    {strings.ToLower(user)},
+		"command":      {"/wa"},
+		"text":         {text},
+		"response_url": {"https://hooks.slack.com/commands/T1DC2JH3J/397700885554/96rGlfmibIGlgcZRskXaIFfN"},
+	}.Encode()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/slack/command", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(slack.TimestampHeader, timestamp)
+	req.Header.Set(slack.SignatureHeader, slack.Sign(secret, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+
+	slack "wordassassin/slack"
+)
+
+// slackEvent receives the Events API requests Slack posts for the channels the app is in. It answers the challenge
+// Slack sets when the request URL is configured, and hands messages people type to the handler to look for kill
+// words. Requests that can't be shown to come from Slack are turned away. Slack sends an event again if it doesn't
+// hear back quickly, so retried deliveries are acknowledged without being looked at twice
+func slackEvent(c echo.Context) error {
+	body, err := verifiedSlackBody(c)
+	if err != nil {
+		logger.Printf("slackEvent: %s", err.Error())
+		return c.String(http.StatusUnauthorized, "Request not verified")
+	}
+	req, err := slack.ParseEvent(body)
+	if err != nil {
+		logger.Printf("slackEvent: %s", err.Error())
+		return c.String(http.StatusBadRequest, "Event not understood")
+	}
+	switch {
+	case req.Type == slack.EventURLVerification:
+		return c.JSON(http.StatusOK, map[string]string{"challenge": req.Challenge})
+	case req.Type != slack.EventCallback || !req.Event.Typed():
+	case c.Request().Header.Get(slack.RetryNumHeader) != "":
+		logger.Printf("slackEvent: ignoring retried delivery of %s", req.EventID)
+	default:
+		at := req.Event.Time()
+		if at.IsZero() {
+			at = time.Now()
+		}
+		if err = handler.OnMessagePosted(c.Request().Context(), req.Event.Channel, req.Event.User, req.Event.Text, at); err != nil {
+			logger.Printf("slackEvent: %s", err.Error())
+		}
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+
+	slack "wordassassin/slack"
+	types "wordassassin/types"
+)
+
+func TestSlackEvent(t *testing.T) {
+	dms := &slack.RecordingClient{}
+	e := getSlackServerWithMocks(t)
+	handler = NewHandler(handler.gPool, handler.mongo, logger, dms)
+	for _, text := range []string{"create teatime afile.txt sesame", "join teatime"} {
+		postSlackCommand(e, "UBOSS", text, time.Now(), testSlackSecret)
+	}
+	for _, p := range []string{"UHIT1", "UHIT2", "UHIT3", "UHIT4"} {
+		postSlackCommand(e, p, "join teatime", time.Now(), testSlackSecret)
+	}
+	postSlackCommand(e, "UBOSS", "start teatime", time.Now(), testSlackSecret)
+	handler.FlushMessages()
+	var assassin *types.Player
+	players, _ := handler.gPool.GetPlayers(context.Background(), "teatime")
+	for _, p := range players {
+		if p.Target == "teatime+UHIT1" {
+			assassin = p
+		}
+	}
+	require.NotNil(t, assassin)
+	word := assassin.KillWord
+	now := time.Now()
+	say := func(user slack.SlackID, channel string, text string, at time.Time) {
+		rec := postSlackMessage(e, user.ToString(), channel, text, at, testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		handler.FlushMessages()
+	}
+	asked := func() (texts []string) {
+		for _, text := range dms.DirectMessages("UHIT1") {
+			if strings.HasPrefix(text, "You said") {
+				texts = append(texts, text)
+			}
+		}
+		return texts
+	}
+
+	t.Run("Assassin not around", func(t *testing.T) {
+		say("UHIT1", "CKITCHEN", "Anyone for "+word+"?", now)
+		say(assassin.SlackID, "CKITCHEN", "Morning all", now.Add(-presenceWindow-time.Minute))
+		say("UHIT1", "CKITCHEN", "Anyone for "+word+"?", now)
+		say(assassin.SlackID, "CLOUNGE", "Morning all", now)
+		say("UHIT1", "CKITCHEN", "Anyone for "+word+"?", now)
+		require.Empty(t, asked(), "The assassin hasn't been in the kitchen lately")
+	})
+	t.Run("Word not said", func(t *testing.T) {
+		say(assassin.SlackID, "CKITCHEN", "Morning all", now)
+		say("UHIT1", "CKITCHEN", "Anyone for pine"+word+"?", now)
+		say("UHIT1", "CKITCHEN", word+"s all round", now)
+		require.Empty(t, asked(), "Only the whole word counts")
+	})
+	t.Run("Retries and bots ignored", func(t *testing.T) {
+		body := slackMessageEvent("UHIT1", "CKITCHEN", word, now)
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(slack.TimestampHeader, timestamp)
+		req.Header.Set(slack.SignatureHeader, slack.Sign(testSlackSecret, timestamp, []byte(body)))
+		req.Header.Set(slack.RetryNumHeader, "1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		bot := fmt.Sprintf(`{"type": "event_callback", "event": {"type": "message", "subtype": "bot_message", "bot_id": "B1", "user": "UHIT1", "channel": "CKITCHEN", "text": "%s"}}`, word)
+		require.Equal(t, http.StatusOK, postSlackEvent(e, bot, time.Now(), testSlackSecret).Code)
+		handler.FlushMessages()
+		require.Empty(t, asked())
+	})
+	t.Run("Victim gives themselves away", func(t *testing.T) {
+		say("UHIT1", "CKITCHEN", "Well, "+strings.ToUpper(word)+", obviously", now.Add(time.Minute))
+		require.Equal(t, []string{fmt.Sprintf("You said *%s* in <#CKITCHEN> with <@%s> around. Were you assassinated in game teatime?", word, assassin.SlackID)}, asked())
+		sent := dms.Posted("DUHIT1")
+		button := sent[len(sent)-1].Message.Blocks[1].Elements[0].(slack.Button)
+		require.Equal(t, actionConfirmKill, button.ActionID)
+		require.Equal(t, "teatime+UHIT1", button.Value)
+		say("UHIT1", "CKITCHEN", "I said "+word, now.Add(2*time.Minute))
+		require.Len(t, asked(), 1, "Not asked again while the kill is waiting")
+	})
+	t.Run("Only the victim confirms", func(t *testing.T) {
+		rec := postSlackAction(e, assassin.SlackID.ToString(), actionConfirmKill, "teatime+UHIT1", time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code)
+		handler.FlushMessages()
+		sent := dms.DirectMessages(assassin.SlackID)
+		require.Contains(t, sent[len(sent)-1], "Sorry, that didn't work. OnKillConfirmed: No kill of UHIT1 in game teatime is waiting on "+assassin.SlackID.ToString())
+	})
+	t.Run("Victim confirms", func(t *testing.T) {
+		rec := postSlackAction(e, "UHIT1", actionConfirmKill, "teatime+UHIT1", time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code)
+		handler.FlushMessages()
+		victim, err := handler.gPool.GetPlayer(context.Background(), "teatime+UHIT1")
+		require.NoError(t, err)
+		require.False(t, victim.IsAlive())
+		require.Equal(t, assassin.GetID(), victim.KilledBy)
+		sent := dms.DirectMessages("UHIT1")
+		require.Equal(t, "Death confirmed in game teatime. Better luck next time", sent[len(sent)-1])
+		require.Contains(t, dms.DirectMessages(assassin.SlackID), "<@UHIT1> confirmed their death in game teatime. Well played")
+	})
+	t.Run("The dead give nothing away", func(t *testing.T) {
+		say("UHIT1", "CKITCHEN", word, now.Add(3*time.Minute))
+		require.Len(t, asked(), 1)
+	})
+	t.Run("Challenge", func(t *testing.T) {
+		rec := postSlackEvent(e, `{"token": "Jhj5dZrVaK7ZwHHjRyZWjbDl", "challenge": "3eZbrw1aB", "type": "url_verification"}`, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"challenge": "3eZbrw1aB"}`, rec.Body.String())
+	})
+	t.Run("Not understood", func(t *testing.T) {
+		rec := postSlackEvent(e, `nope`, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+	t.Run("Unverified", func(t *testing.T) {
+		rec := postSlackMessage(e, "UHIT2", "CKITCHEN", "hello", time.Now(), "not the secret")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+// postSlackMessage sends a message.channels event as Slack would, signed with the secret
+func postSlackMessage(e *echo.Echo, user string, channel string, text string, at time.Time, secret string) *httptest.ResponseRecorder {
+	return postSlackEvent(e, slackMessageEvent(user, channel, text, at), time.Now(), secret)
+}
+
+// slackMessageEvent makes the body of a message.channels event for a message posted at the given time
+func slackMessageEvent(user string, channel string, text string, at time.Time) string {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":     slack.EventCallback,
+		"event_id": "Ev" + strconv.FormatInt(at.UnixNano(), 36),
+		"event": map[string]string{
+			"type":         slack.EventMessage,
+			"channel":      channel,
+			"user":         user,
+			"text":         text,
+			"ts":           fmt.Sprintf("%d.000100", at.Unix()),
+			"channel_type": "channel",
+		},
+	})
+	return string(payload)
+}
+
+// postSlackEvent sends an Events API request as Slack would, signed with the secret at the given time
+func postSlackEvent(e *echo.Echo, body string, at time.Time, secret string) *httptest.ResponseRecorder {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(slack.TimestampHeader, timestamp)
+	req.Header.Set(slack.SignatureHeader, slack.Sign(secret, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
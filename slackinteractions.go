@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	slack "wordassassin/slack"
+)
+
+// Action IDs of the buttons in the direct messages the game sends
+const (
+	// actionAssassinated is the victim's button. Its value is the game
+	actionAssassinated string = "wa_assassinated"
+	// actionConfirmKill confirms a kill waiting on whoever clicks it, the assassin or, for a kill spotted in a
+	// channel, the victim. Its value is the victim's player ID
+	actionConfirmKill string = "wa_confirm_kill"
+)
+
+// slackInteraction answers clicks on the buttons in the messages the game sends. Requests that can't be shown to
+// come from Slack are turned away. Slack takes nothing back for a click but an OK, so the clicker hears how it went
+// in a direct message
+func slackInteraction(c echo.Context) error {
+	body, err := verifiedSlackBody(c)
+	if err != nil {
+		logger.Printf("slackInteraction: %s", err.Error())
+		return c.String(http.StatusUnauthorized, "Request not verified")
+	}
+	in, err := slack.ParseInteraction(body)
+	if err != nil {
+		logger.Printf("slackInteraction: %s", err.Error())
+		return c.String(http.StatusBadRequest, "Interaction not understood")
+	}
+	if in.Type == slack.InteractionBlockActions {
+		for _, action := range in.Actions {
+			runSlackAction(c.Request().Context(), in.UserID, action)
+		}
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// runSlackAction hands a button click to the handler on behalf of the Slack user who clicked it
+func runSlackAction(ctx context.Context, user slack.SlackID, action slack.Action) {
+	switch action.ActionID {
+	case actionAssassinated:
+		gameid := action.Value
+		err := handler.OnDeathClaimed(ctx, gameid, user)
+		handler.Tell(ctx, user, slackReply("OnDeathClaimed", err, "Your assassin has been asked to confirm your death in game %s", gameid).Text)
+	case actionConfirmKill:
+		gameid, victim := action.Value, ""
+		if i := strings.LastIndex(action.Value, "+"); i >= 0 {
+			gameid, victim = action.Value[:i], action.Value[i+1:]
+		}
+		err := handler.OnKillConfirmed(ctx, gameid, slack.SlackID(victim), user)
+		if user == slack.SlackID(victim) {
+			handler.Tell(ctx, user, slackReply("OnKillConfirmed", err, "Death confirmed in game %s. Better luck next time", gameid).Text)
+		} else {
+			handler.Tell(ctx, user, slackReply("OnKillConfirmed", err, "Kill confirmed in game %s. Well played", gameid).Text)
+		}
+	default:
+		logger.Printf("runSlackAction: %s clicked unknown action %q", user, action.ActionID)
+	}
+}
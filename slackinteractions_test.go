@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+
+	slack "wordassassin/slack"
+	types "wordassassin/types"
+)
+
+func TestSlackInteraction(t *testing.T) {
+	dms := &slack.RecordingClient{}
+	e := getSlackServerWithMocks(t)
+	handler = NewHandler(handler.gPool, handler.mongo, logger, dms)
+	for _, text := range []string{"create lunchtime afile.txt sesame", "join lunchtime"} {
+		postSlackCommand(e, "UBOSS", text, time.Now(), testSlackSecret)
+	}
+	for _, p := range []string{"UHIT1", "UHIT2", "UHIT3", "UHIT4"} {
+		postSlackCommand(e, p, "join lunchtime", time.Now(), testSlackSecret)
+	}
+	postSlackCommand(e, "UBOSS", "start lunchtime", time.Now(), testSlackSecret)
+	click := func(user string, actionID string, value string) {
+		rec := postSlackAction(e, user, actionID, value, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		handler.FlushMessages()
+	}
+	lastDM := func(user slack.SlackID) slack.Message {
+		sent := dms.Posted("D" + user.ToString())
+		require.NotEmpty(t, sent, "%s was sent nothing", user)
+		return sent[len(sent)-1].Message
+	}
+	player := func(slackid string) *types.Player {
+		p, err := handler.gPool.GetPlayer(context.Background(), "lunchtime+"+slackid)
+		require.NoError(t, err)
+		return p
+	}
+	handler.FlushMessages()
+	targetDM := lastDM("UHIT1")
+	require.Equal(t, "actions", targetDM.Blocks[2].Type, "The target comes with the button to claim a death")
+	button := targetDM.Blocks[2].Elements[0].(slack.Button)
+	require.Equal(t, actionAssassinated, button.ActionID)
+	require.Equal(t, "lunchtime", button.Value)
+
+	var assassin *types.Player
+	players, _ := handler.gPool.GetPlayers(context.Background(), "lunchtime")
+	for _, p := range players {
+		if p.Target == "lunchtime+UHIT1" {
+			assassin = p
+		}
+	}
+	require.NotNil(t, assassin)
+
+	t.Run("Victim claims the kill", func(t *testing.T) {
+		click("UHIT1", button.ActionID, button.Value)
+		require.Equal(t, "Your assassin has been asked to confirm your death in game lunchtime", lastDM("UHIT1").Text)
+		require.True(t, player("UHIT1").IsAlive(), "Not dead until the assassin confirms")
+		confirm := lastDM(assassin.SlackID)
+		require.Equal(t, "<@UHIT1> says you assassinated them in game lunchtime. Confirm the kill?", confirm.Text)
+		confirmButton := confirm.Blocks[1].Elements[0].(slack.Button)
+		require.Equal(t, actionConfirmKill, confirmButton.ActionID)
+		require.Equal(t, "lunchtime+UHIT1", confirmButton.Value)
+	})
+	t.Run("Only the assassin confirms", func(t *testing.T) {
+		bystander := "UHIT2"
+		if assassin.SlackID == "UHIT2" {
+			bystander = "UHIT3"
+		}
+		click(bystander, actionConfirmKill, "lunchtime+UHIT1")
+		require.Contains(t, lastDM(slack.SlackID(bystander)).Text, "Sorry, that didn't work. OnKillConfirmed: No kill of UHIT1 in game lunchtime is waiting on "+bystander)
+		require.True(t, player("UHIT1").IsAlive())
+	})
+	t.Run("Assassin confirms the kill", func(t *testing.T) {
+		click(assassin.SlackID.ToString(), actionConfirmKill, "lunchtime+UHIT1")
+		victim := player("UHIT1")
+		require.False(t, victim.IsAlive(), "Dead once the assassin confirms")
+		require.Equal(t, assassin.GetID(), victim.KilledBy)
+		require.Equal(t, "<@"+assassin.SlackID.ToString()+"> confirmed your death in game lunchtime. Better luck next time", lastDM("UHIT1").Text)
+		var texts []string
+		targets := 0
+		for _, p := range dms.Posted("D" + assassin.SlackID.ToString()) {
+			texts = append(texts, p.Message.Text)
+			if strings.HasPrefix(p.Message.Text, "Game lunchtime: your target is") {
+				targets++
+			}
+		}
+		require.Contains(t, texts, "Kill confirmed in game lunchtime. Well played")
+		require.Equal(t, 2, targets, "The assassin gets their new target too")
+	})
+	t.Run("A kill is only confirmed once", func(t *testing.T) {
+		click(assassin.SlackID.ToString(), actionConfirmKill, "lunchtime+UHIT1")
+		require.Contains(t, lastDM(assassin.SlackID).Text, "Sorry, that didn't work. OnKillConfirmed: No kill of UHIT1")
+	})
+	t.Run("The dead can't claim again", func(t *testing.T) {
+		click("UHIT1", actionAssassinated, "lunchtime")
+		require.Contains(t, lastDM("UHIT1").Text, "Sorry, that didn't work. OnDeathClaimed: UHIT1 is no longer alive in game lunchtime")
+	})
+	t.Run("Not a button click", func(t *testing.T) {
+		before := len(dms.Posted())
+		rec := postSlackInteraction(e, `{"type": "view_closed", "user": {"id": "UHIT2"}}`, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusOK, rec.Code)
+		handler.FlushMessages()
+		require.Len(t, dms.Posted(), before, "Other interactions are ignored")
+	})
+	t.Run("Not understood", func(t *testing.T) {
+		rec := postSlackInteraction(e, `nope`, time.Now(), testSlackSecret)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+	t.Run("Unverified", func(t *testing.T) {
+		rec := postSlackAction(e, "UHIT2", actionAssassinated, "lunchtime", time.Now(), "not the secret")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+// postSlackAction sends a button click as Slack would, signed with the secret at the given time
+func postSlackAction(e *echo.Echo, user string, actionID string, value string, at time.Time, secret string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":         slack.InteractionBlockActions,
+		"user":         map[string]string{"id": user, "username": strings.ToLower(user)},
+		"channel":      map[string]string{"id": "D" + user},
+		"response_url": "https://hooks.slack.com/actions/T1DC2JH3J/397700885554/96rGlfmibIGlgcZRskXaIFfN",
+		"actions":      []map[string]string{{"action_id": actionID, "block_id": "b1", "value": value, "type": "button"}},
+	})
+	return postSlackInteraction(e, string(payload), at, secret)
+}
+
+// postSlackInteraction sends an interaction payload as Slack would, signed with the secret at the given time
+func postSlackInteraction(e *echo.Echo, payload string, at time.Time, secret string) *httptest.ResponseRecorder {
+	body := url.Values{"payload": {payload}}.Encode()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(slack.TimestampHeader, timestamp)
+	req.Header.Set(slack.SignatureHeader, slack.Sign(secret, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,250 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DictionaryFormat names a file layout that dictionaries can be imported from and exported to
+type DictionaryFormat string
+
+// Constants for DictionaryFormat
+const (
+	// TextFormat is one word per line. Blank lines and lines starting with '#' are skipped
+	TextFormat DictionaryFormat = "text"
+	// CSVFormat is word[,category[,difficulty]] per row, with an optional header row starting with "word"
+	CSVFormat DictionaryFormat = "csv"
+	// JSONFormat is an array of either plain strings or {"word", "category", "difficulty"} objects
+	JSONFormat DictionaryFormat = "json"
+)
+
+// ImportResult reports the outcome of a bulk import
+type ImportResult struct {
+	Added    int      `json:"added"`
+	Rejected []string `json:"rejected"`
+}
+
+// importLine is a word read from an import source, along with where it came from for error reporting. A reason
+// is set when the line couldn't be parsed at all.
+type importLine struct {
+	line       int
+	word       string
+	category   string
+	difficulty string
+	reason     string
+}
+
+// ParseDictionaryFormat validates a format name. Blank defaults to TextFormat.
+func ParseDictionaryFormat(name string) (DictionaryFormat, error) {
+	switch f := DictionaryFormat(strings.ToLower(strings.TrimSpace(name))); f {
+	case "", "txt", TextFormat:
+		return TextFormat, nil
+	case CSVFormat, JSONFormat:
+		return f, nil
+	}
+	return "", errorf(ErrInvalidArgument, "Unknown dictionary format: %s. Use one of text, csv or json", name)
+}
+
+// Import reads words in the given format and adds them to the dictionary in a single batch write. Lines that can't
+// be parsed, fail KillWord validation, repeat a word already in the dictionary or get rejected by mongo are
+// reported back by line number. The remaining words are still added.
+// Errors:
+//   the source can't be read or parsed as a whole
+//   mongo issue with the batch
+func (kd *KillDictionary) Import(ctx context.Context, r io.Reader, format DictionaryFormat) (result ImportResult, err error) {
+	var lines []importLine
+	switch format {
+	case TextFormat:
+		lines, err = parseTextLines(r)
+	case CSVFormat:
+		lines, err = parseCSVLines(r)
+	case JSONFormat:
+		lines, err = parseJSONLines(r)
+	default:
+		err = fmt.Errorf("Unknown dictionary format: %s", format)
+	}
+	if err != nil {
+		return result, errorf(ErrInvalidArgument, "Import: %w", err)
+	}
+
+	existing := make(map[string]bool, len(kd.words))
+	for _, w := range kd.words {
+		existing[w] = true
+	}
+	batch := make([]*KillWord, 0, len(lines))
+	batchLines := make([]importLine, 0, len(lines))
+	for _, l := range lines {
+		if l.reason != "" {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("line %d: %s", l.line, l.reason))
+			continue
+		}
+		kw, kwErr := NewKillWord(kd.ID, l.word)
+		if kwErr != nil {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("line %d: %v", l.line, kwErr))
+			continue
+		}
+		if existing[l.word] {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("line %d: %s is a duplicate", l.line, l.word))
+			continue
+		}
+		kw.Category = l.category
+		if l.difficulty != "" {
+			if kw.Difficulty, kwErr = strconv.Atoi(l.difficulty); kwErr != nil {
+				result.Rejected = append(result.Rejected, fmt.Sprintf("line %d: difficulty %s is not a number", l.line, l.difficulty))
+				continue
+			}
+		}
+		existing[l.word] = true
+		batch = append(batch, &kw)
+		batchLines = append(batchLines, l)
+	}
+
+	failed, err := kd.repo.SaveMany(ctx, batch)
+	if err != nil {
+		return result, fmt.Errorf("Import: %w", err)
+	}
+	for i, l := range batchLines {
+		if writeErr, exists := failed[i]; exists {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("line %d: %v", l.line, writeErr))
+			continue
+		}
+		kd.words = append(kd.words, l.word)
+		result.Added++
+	}
+	return result, nil
+}
+
+// Export writes every word in the dictionary, along with any category and difficulty, in the given format. Words
+// are read back from mongo so the extra details come along, and are written in alphabetical order.
+func (kd *KillDictionary) Export(ctx context.Context, w io.Writer, format DictionaryFormat) error {
+	words, err := kd.KillWords(ctx)
+	if err != nil {
+		return fmt.Errorf("Export: %w", err)
+	}
+	switch format {
+	case TextFormat:
+		for _, kw := range words {
+			if _, err = fmt.Fprintln(w, kw.Word); err != nil {
+				return fmt.Errorf("Export: %w", err)
+			}
+		}
+	case CSVFormat:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"word", "category", "difficulty"})
+		for _, kw := range words {
+			difficulty := ""
+			if kw.Difficulty != 0 {
+				difficulty = strconv.Itoa(kw.Difficulty)
+			}
+			cw.Write([]string{kw.Word, kw.Category, difficulty})
+		}
+		cw.Flush()
+		if err = cw.Error(); err != nil {
+			return fmt.Errorf("Export: %w", err)
+		}
+	case JSONFormat:
+		type exportWord struct {
+			Word       string `json:"word"`
+			Category   string `json:"category,omitempty"`
+			Difficulty int    `json:"difficulty,omitempty"`
+		}
+		out := make([]exportWord, len(words))
+		for i, kw := range words {
+			out[i] = exportWord{kw.Word, kw.Category, kw.Difficulty}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(out); err != nil {
+			return fmt.Errorf("Export: %w", err)
+		}
+	default:
+		return errorf(ErrInvalidArgument, "Export: Unknown dictionary format: %s", format)
+	}
+	return nil
+}
+
+// KillWords fetches the full persisted record of every word in this dictionary, sorted by word
+func (kd *KillDictionary) KillWords(ctx context.Context) ([]KillWord, error) {
+	return kd.repo.Find(ctx, kd.ID)
+}
+
+func parseTextLines(r io.Reader) (lines []importLine, err error) {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		lines = append(lines, importLine{line: n, word: word})
+	}
+	return lines, scanner.Err()
+}
+
+func parseCSVLines(r io.Reader) (lines []importLine, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	for row := 1; ; row++ {
+		rec, readErr := cr.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if parseErr, ok := readErr.(*csv.ParseError); ok {
+			lines = append(lines, importLine{line: parseErr.Line, reason: parseErr.Err.Error()})
+			continue
+		} else if readErr != nil {
+			return nil, readErr
+		}
+		fields := make([]string, 3)
+		copy(fields, rec)
+		l := importLine{
+			line:       row,
+			word:       strings.TrimSpace(fields[0]),
+			category:   strings.TrimSpace(fields[1]),
+			difficulty: strings.TrimSpace(fields[2]),
+		}
+		if row == 1 && strings.EqualFold(l.word, "word") {
+			continue
+		}
+		if l.word == "" && l.category == "" && l.difficulty == "" {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func parseJSONLines(r io.Reader) (lines []importLine, err error) {
+	var items []json.RawMessage
+	if err = json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("expected a JSON array: %w", err)
+	}
+	for i, item := range items {
+		l := importLine{line: i + 1}
+		var word string
+		if json.Unmarshal(item, &word) == nil {
+			l.word = strings.TrimSpace(word)
+			lines = append(lines, l)
+			continue
+		}
+		var obj struct {
+			Word       string      `json:"word"`
+			Category   string      `json:"category"`
+			Difficulty json.Number `json:"difficulty"`
+		}
+		if decErr := json.Unmarshal(item, &obj); decErr != nil {
+			l.reason = fmt.Sprintf("expected a word or a word object: %v", decErr)
+		}
+		l.word = strings.TrimSpace(obj.Word)
+		l.category = strings.TrimSpace(obj.Category)
+		l.difficulty = obj.Difficulty.String()
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
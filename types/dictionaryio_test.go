@@ -0,0 +1,208 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dao "wordassassin/persistence"
+)
+
+func TestParseDictionaryFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    DictionaryFormat
+		wantErr bool
+	}{
+		{"Blank defaults to text", "", TextFormat, false},
+		{"txt alias", "txt", TextFormat, false},
+		{"Upper case csv", "CSV", CSVFormat, false},
+		{"json", "json", JSONFormat, false},
+		{"Unknown", "xml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDictionaryFormat(tt.format)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "Unknown dictionary format")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKillDictionary_Import(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       DictionaryFormat
+		source       string
+		existing     []string
+		duplicateIDs []string
+		writeMode    string
+		wantAdded    []string
+		wantRejected []string
+		wantErr      string
+	}{
+		{
+			name:      "Text skips blanks and comments",
+			format:    TextFormat,
+			source:    "# animals\nbadger\n\n  walrus  \nox\n",
+			wantAdded: []string{"badger", "walrus"},
+			wantRejected: []string{
+				"line 5: ox does not meet the minimum char length",
+			},
+		},
+		{
+			name:      "Text repeats and existing words",
+			format:    TextFormat,
+			source:    "badger\nwalrus\nbadger\n",
+			existing:  []string{"walrus"},
+			wantAdded: []string{"badger"},
+			wantRejected: []string{
+				"line 2: walrus is a duplicate",
+				"line 3: badger is a duplicate",
+			},
+		},
+		{
+			name:      "CSV with header, category and difficulty",
+			format:    CSVFormat,
+			source:    "word,category,difficulty\nbadger,animal,2\nteapot\nwalrus,animal,hard\n",
+			wantAdded: []string{"badger", "teapot"},
+			wantRejected: []string{
+				"line 4: difficulty hard is not a number",
+			},
+		},
+		{
+			name:      "CSV parse error",
+			format:    CSVFormat,
+			source:    "badger\n\"teapot\nwalrus\n",
+			wantAdded: []string{"badger"},
+			wantRejected: []string{
+				"extraneous or missing \" in quoted-field",
+			},
+		},
+		{
+			name:      "JSON strings and objects",
+			format:    JSONFormat,
+			source:    `["badger", {"word": "teapot", "category": "kitchen", "difficulty": 3}, 42]`,
+			wantAdded: []string{"badger", "teapot"},
+			wantRejected: []string{
+				"line 3: expected a word or a word object",
+			},
+		},
+		{
+			name:    "JSON not an array",
+			format:  JSONFormat,
+			source:  `{"word": "badger"}`,
+			wantErr: "expected a JSON array",
+		},
+		{
+			name:         "Batch duplicates in mongo",
+			format:       TextFormat,
+			source:       "badger\nteapot\nwalrus\n",
+			duplicateIDs: []string{"importer+teapot"},
+			writeMode:    "duplicate",
+			wantAdded:    []string{"badger", "walrus"},
+			wantRejected: []string{
+				"line 2: Mock duplicate on write",
+			},
+		},
+		{
+			name:      "Batch write fails",
+			format:    TextFormat,
+			source:    "badger\n",
+			writeMode: "fail",
+			wantErr:   "Import:",
+		},
+		{
+			name:    "Unknown format",
+			format:  "xml",
+			source:  "badger\n",
+			wantErr: "Unknown dictionary format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := dao.NewMockMongoSession()
+			if tt.writeMode != "" {
+				mm.WriteMode = tt.writeMode
+			}
+			if len(tt.duplicateIDs) > 0 {
+				mm.DuplicateIDs = make(map[string]bool)
+				for _, id := range tt.duplicateIDs {
+					mm.DuplicateIDs[id] = true
+				}
+			}
+			kd := NewKillDictionary(context.Background(), mm, "importer", tt.existing...)
+
+			got, err := kd.Import(context.Background(), strings.NewReader(tt.source), tt.format)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tt.wantAdded), got.Added)
+			require.Equal(t, len(tt.wantRejected), len(got.Rejected), "Rejected: %v", got.Rejected)
+			for i, want := range tt.wantRejected {
+				require.Contains(t, got.Rejected[i], want)
+			}
+			for _, word := range tt.wantAdded {
+				require.Contains(t, kd.words, word)
+			}
+		})
+	}
+}
+
+func TestKillDictionary_Export(t *testing.T) {
+	mm := dao.NewMockMongoSession()
+	badger, _ := NewKillWord("exporter", "badger")
+	badger.Category = "animal"
+	badger.Difficulty = 2
+	teapot, _ := NewKillWord("exporter", "teapot")
+	other, _ := NewKillWord("otherdict", "walrus")
+	mm.CollectionResults = map[string][]dao.Persistable{
+		CollectionName: {&teapot, &badger, &other},
+	}
+	kd := NewKillDictionary(context.Background(), mm, "exporter")
+
+	tests := []struct {
+		name   string
+		format DictionaryFormat
+		want   string
+	}{
+		{"Text", TextFormat, "badger\nteapot\n"},
+		{"CSV", CSVFormat, "word,category,difficulty\nbadger,animal,2\nteapot,,\n"},
+		{"JSON", JSONFormat, "[\n  {\n    \"word\": \"badger\",\n    \"category\": \"animal\",\n    \"difficulty\": 2\n  },\n  {\n    \"word\": \"teapot\"\n  }\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, kd.Export(context.Background(), &out, tt.format))
+			require.Equal(t, tt.want, out.String())
+		})
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, kd.Export(context.Background(), &out, CSVFormat))
+		copyDict := NewKillDictionary(context.Background(), dao.NewMockMongoSession(), "copy")
+		result, err := copyDict.Import(context.Background(), &out, CSVFormat)
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Added)
+		require.Empty(t, result.Rejected)
+	})
+
+	t.Run("Mongo failure", func(t *testing.T) {
+		mm.QueryMode = "fail"
+		defer func() { mm.QueryMode = "positive" }()
+		require.Error(t, kd.Export(context.Background(), &bytes.Buffer{}, TextFormat))
+	})
+}
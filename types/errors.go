@@ -0,0 +1,34 @@
+package types
+
+import (
+	"errors"
+
+	persistence "wordassassin/persistence"
+)
+
+// Sentinel errors for the kinds of failure callers need to tell apart, whether they come from the game logic or the
+// persistence layer beneath it. Check for them with errors.Is
+var (
+	// ErrDuplicate means the game, player, event or word already exists
+	ErrDuplicate = persistence.ErrDuplicate
+	// ErrNotFound means there is no game, player or dictionary with the ID asked for
+	ErrNotFound = persistence.ErrNotFound
+	// ErrStoreUnavailable means the store, or the pool in front of it, can't take requests right now
+	ErrStoreUnavailable = persistence.ErrStoreUnavailable
+	// ErrConflict means the game or a player changed in the store since it was read, so a change to it was refused.
+	// Trying again against the latest state may work
+	ErrConflict = persistence.ErrConflict
+	// ErrInvalidState means the game isn't in a state that allows the request, such as joining a game in play
+	ErrInvalidState = errors.New("invalid state")
+	// ErrNotAuthorized means the request came from someone not allowed to make it
+	ErrNotAuthorized = errors.New("not authorized")
+	// ErrInvalidArgument means the request itself is malformed, such as a blank ID or an unknown format
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrUndecodable means a stored record can't be read back as the type it should be
+	ErrUndecodable = errors.New("undecodable")
+)
+
+// errorf formats an error of the given kind. See persistence.Errorf
+func errorf(kind error, format string, args ...interface{}) error {
+	return persistence.Errorf(kind, format, args...)
+}
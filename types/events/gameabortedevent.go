@@ -0,0 +1,83 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+// GameAbortedEvent is created when a game is called off before anyone wins
+type GameAbortedEvent struct {
+	ID          string        `json:"id" bson:"_id"`
+	TimeCreated time.Time     `json:"timeCreated" bson:"timecreated"`
+	Seq         int64         `json:"seq" bson:"seq"`
+	EventType   string        `json:"eventType" bson:"eventtype"`
+	GameID      string        `json:"gameId" bson:"gameid"`
+	AbortedBy   slack.SlackID `json:"abortedBy" bson:"abortedby"`
+}
+
+// NewGameAbortedEvent returns an instance of the event. A game only ends once, so the ID is derived from the gameid.
+// Errors:
+// -- either gameid or abortedBy is blank
+func NewGameAbortedEvent(gameid string, abortedBy slack.SlackID) (result GameAbortedEvent, err error) {
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if abortedBy == "" {
+		err = fmt.Errorf("The request is missing AbortedBy field")
+	}
+
+	result = GameAbortedEvent{
+		ID:          gameid + "+aborted",
+		TimeCreated: time.Now(),
+		EventType:   "GameAbortedEvent",
+		GameID:      gameid,
+		AbortedBy:   abortedBy,
+	}
+	return
+}
+
+// NewGameAbortedInline returns an instance of the event with no error value. Panics on error instead.
+func NewGameAbortedInline(gameid string, abortedBy slack.SlackID) GameAbortedEvent {
+	if result, err := NewGameAbortedEvent(gameid, abortedBy); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *GameAbortedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *GameAbortedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the game was aborted
+func (e *GameAbortedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetGameID returns the game this event belongs to
+func (e *GameAbortedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *GameAbortedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *GameAbortedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+func TestGameAbortedEventIsGameEvent(t *testing.T) {
+	_, ok := interface{}(&GameAbortedEvent{}).(GameEvent)
+	require.True(t, ok)
+}
+
+func TestNewGameAbortedEvent(t *testing.T) {
+	t.Run("Positive", func(t *testing.T) {
+		got, err := NewGameAbortedEvent("game1", slack.SlackID("UBOSS"))
+		require.NoError(t, err)
+		require.Equal(t, "game1+aborted", got.GetID())
+		require.Equal(t, slack.SlackID("UBOSS"), got.AbortedBy)
+		require.Equal(t, "GameAbortedEvent", got.EventType)
+	})
+	t.Run("No gameid", func(t *testing.T) {
+		_, err := NewGameAbortedEvent("", slack.SlackID("UBOSS"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing GameID field")
+	})
+	t.Run("No aborter", func(t *testing.T) {
+		_, err := NewGameAbortedEvent("game1", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing AbortedBy field")
+	})
+	t.Run("Inline", func(t *testing.T) {
+		require.Panics(t, func() { NewGameAbortedInline("", slack.SlackID("UBOSS")) })
+	})
+}
+
+func TestGameAbortedEvent_Decode(t *testing.T) {
+	original := NewGameAbortedInline("Caress of Steel", slack.SlackID("UNECROMANCER"))
+	original.TimeCreated = time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC)
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err)
+
+	actual := &GameAbortedEvent{}
+	require.NoError(t, actual.Decode(asBytes))
+	require.Equal(t, original.ID, actual.ID)
+	require.Equal(t, original.AbortedBy, actual.AbortedBy)
+	require.Equal(t, original.TimeCreated.Unix(), actual.GetTimeCreated().Unix())
+}
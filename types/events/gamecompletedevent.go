@@ -0,0 +1,89 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+// GameCompletedEvent is created when the game completes, naming the last assassin standing
+type GameCompletedEvent struct {
+	ID          string    `json:"id" bson:"_id"`
+	TimeCreated time.Time `json:"timeCreated" bson:"timecreated"`
+	Seq         int64     `json:"seq" bson:"seq"`
+	EventType   string    `json:"eventType" bson:"eventtype"`
+	GameID      string    `json:"gameId" bson:"gameid"`
+	WinnerID    string    `json:"winnerId" bson:"winnerid"`
+	TimeStarted time.Time `json:"timeStarted" bson:"timestarted"`
+}
+
+// NewGameCompletedEvent returns an instance of the event. A game only completes once, so the ID is derived
+// from the gameid.
+// Errors:
+// -- either gameid or winnerid is blank
+func NewGameCompletedEvent(gameid, winnerid string, timeStarted time.Time) (result GameCompletedEvent, err error) {
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if winnerid == "" {
+		err = fmt.Errorf("The request is missing WinnerID field")
+	}
+
+	result = GameCompletedEvent{
+		ID:          gameid + "+completed",
+		TimeCreated: time.Now(),
+		EventType:   "GameCompletedEvent",
+		GameID:      gameid,
+		WinnerID:    winnerid,
+		TimeStarted: timeStarted,
+	}
+	return
+}
+
+// NewGameCompletedInline returns an instance of the event with no error value. Panics on error instead.
+func NewGameCompletedInline(gameid, winnerid string, timeStarted time.Time) GameCompletedEvent {
+	if result, err := NewGameCompletedEvent(gameid, winnerid, timeStarted); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *GameCompletedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *GameCompletedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the game completed
+func (e *GameCompletedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetDuration provides the total running time of the completed game
+func (e *GameCompletedEvent) GetDuration() time.Duration {
+	return e.TimeCreated.Sub(e.TimeStarted)
+}
+
+// GetGameID returns the game this event belongs to
+func (e *GameCompletedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *GameCompletedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *GameCompletedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
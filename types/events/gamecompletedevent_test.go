@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/persistence"
+)
+
+func TestGameCompletedEventIsPersistable(t *testing.T) {
+	ev := &GameCompletedEvent{
+		ID:        "I will persist",
+		EventType: "GameCompletedEvent",
+	}
+
+	_, ok := interface{}(ev).(persistence.Persistable)
+	require.True(t, ok)
+	_, ok = interface{}(ev).(GameEvent)
+	require.True(t, ok)
+}
+
+func TestNewGameCompletedEvent(t *testing.T) {
+	started := time.Now().Add(-90 * time.Minute)
+	t.Run("Positive", func(t *testing.T) {
+		got, err := NewGameCompletedEvent("game1", "game1+UWINNER", started)
+		require.NoError(t, err)
+		require.Equal(t, "game1+completed", got.GetID())
+		require.Equal(t, "GameCompletedEvent", got.EventType)
+		require.Equal(t, "game1", got.GameID)
+		require.Equal(t, "game1+UWINNER", got.WinnerID)
+		require.Equal(t, started, got.TimeStarted)
+		require.True(t, got.GetDuration() >= 90*time.Minute, "Duration runs from start to completion")
+	})
+	t.Run("No gameid", func(t *testing.T) {
+		_, err := NewGameCompletedEvent("", "game1+UWINNER", started)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing GameID field")
+	})
+	t.Run("No winner", func(t *testing.T) {
+		_, err := NewGameCompletedEvent("game1", "", started)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing WinnerID field")
+	})
+	t.Run("Inline", func(t *testing.T) {
+		require.NotPanics(t, func() { NewGameCompletedInline("game1", "game1+UWINNER", started) })
+		require.Panics(t, func() { NewGameCompletedInline("game1", "", started) })
+	})
+}
+
+func TestGameCompletedEvent_Decode(t *testing.T) {
+	original, err := NewGameCompletedEvent("Redemption Song", "Redemption Song+UMARLEY", time.Now())
+	require.NoError(t, err)
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err, "Failure to marshal test object to bytes: %v", err)
+
+	actual := &GameCompletedEvent{}
+	err = actual.Decode(asBytes)
+	require.NoError(t, err, "Failure to Decode BSON: %v", err)
+	require.Equal(t, original.ID, actual.ID)
+	require.Equal(t, original.GameID, actual.GameID)
+	require.Equal(t, original.WinnerID, actual.WinnerID)
+	require.Equal(t, original.GetTimeCreated().Unix(), actual.GetTimeCreated().Unix())
+}
@@ -13,10 +13,12 @@ import (
 type GameCreatedEvent struct {
 	ID             string    	 `json:"id" bson:"_id"`
 	TimeCreated    time.Time 	 `json:"timeCreated"`
+	Seq            int64     	 `json:"seq" bson:"seq"`
 	EventType      string    	 `json:"eventType"`
 	GameCreator    slack.SlackID `json:"gameCreator"`
 	KillDictionary string    	 `json:"killDictionary"`
 	Passcode       string    	 `json:"passcode" bson:"passcode"`
+	Channel        string    	 `json:"channel" bson:"channel"` // where the game is announced in Slack, if anywhere
 }
 
 // NewGameCreatedEvent returns an instance of the event
@@ -72,4 +74,20 @@ func (e *GameCreatedEvent) GetID() string {
 // GetTimeCreated returns the unique identifer for this event
 func (e *GameCreatedEvent) GetTimeCreated() time.Time {
 	return e.TimeCreated
-}
\ No newline at end of file
+}
+
+// GetGameID returns the game this event belongs to
+func (e *GameCreatedEvent) GetGameID() string {
+	return e.ID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *GameCreatedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *GameCreatedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
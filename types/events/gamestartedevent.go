@@ -0,0 +1,87 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+// GameStartedEvent is created when the game is started. The seed drives target shuffling and kill word draws, so
+// replaying the event log deals the same targets the game did.
+type GameStartedEvent struct {
+	ID          string        `json:"id" bson:"_id"`
+	TimeCreated time.Time     `json:"timeCreated" bson:"timecreated"`
+	Seq         int64         `json:"seq" bson:"seq"`
+	EventType   string        `json:"eventType" bson:"eventtype"`
+	GameID      string        `json:"gameId" bson:"gameid"`
+	StartedBy   slack.SlackID `json:"startedBy" bson:"startedby"`
+	Seed        int64         `json:"seed" bson:"seed"`
+}
+
+// NewGameStartedEvent returns an instance of the event. A game only starts once, so the ID is derived from the
+// gameid.
+// Errors:
+// -- either gameid or startedBy is blank
+func NewGameStartedEvent(gameid string, startedBy slack.SlackID, seed int64) (result GameStartedEvent, err error) {
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if startedBy == "" {
+		err = fmt.Errorf("The request is missing StartedBy field")
+	}
+
+	result = GameStartedEvent{
+		ID:          gameid + "+started",
+		TimeCreated: time.Now(),
+		EventType:   "GameStartedEvent",
+		GameID:      gameid,
+		StartedBy:   startedBy,
+		Seed:        seed,
+	}
+	return
+}
+
+// NewGameStartedInline returns an instance of the event with no error value. Panics on error instead.
+func NewGameStartedInline(gameid string, startedBy slack.SlackID, seed int64) GameStartedEvent {
+	if result, err := NewGameStartedEvent(gameid, startedBy, seed); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *GameStartedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *GameStartedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the game was started
+func (e *GameStartedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetGameID returns the game this event belongs to
+func (e *GameStartedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *GameStartedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *GameStartedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
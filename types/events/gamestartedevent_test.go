@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/persistence"
+	"wordassassin/slack"
+)
+
+func TestGameStartedEventIsPersistable(t *testing.T) {
+	ev := &GameStartedEvent{ID: "I will persist"}
+
+	_, ok := interface{}(ev).(persistence.Persistable)
+	require.True(t, ok)
+}
+
+func TestNewGameStartedEvent(t *testing.T) {
+	t.Run("Positive", func(t *testing.T) {
+		got, err := NewGameStartedEvent("game1", slack.SlackID("UBOSS"), 42)
+		require.NoError(t, err)
+		require.Equal(t, "game1+started", got.GetID())
+		require.Equal(t, "game1", got.GameID)
+		require.Equal(t, slack.SlackID("UBOSS"), got.StartedBy)
+		require.Equal(t, int64(42), got.Seed)
+		require.Equal(t, "GameStartedEvent", got.EventType)
+	})
+	t.Run("No gameid", func(t *testing.T) {
+		_, err := NewGameStartedEvent("", slack.SlackID("UBOSS"), 42)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing GameID field")
+	})
+	t.Run("No starter", func(t *testing.T) {
+		_, err := NewGameStartedEvent("game1", "", 42)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing StartedBy field")
+	})
+	t.Run("Inline", func(t *testing.T) {
+		require.NotPanics(t, func() { NewGameStartedInline("game1", slack.SlackID("UBOSS"), 42) })
+		require.Panics(t, func() { NewGameStartedInline("", slack.SlackID("UBOSS"), 42) })
+	})
+}
+
+func TestGameStartedEvent_Decode(t *testing.T) {
+	original := GameStartedEvent{
+		ID:          "Limelight+started",
+		TimeCreated: time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC),
+		EventType:   "GameStartedEvent",
+		GameID:      "Limelight",
+		StartedBy:   slack.SlackID("UPEART"),
+		Seed:        1981,
+	}
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err, "Failure to marshal test object to bytes: %v", err)
+
+	actual := &GameStartedEvent{}
+	require.NoError(t, actual.Decode(asBytes))
+	require.Equal(t, original.ID, actual.ID)
+	require.Equal(t, original.GameID, actual.GameID)
+	require.Equal(t, original.StartedBy, actual.StartedBy)
+	require.Equal(t, original.Seed, actual.Seed)
+	require.Equal(t, original.TimeCreated.Unix(), actual.GetTimeCreated().Unix())
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+// KillReportedEvent is created each time a player reports their own assassination
+type KillReportedEvent struct {
+	ID          string        `json:"id" bson:"_id"`
+	TimeCreated time.Time     `json:"timeCreated" bson:"timecreated"`
+	Seq         int64         `json:"seq" bson:"seq"`
+	EventType   string        `json:"eventType" bson:"eventtype"`
+	GameID      string        `json:"gameId" bson:"gameid"`
+	PlayerID    string        `json:"playerId" bson:"playerid"`
+	SlackID     slack.SlackID `json:"slackId" bson:"slackid"`
+}
+
+// NewKillReportedEvent returns an instance of the event, along with an automagically calculated ID. Since a
+// player can only die once per game, the ID is unique per victim and game.
+// Errors:
+// -- either gameid or slackid is blank
+// -- slackid is an invalid slack id (per slack validator)
+func NewKillReportedEvent(gameid, slackid string) (result KillReportedEvent, err error) {
+	var actualSlackID slack.SlackID
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if slackid == "" {
+		err = fmt.Errorf("The request is missing SlackID field")
+	} else if actualSlackID, err = slack.New(slackid); err != nil {
+		err = fmt.Errorf("Player does not have a valid Slack ID: %v", err)
+	}
+
+	result = KillReportedEvent{
+		TimeCreated: time.Now(),
+		EventType:   "KillReportedEvent",
+		GameID:      gameid,
+		PlayerID:    gameid + "+" + slackid,
+		SlackID:     actualSlackID,
+	}
+
+	result.ID = result.PlayerID + "+killed"
+	return
+}
+
+// NewKillReportedInline returns an instance of the event with no error value. Panics on error instead.
+func NewKillReportedInline(gameid, slackid string) KillReportedEvent {
+	if result, err := NewKillReportedEvent(gameid, slackid); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *KillReportedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *KillReportedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the kill was reported
+func (e *KillReportedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetGameID returns the game this event belongs to
+func (e *KillReportedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *KillReportedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *KillReportedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
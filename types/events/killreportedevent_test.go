@@ -0,0 +1,100 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/persistence"
+	"wordassassin/slack"
+)
+
+func TestKillReportedEventIsPersistable(t *testing.T) {
+	ev := &KillReportedEvent{
+		ID:          "I will persist",
+		TimeCreated: time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC),
+		EventType:   "KillReportedEvent",
+		GameID:      "Time",
+	}
+
+	_, ok := interface{}(ev).(persistence.Persistable)
+	require.True(t, ok)
+}
+
+func TestNewKillReportedEvent_MultiplePermutations(t *testing.T) {
+	tests := []struct {
+		testname string
+		ID       string
+		GameID   string
+		SlackID  string
+		wantErr  bool
+		msg      string
+	}{
+		{
+			"Positive",
+			"game1+Uvictim+killed", "game1", "Uvictim",
+			false, "",
+		},
+		{
+			"No gameid",
+			"", "", "Uvictim",
+			true, "missing GameID field",
+		},
+		{
+			"No slackid",
+			"", "game1", "",
+			true, "missing SlackID field",
+		},
+		{
+			"Invalid slackid",
+			"", "game1", "@UBADSLACK",
+			true, "valid Slack ID",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			got, err := NewKillReportedEvent(tt.GameID, tt.SlackID)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.msg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.ID, got.GetID())
+				require.Equal(t, tt.GameID, got.GameID)
+				require.Equal(t, tt.GameID+"+"+tt.SlackID, got.PlayerID)
+				require.Equal(t, slack.SlackID(tt.SlackID), got.SlackID)
+				require.Equal(t, "KillReportedEvent", got.EventType)
+				require.NotNil(t, got.TimeCreated) // can't match a time now
+			}
+		})
+	}
+}
+
+func TestNewKillReportedInline(t *testing.T) {
+	require.NotPanics(t, func() { NewKillReportedInline("inline_game", "UINLINEVICTIM") })
+	require.Panics(t, func() { NewKillReportedInline("", "UINLINEVICTIM") })
+}
+
+func TestKillReportedEvent_Decode(t *testing.T) {
+	original := KillReportedEvent{
+		ID:          "testID",
+		TimeCreated: time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC),
+		EventType:   "KillReportedEvent",
+		GameID:      "Redemption Song",
+		PlayerID:    "Redemption Song+UWAILERS",
+		SlackID:     slack.SlackID("UWAILERS"),
+	}
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err, "Failure to marshal test object to bytes: %v", err)
+
+	actual := &KillReportedEvent{}
+	err = actual.Decode(asBytes)
+	require.NoError(t, err, "Failure to Decode BSON: %v", err)
+	require.Equal(t, original.ID, actual.ID)
+	require.Equal(t, original.GameID, actual.GameID)
+	require.Equal(t, original.PlayerID, actual.PlayerID)
+	require.Equal(t, original.SlackID, actual.SlackID)
+	require.Equal(t, original.TimeCreated.Unix(), actual.GetTimeCreated().Unix())
+}
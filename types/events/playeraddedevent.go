@@ -12,11 +12,13 @@ import (
 type PlayerAddedEvent struct {
 	ID          string        `json:"id" bson:"_id"`
 	TimeCreated time.Time     `json:"timeCreated" bson:"timecreated"`
+	Seq         int64         `json:"seq" bson:"seq"`
 	EventType	string	      `json:"eventType" bson:"eventtype"`
 	GameID		string	      `json:"gameId" bson:"gameid"`
 	SlackID     slack.SlackID `json:"slackId" bson:"slackid"`
 	Name        string        `json:"name" bson:"name"`
 	Email       string        `json:"email" bson:"email"`
+	TokenHash   string        `json:"-" bson:"tokenhash"`
 }
 
 // NewPlayerAddedEvent returns an instance of the event, along with an automagically calculated ID
@@ -71,4 +73,20 @@ func (e *PlayerAddedEvent) GetID() string {
 // GetTimeCreated returns the unique identifer for this event
 func (e *PlayerAddedEvent) GetTimeCreated() time.Time {
 	return e.TimeCreated
-}
\ No newline at end of file
+}
+
+// GetGameID returns the game this event belongs to
+func (e *PlayerAddedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *PlayerAddedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *PlayerAddedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+// PlayerRemovedEvent is created when a player is taken out of a game before it starts
+type PlayerRemovedEvent struct {
+	ID          string        `json:"id" bson:"_id"`
+	TimeCreated time.Time     `json:"timeCreated" bson:"timecreated"`
+	Seq         int64         `json:"seq" bson:"seq"`
+	EventType   string        `json:"eventType" bson:"eventtype"`
+	GameID      string        `json:"gameId" bson:"gameid"`
+	PlayerID    string        `json:"playerId" bson:"playerid"`
+	SlackID     slack.SlackID `json:"slackId" bson:"slackid"`
+}
+
+// NewPlayerRemovedEvent returns an instance of the event, along with an automagically calculated ID
+// Errors:
+// -- either gameid or slackid is blank
+// -- slackid is an invalid slack id (per slack validator)
+func NewPlayerRemovedEvent(gameid, slackid string) (result PlayerRemovedEvent, err error) {
+	var actualSlackID slack.SlackID
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if slackid == "" {
+		err = fmt.Errorf("The request is missing SlackID field")
+	} else if actualSlackID, err = slack.New(slackid); err != nil {
+		err = fmt.Errorf("Player does not have a valid Slack ID: %v", err)
+	}
+
+	result = PlayerRemovedEvent{
+		TimeCreated: time.Now(),
+		EventType:   "PlayerRemovedEvent",
+		GameID:      gameid,
+		PlayerID:    gameid + "+" + slackid,
+		SlackID:     actualSlackID,
+	}
+
+	result.ID = result.PlayerID + "+removed"
+	return
+}
+
+// NewPlayerRemovedInline returns an instance of the event with no error value. Panics on error instead.
+func NewPlayerRemovedInline(gameid, slackid string) PlayerRemovedEvent {
+	if result, err := NewPlayerRemovedEvent(gameid, slackid); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *PlayerRemovedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *PlayerRemovedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the player was removed
+func (e *PlayerRemovedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetGameID returns the game this event belongs to
+func (e *PlayerRemovedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *PlayerRemovedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *PlayerRemovedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
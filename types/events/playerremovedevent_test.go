@@ -0,0 +1,53 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	"wordassassin/slack"
+)
+
+func TestPlayerRemovedEventIsGameEvent(t *testing.T) {
+	_, ok := interface{}(&PlayerRemovedEvent{}).(GameEvent)
+	require.True(t, ok)
+}
+
+func TestNewPlayerRemovedEvent(t *testing.T) {
+	t.Run("Positive", func(t *testing.T) {
+		got, err := NewPlayerRemovedEvent("game1", "UQUITTER")
+		require.NoError(t, err)
+		require.Equal(t, "game1+UQUITTER+removed", got.GetID())
+		require.Equal(t, "game1+UQUITTER", got.PlayerID)
+		require.Equal(t, "PlayerRemovedEvent", got.EventType)
+	})
+	t.Run("No gameid", func(t *testing.T) {
+		_, err := NewPlayerRemovedEvent("", "UQUITTER")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing GameID field")
+	})
+	t.Run("Invalid slackid", func(t *testing.T) {
+		_, err := NewPlayerRemovedEvent("game1", "@UBADSLACK")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "valid Slack ID")
+	})
+	t.Run("Inline", func(t *testing.T) {
+		require.Panics(t, func() { NewPlayerRemovedInline("game1", "") })
+	})
+}
+
+func TestPlayerRemovedEvent_Decode(t *testing.T) {
+	original := NewPlayerRemovedInline("Hemispheres", "UCYGNUS")
+	original.TimeCreated = time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC)
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err)
+
+	actual := &PlayerRemovedEvent{}
+	require.NoError(t, actual.Decode(asBytes))
+	require.Equal(t, original.ID, actual.ID)
+	require.Equal(t, original.PlayerID, actual.PlayerID)
+	require.Equal(t, slack.SlackID("UCYGNUS"), actual.SlackID)
+	require.Equal(t, original.TimeCreated.Unix(), actual.GetTimeCreated().Unix())
+}
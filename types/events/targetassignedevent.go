@@ -0,0 +1,91 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+// TargetAssignedEvent is created when a target is assigned by the game engine, both when the game starts and when
+// an assassin inherits their victim's target
+type TargetAssignedEvent struct {
+	ID          string    `json:"id" bson:"_id"`
+	TimeCreated time.Time `json:"timeCreated" bson:"timecreated"`
+	Seq         int64     `json:"seq" bson:"seq"`
+	EventType   string    `json:"eventType" bson:"eventtype"`
+	GameID      string    `json:"gameId" bson:"gameid"`
+	KillerID    string    `json:"killerId" bson:"killerid"`
+	TargetID    string    `json:"targetId" bson:"targetid"`
+	KillWord    string    `json:"killword" bson:"killword"`
+}
+
+// NewTargetAssignedEvent returns an instance of the event. A killer is only ever assigned a given target once, so
+// the ID is derived from the pair.
+// Errors:
+// -- any of the arguments are blank
+func NewTargetAssignedEvent(gameid, killerid, targetid, killword string) (result TargetAssignedEvent, err error) {
+	if gameid == "" {
+		err = fmt.Errorf("The request is missing GameID field")
+	} else if killerid == "" {
+		err = fmt.Errorf("The request is missing KillerID field")
+	} else if targetid == "" {
+		err = fmt.Errorf("The request is missing TargetID field")
+	} else if killword == "" {
+		err = fmt.Errorf("The request is missing KillWord field")
+	}
+
+	result = TargetAssignedEvent{
+		ID:          killerid + "+targets+" + targetid,
+		TimeCreated: time.Now(),
+		EventType:   "TargetAssignedEvent",
+		GameID:      gameid,
+		KillerID:    killerid,
+		TargetID:    targetid,
+		KillWord:    killword,
+	}
+	return
+}
+
+// NewTargetAssignedInline returns an instance of the event with no error value. Panics on error instead.
+func NewTargetAssignedInline(gameid, killerid, targetid, killword string) TargetAssignedEvent {
+	if result, err := NewTargetAssignedEvent(gameid, killerid, targetid, killword); err != nil {
+		panic(err)
+	} else {
+		return result
+	}
+}
+
+// Decode populates this instance from the supplied bson
+func (e *TargetAssignedEvent) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetID returns the unique identifer for this event
+func (e *TargetAssignedEvent) GetID() string {
+	return e.ID
+}
+
+// GetTimeCreated returns the time the target was assigned
+func (e *TargetAssignedEvent) GetTimeCreated() time.Time {
+	return e.TimeCreated
+}
+
+// GetGameID returns the game this event belongs to
+func (e *TargetAssignedEvent) GetGameID() string {
+	return e.GameID
+}
+
+// GetSeq returns where this event falls in its game's log
+func (e *TargetAssignedEvent) GetSeq() int64 {
+	return e.Seq
+}
+
+// Stamp records when the event was applied to its game, and where it falls in the game's log
+func (e *TargetAssignedEvent) Stamp(at time.Time, seq int64) {
+	e.TimeCreated = at
+	e.Seq = seq
+}
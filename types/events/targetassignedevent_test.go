@@ -0,0 +1,70 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bson "go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTargetAssignedEventIsGameEvent(t *testing.T) {
+	_, ok := interface{}(&TargetAssignedEvent{}).(GameEvent)
+	require.True(t, ok)
+}
+
+func TestNewTargetAssignedEvent(t *testing.T) {
+	tests := []struct {
+		testname string
+		gameid   string
+		killer   string
+		target   string
+		word     string
+		msg      string
+	}{
+		{"Positive", "game1", "game1+UKILLER", "game1+UVICTIM", "teapot", ""},
+		{"No gameid", "", "game1+UKILLER", "game1+UVICTIM", "teapot", "missing GameID field"},
+		{"No killer", "game1", "", "game1+UVICTIM", "teapot", "missing KillerID field"},
+		{"No target", "game1", "game1+UKILLER", "", "teapot", "missing TargetID field"},
+		{"No word", "game1", "game1+UKILLER", "game1+UVICTIM", "", "missing KillWord field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			got, err := NewTargetAssignedEvent(tt.gameid, tt.killer, tt.target, tt.word)
+			if tt.msg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.msg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "game1+UKILLER+targets+game1+UVICTIM", got.GetID())
+			require.Equal(t, "TargetAssignedEvent", got.EventType)
+			require.Equal(t, tt.word, got.KillWord)
+		})
+	}
+	t.Run("Inline", func(t *testing.T) {
+		require.NotPanics(t, func() { NewTargetAssignedInline("game1", "game1+UKILLER", "game1+UVICTIM", "teapot") })
+		require.Panics(t, func() { NewTargetAssignedInline("game1", "game1+UKILLER", "game1+UVICTIM", "") })
+	})
+}
+
+func TestTargetAssignedEvent_Decode(t *testing.T) {
+	original := TargetAssignedEvent{
+		ID:          "Tom Sawyer",
+		TimeCreated: time.Date(2112, time.February, 13, 16, 20, 0, 0, time.UTC),
+		EventType:   "TargetAssignedEvent",
+		GameID:      "Moving Pictures",
+		KillerID:    "Moving Pictures+UKILLER",
+		TargetID:    "Moving Pictures+UVICTIM",
+		KillWord:    "mean mean stride",
+	}
+	asBytes, err := bson.Marshal(original)
+	require.NoError(t, err)
+
+	actual := &TargetAssignedEvent{}
+	require.NoError(t, actual.Decode(asBytes))
+	require.Equal(t, original.KillerID, actual.KillerID)
+	require.Equal(t, original.TargetID, actual.TargetID)
+	require.Equal(t, original.KillWord, actual.KillWord)
+	require.Equal(t, original.TimeCreated.Unix(), actual.GetTimeCreated().Unix())
+}
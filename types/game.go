@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 	"math/rand"
+	"sort"
 	bson "go.mongodb.org/mongo-driver/bson"
 	
 	events "wordassassin/types/events"
@@ -25,6 +26,15 @@ type Game struct {
 	MinimumPlayers int 		     `json:"minimumplayers"`
 	StartPlayers   int           `json:"startplayers"`
 	RemainPlayers  int           `json:"remainplayers"`
+	FinishTime     time.Time     `json:"finishtime"`
+	Winner         string        `json:"winner" bson:"winner"`
+	Channel        string        `json:"channel" bson:"channel"`
+	Version        int64         `json:"version" bson:"version"`
+	EventSeq       int64         `json:"eventseq" bson:"eventseq"` // Seq of the last event logged for this game
+	// Kill word drawing state. Not persisted; rebuilt from the players when the dictionary is attached
+	dict           *KillDictionary
+	rnd            *rand.Rand
+	usedWords      map[string]bool
 	// Other possible things:
 	//	TargetList
 	//	NumKills
@@ -51,8 +61,10 @@ func NewGameFromEvent(ev events.GameCreatedEvent) (g Game) {
 		GameCreator:    ev.GameCreator,
 		KillDictionary: ev.KillDictionary,
 		Passcode:       ev.Passcode,
+		Channel:        ev.Channel,
 		Status:         Starting,
 		StartTime:		time.Unix(0, 0),
+		FinishTime:		time.Unix(0, 0),
 		MinimumPlayers:	DefaultMinimumPlayers, // TODO: pass in override from event
 	}
 	return
@@ -71,6 +83,49 @@ func (g *Game) GetID() string {
 	return g.ID
 }
 
+// GetVersion getter for Version field, which counts the updates persisted for this game
+func (g *Game) GetVersion() int64 {
+	return g.Version
+}
+
+// SetVersion setter for Version field. The persistence layer moves it on with each update
+func (g *Game) SetVersion(version int64) {
+	g.Version = version
+}
+
+// GetDuration provides how long the game has been running, or for a finished game how long it ran in total.
+// Games that haven't started yet have no duration.
+func (g *Game) GetDuration() time.Duration {
+	switch g.Status {
+	case Playing:
+		return time.Since(g.StartTime)
+	case Finished:
+		return g.FinishTime.Sub(g.StartTime)
+	}
+	return 0
+}
+
+// SetKillDictionary attaches the dictionary that kill words are drawn from. Any words already assigned to or used
+// against the supplied players are marked as used, so they won't be handed out again in this game. That includes the
+// last word each dead player was dealt, which it keeps after its death.
+func (g *Game) SetKillDictionary(kd *KillDictionary, players ...*Player) {
+	g.dict = kd
+	g.usedWords = make(map[string]bool, len(players)*2)
+	for _, p := range players {
+		if p.KillWord != "" {
+			g.usedWords[p.KillWord] = true
+		}
+		if p.KilledWith != "" {
+			g.usedWords[p.KilledWith] = true
+		}
+	}
+}
+
+// Seed sets the random source used for target shuffling and kill word draws. Mostly useful for deterministic tests.
+func (g *Game) Seed(seed int64) {
+	g.rnd = rand.New(rand.NewSource(seed))
+}
+
 // GetPlayerList fetches a map of players from the player pool for this game keyed by ID
 func (g *Game) GetPlayerList(pp PlayerPoolAbstraction) (result map[string]*Player) {
 	if list, err := pp.GetAllPlayersInGame(g.GetID()); err == nil {
@@ -98,6 +153,11 @@ func (g *Game) GetStatusReport() string {
 	fmt.Sprintf("Game Status for %s:\n\n", g.GetID()) +
 	fmt.Sprintf("   Status: %s\n", 	       g.GetStatus()) +
 	fmt.Sprintf("   # Players: %d\n",      g.StartPlayers)
+	if g.Status == Finished {
+		result +=
+		fmt.Sprintf("   Winner: %s\n",        g.Winner) +
+		fmt.Sprintf("   Duration: %s\n",      g.GetDuration().Round(time.Second))
+	}
 	
 	return result	
 	}	
@@ -106,45 +166,190 @@ func (g *Game) GetStatusReport() string {
 func (g *Game) Start(players []*Player) error {
 	// Validate whatever needs validating
 	if g.Status != Starting {
-		return fmt.Errorf("Game not in starting state. Current state is %s", g.GetStatus())
+		return errorf(ErrInvalidState, "Game not in starting state. Current state is %s", g.GetStatus())
 	}
 	// make sure something isn't horribly wrong in accounting
 	if g.StartPlayers != len(players) {
 		panic( fmt.Sprintf("Game: %s.StartPlayers=%d, PlayerPool count=%d", g.GetID(), g.StartPlayers, len(players))	)	
 	}
 	if g.StartPlayers < g.MinimumPlayers {
-		return fmt.Errorf("Game requires %d players. Current count is %d", g.MinimumPlayers, g.StartPlayers)
+		return errorf(ErrInvalidState, "Game requires %d players. Current count is %d", g.MinimumPlayers, g.StartPlayers)
 	}
-	if !ValidDictionary(g.KillDictionary) {
-		return fmt.Errorf("Game requires a valid dictionary. %s doesn't meet the critera", g.KillDictionary)
+	if err := ValidDictionary(g.dict, g.StartPlayers); err != nil {
+		return errorf(ErrInvalidState, "Game requires a valid dictionary. %v", err)
 	}
 	// Assign first round of targets
-	g.SetAllTargets(players)
+	if err := g.SetAllTargets(players); err != nil {
+		return err
+	}
 	// Set the game status to "running" and the start time
 	g.Status = Playing
 	g.StartTime = time.Now()
+	g.RemainPlayers = g.StartPlayers
 	// Log what you gotta log -- unless an event is written first
 	return nil
 }
 	
-// SetAllTargets creates the targets and kill words for all players in a list, using this Game's kill dict
-func (g *Game) SetAllTargets(players []*Player) {
+// RecordKill applies the assassination of the victim to the players in this game. The victim's assassin is
+// whichever living player currently holds them as a target. The assassin inherits the victim's target and kill
+// word, and the victim is marked dead along with who did the deed and with which word.
+// Errors:
+// -- game not in playing state
+// -- victim not found in the player list, or already dead
+// -- no living assassin holds the victim as a target
+func (g *Game) RecordKill(victimID string, players []*Player) (assassin *Player, err error) {
+	victim, assassin, err := g.findKill(victimID, players)
+	if err != nil {
+		return nil, err
+	}
+	// Unless this kill ends the game, the assassin gets a fresh word for their new target
+	var killWord string
+	if g.RemainPlayers > 2 {
+		if killWord, err = g.drawKillWord(); err != nil {
+			return nil, err
+		}
+	}
+	g.applyKill(victim, assassin, killWord)
+	return assassin, nil
+}
+
+// findKill finds the victim of a kill, and the living player holding them as a target. See RecordKill for the errors
+func (g *Game) findKill(victimID string, players []*Player) (victim *Player, assassin *Player, err error) {
+	if g.Status != Playing {
+		return nil, nil, errorf(ErrInvalidState, "Game not in playing state. Current state is %s", g.GetStatus())
+	}
+	for _, p := range players {
+		if p.GetID() == victimID {
+			victim = p
+			break
+		}
+	}
+	if victim == nil {
+		return nil, nil, errorf(ErrNotFound, "Player %s is not in game %s", victimID, g.GetID())
+	}
+	if !victim.IsAlive() {
+		return nil, nil, errorf(ErrDuplicate, "Player %s is already dead", victimID)
+	}
+	for _, p := range players {
+		if p.IsAlive() && p.Target == victimID && p != victim {
+			return victim, p, nil
+		}
+	}
+	return nil, nil, errorf(ErrInvalidState, "No living assassin in game %s is targeting %s", g.GetID(), victimID)
+}
+
+// applyKill records the death, and passes the victim's target on to the assassin along with the word to kill it with.
+// The victim keeps the last word it was dealt, so that a restart knows the word is spent
+func (g *Game) applyKill(victim *Player, assassin *Player, killWord string) {
+	victim.Status = Dead
+	victim.KilledBy = assassin.GetID()
+	victim.KilledWith = assassin.KillWord
+	assassin.Kills++
+	assassin.SetTarget(victim.Target, killWord)
+	victim.Target = ""
+	g.RemainPlayers--
+	// Last assassin standing takes the game
+	if g.RemainPlayers <= 1 {
+		assassin.SetTarget("", "")
+		g.Finish(assassin.GetID())
+	}
+}
+
+// Finish closes out a game in play, declaring the winner and stamping the finish time
+func (g *Game) Finish(winnerID string) {
+	g.Status = Finished
+	g.Winner = winnerID
+	g.FinishTime = time.Now()
+}
+
+// Abort calls off a game that hasn't finished, stamping the finish time. There is no winner.
+func (g *Game) Abort() error {
+	if g.Status != Starting && g.Status != Playing {
+		return errorf(ErrInvalidState, "Game can't be aborted. Current state is %s", g.GetStatus())
+	}
+	g.Status = Aborted
+	g.FinishTime = time.Now()
+	return nil
+}
+
+// SetAllTargets creates the targets and kill words for all players in a list, using this Game's kill dict. Every
+// player gets a distinct word. Words are drawn before any assignment is made, so an error leaves targets untouched.
+func (g *Game) SetAllTargets(players []*Player) error {
 	// for each assignment, send target notification -- delay until last in case of issues above to prevent chances
 	//   of false notification
-	// Randomly shuffle the players list
-	rand.Shuffle(len(players), func(i, j int) {
+	words := make([]string, len(players))
+	for i := range players {
+		word, err := g.drawKillWord()
+		if err != nil {
+			return err
+		}
+		words[i] = word
+	}
+	// Randomly shuffle the players list, starting from ID order so a seeded game always deals the same ring
+	sort.Slice(players, func(i, j int) bool { return players[i].GetID() < players[j].GetID() })
+	g.random().Shuffle(len(players), func(i, j int) {
 		players[i], players[j] = players[j], players[i]
 	})
 	// Assign as target the next player in the list (post shuffle)
 	for i := 0; i < len(players)-1; i++ {
-		players[i].SetTarget(players[i+1].GetID(), "TODO: assign random word from the killdict")
+		players[i].SetTarget(players[i+1].GetID(), words[i])
 	}
 	// Wraparound the assignment from last back to the first player
-	players[len(players)-1].SetTarget(players[0].GetID(), "TODO: assign random word from the killdict")
+	players[len(players)-1].SetTarget(players[0].GetID(), words[len(players)-1])
+	return nil
+}
+
+// RequiredWords calculates how many kill words a game needs over its lifetime: one for each player at the start,
+// plus a reserve for the fresh word drawn on every kill that doesn't end the game.
+func RequiredWords(numPlayers int) int {
+	if numPlayers < 2 {
+		return numPlayers
+	}
+	return numPlayers + (numPlayers - 2)
 }
 
-// ValidDictionary checks for the existence and proper format of a KillDictionary
-func ValidDictionary( dict string ) bool {
-	// TODO: define and implement what valid means
-	return true
-}
\ No newline at end of file
+// ValidDictionary checks that a KillDictionary exists and holds enough words to see a game with the given number of
+// players through to the end. The error spells out the shortfall.
+func ValidDictionary(kd *KillDictionary, numPlayers int) error {
+	if kd == nil {
+		return errorf(ErrNotFound, "no KillDictionary loaded")
+	}
+	needed := RequiredWords(numPlayers)
+	if kd.Count() < needed {
+		return errorf(ErrInvalidArgument, "KillDictionary %s has %d words, but %d players need %d (%d to start plus %d in reserve for reassignments). Short by %d",
+			kd.ID, kd.Count(), numPlayers, needed, numPlayers, needed-numPlayers, needed-kd.Count())
+	}
+	return nil
+}
+
+// drawKillWord pulls a word from the attached dictionary that hasn't been used in this game yet
+func (g *Game) drawKillWord() (string, error) {
+	if g.dict == nil {
+		return "", fmt.Errorf("Game %s has no KillDictionary loaded", g.GetID())
+	}
+	if g.usedWords == nil {
+		g.usedWords = make(map[string]bool)
+	}
+	word, err := g.dict.GetKillWord(g.random(), g.usedWords)
+	if err != nil {
+		return "", err
+	}
+	g.usedWords[word] = true
+	return word, nil
+}
+
+// markWordUsed keeps a word assigned outside of drawKillWord from being drawn again
+func (g *Game) markWordUsed(word string) {
+	if g.usedWords == nil {
+		g.usedWords = make(map[string]bool)
+	}
+	g.usedWords[word] = true
+}
+
+// random provides this game's random source, creating a time seeded one on first use
+func (g *Game) random() *rand.Rand {
+	if g.rnd == nil {
+		g.Seed(time.Now().UnixNano())
+	}
+	return g.rnd
+}
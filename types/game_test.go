@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"fmt"
 	"time"
@@ -9,6 +11,7 @@ import (
 	bson "go.mongodb.org/mongo-driver/bson"
 
 	events "wordassassin/types/events"
+	dao "wordassassin/persistence"
 	"wordassassin/slack"
 )
 
@@ -63,6 +66,7 @@ func TestStart(t *testing.T) {
 	ev, _ := events.NewGameCreatedEvent(expectedID, "UKingKong", "bananas.txt", "Jane")
 	youCanStartMeUp := NewGameFromEvent(ev)
 	youCanStartMeUp.StartPlayers = 13
+	youCanStartMeUp.SetKillDictionary(generateKillDictionary("bananas.txt", 30))
 	players := generatePlayers(youCanStartMeUp.ID, 13)
 
 	t.Run("Positive", func(t *testing.T) {
@@ -71,7 +75,31 @@ func TestStart(t *testing.T) {
 		require.Equal(t, Playing, youCanStartMeUp.Status)
 		actualStatus := youCanStartMeUp.GetStatus()
 		require.Equal(t, "playing", actualStatus)
+		require.Equal(t, 13, youCanStartMeUp.RemainPlayers, "Everyone starts out alive")
 		// TODO: find a way to validate that timestamp was set to now
+		used := make(map[string]bool)
+		for _, p := range players {
+			require.NotEqual(t, "", p.KillWord, "Every player gets a kill word")
+			require.False(t, used[p.KillWord], "Kill words are not repeated within a game")
+			used[p.KillWord] = true
+		}
+	})
+	t.Run("Not enough words", func(t *testing.T) {
+		wordStarved := NewGameFromEvent(ev)
+		wordStarved.StartPlayers = 13
+		wordStarved.SetKillDictionary(generateKillDictionary("bananas.txt", 20))
+		err := wordStarved.Start(generatePlayers(wordStarved.ID, 13))
+		require.Error(t, err, "Can't start without enough words to finish the game")
+		require.Contains(t, err.Error(), "Game requires a valid dictionary. KillDictionary bananas.txt has 20 words, but 13 players need 24")
+		require.Contains(t, err.Error(), "Short by 4")
+		require.Equal(t, Starting, wordStarved.Status)
+	})
+	t.Run("No dictionary", func(t *testing.T) {
+		noDict := NewGameFromEvent(ev)
+		noDict.StartPlayers = 13
+		err := noDict.Start(generatePlayers(noDict.ID, 13))
+		require.Error(t, err, "Can't start without a dictionary")
+		require.Contains(t, err.Error(), "Game requires a valid dictionary. no KillDictionary loaded")
 	})
 	t.Run("Wrong state", func(t *testing.T) {
 		runningGame := NewGameFromEvent(ev)
@@ -92,9 +120,166 @@ func TestStart(t *testing.T) {
 		expectedMsg := fmt.Sprintf("Game requires %d players. Current count is %d", minExpected, numPlayers)
 		require.Contains(t, err.Error(), expectedMsg)
 	})
-	t.Run("Invalid target list", func(t *testing.T) { // TODO: create a test when valid dictionary logic is defined
-		require.True(t, true)
+	t.Run("Dictionary shrank after creation", func(t *testing.T) {
+		shrunk := NewGameFromEvent(ev)
+		shrunk.StartPlayers = 5
+		kd := generateKillDictionary("bananas.txt", 8)
+		require.NoError(t, kd.RemoveWord(context.Background(), "bananas.txt-0"))
+		shrunk.SetKillDictionary(kd)
+		err := shrunk.Start(generatePlayers(shrunk.ID, 5))
+		require.Error(t, err, "Dictionary is checked again at start")
+		require.Contains(t, err.Error(), "Short by 1")
+	})
+}
+
+func TestRecordKill(t *testing.T) {
+	ev, _ := events.NewGameCreatedEvent("killingFloor", "UKingKong", "bananas.txt", "Jane")
+	setup := func() (*Game, []*Player) {
+		g := NewGameFromEvent(ev)
+		g.Status = Playing
+		g.StartPlayers = 3
+		g.RemainPlayers = 3
+		players := generatePlayers(g.ID, 3)
+		g.SetKillDictionary(generateKillDictionary("bananas.txt", 10))
+		// p0 -> p1 -> p2 -> p0
+		players[0].SetTarget(players[1].ID, "word1")
+		players[1].SetTarget(players[2].ID, "word2")
+		players[2].SetTarget(players[0].ID, "word0")
+		return &g, players
+	}
+
+	t.Run("Positive", func(t *testing.T) {
+		g, players := setup()
+		assassin, err := g.RecordKill(players[1].ID, players)
+		require.NoError(t, err)
+		require.Equal(t, players[0], assassin, "Assassin is whoever had the victim as a target")
+		require.Equal(t, Dead, players[1].Status)
+		require.Equal(t, players[0].ID, players[1].KilledBy)
+		require.Equal(t, "word1", players[1].KilledWith)
+		require.Equal(t, "", players[1].Target, "The dead hunt no more")
+		require.Equal(t, 1, assassin.Kills)
+		require.Equal(t, players[2].ID, assassin.Target, "Assassin inherits the victim's target")
+		require.Contains(t, assassin.KillWord, "bananas", "Assassin draws a fresh word from the dictionary")
+		require.Equal(t, 2, g.RemainPlayers)
+	})
+	t.Run("Wrong state", func(t *testing.T) {
+		g, players := setup()
+		g.Status = Starting
+		_, err := g.RecordKill(players[1].ID, players)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not in playing state")
+	})
+	t.Run("Unknown victim", func(t *testing.T) {
+		g, players := setup()
+		_, err := g.RecordKill("nobody", players)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Player nobody is not in game")
+	})
+	t.Run("Already dead", func(t *testing.T) {
+		g, players := setup()
+		_, err := g.RecordKill(players[1].ID, players)
+		require.NoError(t, err)
+		_, err = g.RecordKill(players[1].ID, players)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already dead")
+		require.Equal(t, 2, g.RemainPlayers, "A second report must not change the count")
+	})
+	t.Run("Last one standing wins", func(t *testing.T) {
+		g, players := setup()
+		_, err := g.RecordKill(players[1].ID, players)
+		require.NoError(t, err)
+		require.Equal(t, Playing, g.Status, "Two players left is still a game")
+		winner, err := g.RecordKill(players[2].ID, players)
+		require.NoError(t, err)
+		require.Equal(t, Finished, g.Status)
+		require.Equal(t, players[0].ID, g.Winner)
+		require.Equal(t, 1, g.RemainPlayers)
+		require.Equal(t, "", winner.Target, "No one left to hunt")
+		require.False(t, g.FinishTime.Before(g.StartTime), "Finish time should be stamped")
+		report := g.GetStatusReport()
+		require.Contains(t, report, "Status: finished")
+		require.Contains(t, report, "Winner: "+players[0].ID)
+		require.Contains(t, report, "Duration: ")
+	})
+	t.Run("Out of words", func(t *testing.T) {
+		g, players := setup()
+		g.SetKillDictionary(generateKillDictionary("bananas.txt", 0))
+		_, err := g.RecordKill(players[1].ID, players)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no unused words left")
+		require.Equal(t, Alive, players[1].Status, "A failed kill leaves the victim alone")
+	})
+	t.Run("No assassin", func(t *testing.T) {
+		g, players := setup()
+		players[0].SetTarget("", "")
+		_, err := g.RecordKill(players[1].ID, players)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "No living assassin")
+		require.True(t, errors.Is(err, ErrInvalidState))
+	})
+}
+
+func TestSetAllTargets_Deterministic(t *testing.T) {
+	ev, _ := events.NewGameCreatedEvent("seeded", "UKingKong", "bananas.txt", "Jane")
+	assign := func(seed int64) map[string]string {
+		g := NewGameFromEvent(ev)
+		g.SetKillDictionary(generateKillDictionary("bananas.txt", 50))
+		g.Seed(seed)
+		players := generatePlayers(g.ID, 8)
+		require.NoError(t, g.SetAllTargets(players))
+		result := make(map[string]string, len(players))
+		for _, p := range players {
+			result[p.ID] = p.Target + "/" + p.KillWord
+		}
+		return result
+	}
+	require.Equal(t, assign(2112), assign(2112), "Same seed, same assignments")
+	require.NotEqual(t, assign(2112), assign(1984), "Different seed, different assignments")
+}
+
+func TestSetKillDictionary_MarksUsedWords(t *testing.T) {
+	ev, _ := events.NewGameCreatedEvent("restored", "UKingKong", "bananas.txt", "Jane")
+	g := NewGameFromEvent(ev)
+	kd := generateKillDictionary("bananas.txt", 3)
+	players := generatePlayers(g.ID, 2)
+	players[0].KillWord = "bananas.txt-0"
+	players[1].KilledWith = "bananas.txt-1"
+	g.SetKillDictionary(kd, players...)
+	word, err := g.drawKillWord()
+	require.NoError(t, err)
+	require.Equal(t, "bananas.txt-2", word, "Only the unused word is left to draw")
+}
+
+func TestValidDictionary(t *testing.T) {
+	require.Equal(t, 0, RequiredWords(0))
+	require.Equal(t, 2, RequiredWords(2), "Two players never need a reassignment")
+	require.Equal(t, 8, RequiredWords(DefaultMinimumPlayers))
+	t.Run("Positive", func(t *testing.T) {
+		require.NoError(t, ValidDictionary(generateKillDictionary("plenty", 8), 5))
 	})
+	t.Run("No dictionary", func(t *testing.T) {
+		err := ValidDictionary(nil, 5)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no KillDictionary loaded")
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+	t.Run("Shortfall", func(t *testing.T) {
+		err := ValidDictionary(generateKillDictionary("skimpy", 7), 5)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "KillDictionary skimpy has 7 words, but 5 players need 8 (5 to start plus 3 in reserve for reassignments). Short by 1")
+	})
+}
+
+func TestGetDuration(t *testing.T) {
+	ev, _ := events.NewGameCreatedEvent("timer", "UKingKong", "bananas.txt", "Jane")
+	g := NewGameFromEvent(ev)
+	require.Equal(t, time.Duration(0), g.GetDuration(), "No duration before the game starts")
+	g.Status = Playing
+	g.StartTime = time.Now().Add(-time.Hour)
+	require.True(t, g.GetDuration() >= time.Hour, "A running game is timed up to now")
+	g.Finish("timer+UKingKong")
+	g.FinishTime = g.StartTime.Add(42 * time.Minute)
+	require.Equal(t, 42*time.Minute, g.GetDuration(), "A finished game is timed start to finish")
 }
 
 // ID             string     `json:"id" bson:"_id"`
@@ -123,4 +308,14 @@ func generatePlayers(gid string, numPlayers int) (players []*Player) {
 		}
 	}
 	return
-}
\ No newline at end of file
+}
+
+// generateKillDictionary builds an in memory dictionary with the requested number of words, backed by mock mongo
+func generateKillDictionary(id string, numWords int) *KillDictionary {
+	words := make([]string, numWords)
+	for i := range words {
+		words[i] = fmt.Sprintf("%s-%d", id, i)
+	}
+	kd := NewKillDictionary(context.Background(), dao.NewMockMongoSession(), id, words...)
+	return &kd
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// actorQueueSize is how many commands a game can have waiting before senders block
+	actorQueueSize = 32
+)
+
+// gameActor owns a single game. Every command for the game runs on the actor's goroutine, one at a time and in the
+// order they were sent, so commands can change the game and its players without any locking. Different games have
+// different actors, and proceed in parallel.
+type gameActor struct {
+	id       string
+	game     *Game
+	commands chan func(*Game)
+	depth    int32        // commands sent but not yet finished
+	mu       sync.RWMutex // guards closed, so nothing is sent on a closed channel
+	closed   bool
+	done     chan struct{}
+}
+
+// newGameActor starts the goroutine for a game
+func newGameActor(game *Game) *gameActor {
+	a := &gameActor{
+		id:       game.GetID(),
+		game:     game,
+		commands: make(chan func(*Game), actorQueueSize),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *gameActor) run() {
+	defer close(a.done)
+	for cmd := range a.commands {
+		cmd(a.game)
+		atomic.AddInt32(&a.depth, -1)
+	}
+}
+
+// send queues a command, and returns the channel its result will arrive on. A command that panics fails with an
+// error rather than taking the actor, and the server, down with it. A command whose context is done by the time
+// its turn comes is dropped without running, since nobody is waiting on it any more.
+// Errors:
+// -- the actor has been drained
+// -- ctx is done while the queue is full
+func (a *gameActor) send(ctx context.Context, cmd func(*Game) error) (<-chan error, error) {
+	result := make(chan error, 1)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil, errorf(ErrStoreUnavailable, "GameID: %s is shutting down", a.id)
+	}
+	run := func(game *Game) {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("GameID: %s command failed: %v", a.id, r)
+			}
+		}()
+		if err := ctx.Err(); err != nil {
+			result <- errorf(ErrStoreUnavailable, "GameID: %s command abandoned: %w", a.id, err)
+			return
+		}
+		result <- cmd(game)
+	}
+	atomic.AddInt32(&a.depth, 1)
+	select {
+	case a.commands <- run:
+		return result, nil
+	case <-ctx.Done():
+		atomic.AddInt32(&a.depth, -1)
+		return nil, errorf(ErrStoreUnavailable, "GameID: %s command abandoned: %w", a.id, ctx.Err())
+	}
+}
+
+// do runs a command on the actor and waits for it to finish. Once a command has started it runs to the end, so the
+// wait does too; the command's own calls to the store give up when ctx is done
+func (a *gameActor) do(ctx context.Context, cmd func(*Game) error) error {
+	result, err := a.send(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return <-result
+}
+
+// drain stops the actor taking commands, and waits for the ones already queued to finish
+func (a *gameActor) drain() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.commands)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+// queueDepth counts the commands waiting or running
+func (a *gameActor) queueDepth() int {
+	return int(atomic.LoadInt32(&a.depth))
+}
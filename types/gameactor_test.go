@@ -0,0 +1,126 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameActor_Order(t *testing.T) {
+	actor := newGameActor(&Game{ID: "inorder"})
+	defer actor.drain()
+
+	// Commands sent one after another run in that order, even when their callers don't wait
+	var seen []int
+	results := make([]<-chan error, 50)
+	for i := range results {
+		i := i
+		var err error
+		results[i], err = actor.send(context.Background(), func(game *Game) error {
+			seen = append(seen, i)
+			game.StartPlayers++
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	for _, r := range results {
+		require.NoError(t, <-r)
+	}
+	require.Len(t, seen, 50)
+	for i, v := range seen {
+		require.Equal(t, i, v, "Commands ran out of order")
+	}
+	require.Equal(t, 50, actor.game.StartPlayers)
+}
+
+func TestGameActor_QueueDepth(t *testing.T) {
+	actor := newGameActor(&Game{ID: "backlog"})
+	defer actor.drain()
+	require.Equal(t, 0, actor.queueDepth())
+
+	release := make(chan struct{})
+	blocked, err := actor.send(context.Background(), func(*Game) error { <-release; return nil })
+	require.NoError(t, err)
+	queued, err := actor.send(context.Background(), func(*Game) error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, 2, actor.queueDepth(), "One running and one waiting")
+
+	close(release)
+	require.NoError(t, <-blocked)
+	require.NoError(t, <-queued)
+	require.Equal(t, 0, actor.queueDepth())
+}
+
+func TestGameActor_Drain(t *testing.T) {
+	actor := newGameActor(&Game{ID: "closing"})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	ran := 0
+	for i := 0; i < 5; i++ {
+		result, err := actor.send(context.Background(), func(*Game) error {
+			<-release
+			ran++
+			return nil
+		})
+		require.NoError(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-result
+		}()
+	}
+	drained := make(chan struct{})
+	go func() {
+		actor.drain()
+		close(drained)
+	}()
+	close(release)
+	<-drained
+	wg.Wait()
+	require.Equal(t, 5, ran, "Everything queued before the drain still runs")
+
+	err := actor.do(context.Background(), func(*Game) error { return nil })
+	require.Error(t, err, "Nothing runs after the drain")
+	require.Contains(t, err.Error(), "GameID: closing is shutting down")
+	actor.drain()
+}
+
+func TestGameActor_Panic(t *testing.T) {
+	actor := newGameActor(&Game{ID: "fragile"})
+	defer actor.drain()
+	err := actor.do(context.Background(), func(*Game) error { panic("kaboom") })
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GameID: fragile command failed: kaboom")
+	require.NoError(t, actor.do(context.Background(), func(*Game) error { return nil }), "The actor keeps going")
+	require.Equal(t, fmt.Errorf("plain"), actor.do(context.Background(), func(*Game) error { return fmt.Errorf("plain") }))
+}
+
+func TestGameActor_Abandoned(t *testing.T) {
+	actor := newGameActor(&Game{ID: "impatient"})
+	defer actor.drain()
+	release := make(chan struct{})
+	blocked, err := actor.send(context.Background(), func(*Game) error { <-release; return nil })
+	require.NoError(t, err)
+
+	// The caller gives up while its command waits behind another, so the command never runs
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	queued, err := actor.send(ctx, func(*Game) error { ran = true; return nil })
+	require.NoError(t, err)
+	cancel()
+	close(release)
+	require.NoError(t, <-blocked)
+	err = <-queued
+	require.True(t, errors.Is(err, ErrStoreUnavailable))
+	require.True(t, errors.Is(err, context.Canceled))
+	require.False(t, ran)
+
+	err = actor.do(ctx, func(*Game) error { ran = true; return nil })
+	require.True(t, errors.Is(err, context.Canceled))
+	require.False(t, ran, "Nothing runs for a caller that has already gone")
+	require.Equal(t, 0, actor.queueDepth())
+}
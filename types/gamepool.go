@@ -1,23 +1,33 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 	
 	events "wordassassin/types/events"
 	persistence "wordassassin/persistence"
-	"wordassassin/slack"
 )
 
 // GamePoolAbstraction provides abstraction for testing GamePool dependencies
 type GamePoolAbstraction interface {
-	AddGame(game *Game) error
-	AddPlayerToGame(gameid string, ev events.PlayerAddedEvent) error
-	CanAddPlayers(gameid string) (bool, error)
+	AbortGame(ctx context.Context, gameid string, ev events.GameAbortedEvent) error
+	AddGame(ctx context.Context, game *Game) error
+	AddPlayerToGame(ctx context.Context, gameid string, ev events.PlayerAddedEvent) error
+	CanAddPlayers(ctx context.Context, gameid string) (bool, error)
 	GetGame(id string) (*Game, bool)
+	GetPlayer(ctx context.Context, playerid string) (*Player, error)
+	GetPlayers(ctx context.Context, gameid string) ([]*Player, error)
 	GetGamesList() []*Game
-	StartGame(gameid string, slackid slack.SlackID) error 
+	QueueDepth(gameid string) int
+	RemovePlayerFromGame(ctx context.Context, gameid string, ev events.PlayerRemovedEvent) error
+	ReportKill(ctx context.Context, gameid string, ev events.KillReportedEvent) error
+	StartGame(ctx context.Context, gameid string, ev events.GameStartedEvent) error
+	Subscribe(l GameListener)
 }
 
 const (
@@ -25,105 +35,235 @@ const (
 	GamesCollection    string = "games"
 )
 
-// GamePool manages the collection of games in a running server
+// GamePool manages the collection of games in a running server. It is safe for concurrent use: each game's commands
+// run in order on its own actor, and readers get copies
 type GamePool struct {
+	mu       sync.RWMutex // guards games, actors, draining and listeners
 	games 	 map[string]*Game
+	actors   map[string]*gameActor
+	draining bool
 	mongo 	 persistence.MongoAbstraction
+	store    *GameRepository
+	events   *EventRepository
 	players	 PlayerPoolAbstraction
+	playerStore *PlayerRepository // reloads players after a conflict
+	listeners []GameListener
 }
 
-// NewGamePool creates an instance with an initialized pool and pointer to the persistence layer
-func NewGamePool(m persistence.MongoAbstraction, pp PlayerPoolAbstraction) (result *GamePool) {
+// NewGamePool creates an instance with an initialized pool and pointer to the persistence layer, and reconstitutes any
+// games already there
+// Errors:
+// -- the stored games can't be read or decoded
+// -- two stored games share an ID
+func NewGamePool(ctx context.Context, m persistence.MongoAbstraction, pp PlayerPoolAbstraction) (*GamePool, error) {
 	games := make(map[string]*Game, 10)
-	result = &GamePool{
+	result := &GamePool{
 		games:	games,
+		actors:	make(map[string]*gameActor, 10),
 		mongo:	m,
+		store:	NewGameRepository(m),
+		events:	NewEventRepository(m),
+		playerStore: NewPlayerRepository(m),
 	}
 	result.players = pp
 
 	// Reconstitute from mongo automatically
-	// TODO: better error and logging on reconstitution issues
-	existingBytes, err := result.mongo.FetchAllFromCollection(GamesCollection)
-	if err != nil { panic(err) }
-	gamesList := bytesToGames(existingBytes)
-	err = result.ReconstitutePool(gamesList)
-	if err != nil { panic(err) }
-	//fmt.Printf("NewGamePool: Restored %d games\n", len(result.games))
-	return
+	gamesList, err := result.store.Find(ctx, AllGames())
+	if err != nil {
+		return nil, fmt.Errorf("NewGamePool: %w", err)
+	}
+	if err = result.ReconstitutePool(gamesList); err != nil {
+		return nil, fmt.Errorf("NewGamePool: %w", err)
+	}
+	return result, nil
 }
 
-// AddGame adds a game to this pool and persists the addition. Enforces uniqueness of the Game.ID within the pool
-func (pool *GamePool) AddGame(game *Game) error {
+// AbortGame calls off a game that hasn't finished, on behalf of the requestor named in the event, and logs the event.
+// Only the original game creator is allowed to abort a given gameid.
+// Errors returned:
+// -- gameid not exists, or already finished or aborted
+// -- requestor does not match the creating slackid
+// -- mongo issue
+func (pool *GamePool) AbortGame(ctx context.Context, gameid string, ev events.GameAbortedEvent) error {
+	return pool.withGame(ctx, gameid, func(game *Game) error {
+		if game == nil {
+			return errorf(ErrNotFound, "The requested GameID: %s doesn't exist on this server", gameid)
+		}
+		if game.GameCreator != ev.AbortedBy {
+			return errorf(ErrNotAuthorized, "GameID: %s cannot be aborted by non-creator. %s tried though", gameid, ev.AbortedBy)
+		}
+		snap := snapshotGame(game)
+		if err := game.Abort(); err != nil {
+			return fmt.Errorf("GameID: %s Abort failure: %w", gameid, err)
+		}
+		if err := pool.commitGame(ctx, snap, &ev); err != nil {
+			return fmt.Errorf("GameID: %s Abort failure: %w", gameid, err)
+		}
+		pool.publish(&ev, game)
+		return nil
+	})
+}
+
+// AddGame adds a game to this pool and persists the addition. Enforces uniqueness of the Game.ID within the pool.
+// The game only joins the pool once it's saved, so nothing can change it before then, and one that can't be saved
+// is left out altogether. The news of its creation goes out once it has joined, ahead of any other news of the game
+func (pool *GamePool) AddGame(ctx context.Context, game *Game) error {
 	if pool.games == nil {
 		return fmt.Errorf("uninitialized pool. Use NewGamePool")
 	}
 	if game.GetID() == "" {
-		return fmt.Errorf("missing ID for AddGame")
+		return errorf(ErrInvalidArgument, "missing ID for AddGame")
+	}
+	pool.mu.RLock()
+	draining := pool.draining
+	_, exists := pool.games[game.GetID()]
+	pool.mu.RUnlock()
+	if draining {
+		return errorf(ErrStoreUnavailable, "GamePool is shutting down. GameID: %s not added", game.GetID())
 	}
+	if exists {
+		return errorf(ErrDuplicate, "duplicate ID on add: %s", game.GetID())
+	}
+	if err := pool.store.Save(ctx, game); err != nil {
+		return err
+	}
+	pool.mu.Lock()
 	if err := pool.addGameToMap(game); err != nil {
+		pool.mu.Unlock()
 		return err
 	}
-	if err := pool.persistGame(game); err != nil {
+	// Queued before the pool is unlocked, so nothing else can reach the game's actor first
+	published, err := pool.actors[game.GetID()].send(persistence.Detach(ctx), func(game *Game) error {
+		pool.publish(game.createdEvent(), game)
+		return nil
+	})
+	pool.mu.Unlock()
+	if err != nil {
 		return err
 	}
-	return nil
+	return <-published
 }
 
-// AddPlayerToGame encapsulates whatever needs to happen when associating a new player with a game
+// AddPlayerToGame encapsulates whatever needs to happen when associating a new player with a game, and logs the event.
 // Note: with the current design, that really only means incrementing the player count, since the linkage
 // is from the player pool to the actual game, and not bi-directional
-func (pool *GamePool) AddPlayerToGame(gameid string, ev events.PlayerAddedEvent) error {
-	if accepting, err := pool.CanAddPlayers(gameid); !accepting {
-		return err
-	}
-	game, _ := pool.GetGame(gameid)
+// Errors returned:
+// -- gameid not exists or not in 'starting' state
+// -- player already added to the game, now or before (ErrDuplicate)
+// -- PlayerPool or mongo issue
+func (pool *GamePool) AddPlayerToGame(ctx context.Context, gameid string, ev events.PlayerAddedEvent) error {
+	return pool.withGame(ctx, gameid, func(game *Game) error {
+		if accepting, err := canAddPlayers(game, gameid); !accepting {
+			return err
+		}
 
-	// Create the Player instance and add to the PlayerPool
-	player := NewPlayerFromEvent(ev)
-	if addErr := pool.players.AddPlayer(&player); addErr != nil {
-		// Should catch all dups at the event level
-		if strings.Contains(addErr.Error(), "duplicate") {
-			return fmt.Errorf("PlayerPool: attempt to add duplicate player: %s in game: %s", player.GetID(), gameid)
-			
+		// Count the player first, so a game is never persisted with fewer players than the PlayerPool holds
+		snap := snapshotGame(game)
+		game.StartPlayers++
+		if err := pool.commitGame(ctx, snap, &ev); err != nil {
+			return fmt.Errorf("GameID: %s AddPlayer failure: %w", gameid, err)
 		}
-		return fmt.Errorf("PlayerPool: issue on AddPlayer add to : %s", addErr.Error())
-	}
-	// FIXME: Persist the pPool, or should it auto persist on state change?
 
-	game.StartPlayers++
-	return nil
+		// Create the Player instance and add to the PlayerPool
+		player := NewPlayerFromEvent(ev)
+		if addErr := pool.players.AddPlayer(ctx, &player); addErr != nil {
+			pool.uncommit(ctx, snap, &ev)
+			// Should catch all dups at the event level
+			if errors.Is(addErr, ErrDuplicate) {
+				return errorf(ErrDuplicate, "PlayerPool: attempt to add duplicate player: %s in game: %s", player.GetID(), gameid)
+			}
+			return fmt.Errorf("PlayerPool: issue on AddPlayer add to : %w", addErr)
+		}
+		pool.publish(&ev, game, &player)
+		return nil
+	})
 }
 
 // CanAddPlayers validates that a game exists and is in the proper state to accept new players
-func (pool *GamePool) CanAddPlayers(gameid string) (accepting bool, err error) {
+func (pool *GamePool) CanAddPlayers(ctx context.Context, gameid string) (accepting bool, err error) {
+	err = pool.withGame(ctx, gameid, func(game *Game) (canErr error) {
+		accepting, canErr = canAddPlayers(game, gameid)
+		return
+	})
+	return accepting && err == nil, err
+}
+
+// canAddPlayers is CanAddPlayers for a game on its actor. The game is nil if it doesn't exist
+func canAddPlayers(game *Game, gameid string) (accepting bool, err error) {
 	accepting = true
-	game, exists := pool.GetGame(gameid)
-	if !exists {
-		err = fmt.Errorf("The requested GameID: %s doesn't exist on this server", gameid)
+	if game == nil {
+		err = errorf(ErrNotFound, "The requested GameID: %s doesn't exist on this server", gameid)
 		accepting = false
 	} else if game.Status != Starting {
-		err = fmt.Errorf("The requested GameID: %s is not accepting players. State=%s", gameid, game.Status)
+		err = errorf(ErrInvalidState, "The requested GameID: %s is not accepting players. State=%s", gameid, game.Status)
 		accepting = false
 	}
 	return
 }
 
-// GetGame gets the game specified by the requested ID.
+// GetGame gets a copy of the game specified by the requested ID. Changes to the copy don't affect the pool.
 // Returns:
 // -- the game object for that ID
 // -- true for exists if it does, false if it don't
-func (pool *GamePool) GetGame(id string) (*Game, bool) {
-	game, exists := pool.games[id]
-	if exists {
-		return game, true
-	}
-	return nil, false
+func (pool *GamePool) GetGame(id string) (result *Game, exists bool) {
+	pool.withGame(context.Background(), id, func(game *Game) error {
+		if game != nil {
+			copied := *game
+			result, exists = &copied, true
+		}
+		return nil
+	})
+	return
+}
+
+// GetPlayer gets a copy of the player specified by the requested ID, in whichever game they're in
+func (pool *GamePool) GetPlayer(ctx context.Context, playerid string) (result *Player, err error) {
+	err = pool.withGame(ctx, gameIDOf(playerid), func(*Game) error {
+		player, err := pool.players.GetPlayerByID(playerid)
+		if err != nil {
+			return err
+		}
+		copied := *player
+		result = &copied
+		return nil
+	})
+	return
+}
+
+// GetPlayers gets copies of every player in a game, sorted by ID. Changes to the copies don't affect the pool.
+// Errors:
+// -- gameid does not exist
+func (pool *GamePool) GetPlayers(ctx context.Context, gameid string) (result []*Player, err error) {
+	err = pool.withGame(ctx, gameid, func(game *Game) error {
+		if game == nil {
+			return errorf(ErrNotFound, "The requested GameID: %s doesn't exist on this server", gameid)
+		}
+		players, err := pool.playersInGame(gameid)
+		if err != nil {
+			return err
+		}
+		for _, p := range players {
+			copied := *p
+			result = append(result, &copied)
+		}
+		return nil
+	})
+	sort.Slice(result, func(i, j int) bool { return result[i].GetID() < result[j].GetID() })
+	return
 }
 
 // GetGamesList gives a list of each game ID separated by a newline. The result are sorted chronologically by created time
 func (pool *GamePool) GetGamesList() (result []*Game) {
-	for _, v := range pool.games {
-		result = append(result, v)
+	pool.mu.RLock()
+	ids := make([]string, 0, len(pool.games))
+	for id := range pool.games {
+		ids = append(ids, id)
+	}
+	pool.mu.RUnlock()
+	for _, id := range ids {
+		if game, exists := pool.GetGame(id); exists {
+			result = append(result, game)
+		}
 	}
 	sort.SliceStable(result,
 		func(i, j int) bool {
@@ -134,6 +274,8 @@ func (pool *GamePool) GetGamesList() (result []*Game) {
 
 // ReconstitutePool rebuilds a new GamePool from an array of Games
 func (pool *GamePool) ReconstitutePool(games []*Game) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
 	for _, game := range games {
 		if err := pool.addGameToMap(game); err != nil {
 			return err
@@ -142,63 +284,386 @@ func (pool *GamePool) ReconstitutePool(games []*Game) error {
 	return nil
 }
 
-// StartGame calls the start sequence for the specified game on behalf of requestor.
+// RemovePlayerFromGame takes a player back out of a game that hasn't started yet, and logs the event
+// Errors returned:
+// -- gameid not exists or not in 'starting' state
+// -- player not in the game
+// -- PlayerPool or mongo issue
+func (pool *GamePool) RemovePlayerFromGame(ctx context.Context, gameid string, ev events.PlayerRemovedEvent) error {
+	return pool.withGame(ctx, gameid, func(game *Game) error {
+		if accepting, err := canAddPlayers(game, gameid); !accepting {
+			return err
+		}
+		players, err := pool.players.GetAllPlayersInGame(gameid)
+		if err != nil {
+			return fmt.Errorf("GameID: %s RemovePlayer failure. PlayerPool: %w", gameid, err)
+		}
+		var player *Player
+		for _, p := range players {
+			if p.GetID() == ev.PlayerID {
+				player = p
+			}
+		}
+		if player == nil {
+			return errorf(ErrNotFound, "Player %s is not in game %s", ev.PlayerID, gameid)
+		}
+
+		snap := snapshotGame(game)
+		game.StartPlayers--
+		if err = pool.commitGame(ctx, snap, &ev); err != nil {
+			return fmt.Errorf("GameID: %s RemovePlayer failure: %w", gameid, err)
+		}
+		if err = pool.players.RemovePlayer(ctx, player); err != nil {
+			pool.uncommit(ctx, snap, &ev)
+			return fmt.Errorf("GameID: %s RemovePlayer failure. PlayerPool: %w", gameid, err)
+		}
+		pool.publish(&ev, game, player)
+		return nil
+	})
+}
+
+// ReportKill applies a reported assassination to the specified game. The victim is identified by the event, and
+// their assassin is derived from the current target assignments. The event is only written once the kill is
+// applied, so the log has a game's kills in the order they happened even when they were reported at the same time.
+// A kill that ends the game is followed in the log by the game's completion.
+// Errors returned:
+// -- gameid not exists or not in 'playing' state
+// -- PlayerPool failure
+// -- victim not alive in this game (ErrDuplicate when already dead), or no assassin found for them
+// -- mongo issue, including the kill event already being in the log
+func (pool *GamePool) ReportKill(ctx context.Context, gameid string, ev events.KillReportedEvent) error {
+	return pool.withGame(ctx, gameid, func(game *Game) error {
+		if game == nil {
+			return errorf(ErrNotFound, "The requested GameID: %s doesn't exist on this server", gameid)
+		}
+		if game.Status != Playing {
+			return errorf(ErrInvalidState, "The requested GameID: %s is not in play. State=%s", gameid, game.Status)
+		}
+		players, err := pool.players.GetAllPlayersInGame(game.GetID())
+		if err != nil {
+			return fmt.Errorf("GameID: %s ReportKill failure. PlayerPool: %w", gameid, err)
+		}
+		if err = pool.attachKillDictionary(ctx, game, players); err != nil {
+			return fmt.Errorf("GameID: %s ReportKill failure: %w", gameid, err)
+		}
+		snap := snapshotGame(game, players...)
+		assassin, err := game.RecordKill(ev.PlayerID, players)
+		if err != nil {
+			return fmt.Errorf("GameID: %s ReportKill failure: %w", gameid, err)
+		}
+		evs, err := targetEvents([]events.GameEvent{&ev}, game, assassin)
+		if err == nil {
+			evs, err = completedEvents(evs, game)
+		}
+		if err != nil {
+			snap.restore()
+			return fmt.Errorf("GameID: %s ReportKill failure: %w", gameid, err)
+		}
+		if err = pool.commitGame(ctx, snap, evs...); err != nil {
+			return fmt.Errorf("GameID: %s ReportKill failure: %w", gameid, err)
+		}
+		pool.publish(&ev, game, snap.changedPlayers()...)
+		return nil
+	})
+}
+
+// StartGame calls the start sequence for the specified game on behalf of the requestor named in the event, and logs
+// the event ahead of the targets dealt. The game is seeded from the event, so the same event always deals the same
+// targets.
 // Only the original game creator is allowed to start a given gameid.
 // Errors returned:
 // -- gameid or creator empty
 // -- gameid not exists and in 'starting' state
 // -- slackid does not match the creating slackid
-func (pool *GamePool) StartGame(gameid string, creator slack.SlackID) error {
+func (pool *GamePool) StartGame(ctx context.Context, gameid string, ev events.GameStartedEvent) error {
+	creator := ev.StartedBy
 	if gameid == "" || creator == "" {
-		return fmt.Errorf("Game start requires a non-empty game ID and creator ID")
+		return errorf(ErrInvalidArgument, "Game start requires a non-empty game ID and creator ID")
 	}
-	game, exists := pool.GetGame(gameid)
-	if !exists {
-		return fmt.Errorf("The requested GameID: %s doesn't exist on this server", gameid)
+	return pool.withGame(ctx, gameid, func(game *Game) error {
+		if game == nil {
+			return errorf(ErrNotFound, "The requested GameID: %s doesn't exist on this server", gameid)
+		}
+		if game.Status != Starting {
+			return errorf(ErrInvalidState, "The requested GameID: %s is not accepting players. State=%s", gameid, game.Status)
+		}
+		if game.GameCreator != creator {
+			return errorf(ErrNotAuthorized, "GameID: %s cannot be started by non-creator. %s tried though", gameid, creator)
+		}
+		players, err := pool.players.GetAllPlayersInGame(game.GetID())
+		if err != nil {
+			return fmt.Errorf("GameID: %s Start failure. PlayerPool: %w", gameid, err)
+		}
+		if err = pool.attachKillDictionary(ctx, game, players); err != nil {
+			return fmt.Errorf("GameID: %s Start failure: %w", gameid, err)
+		}
+		snap := snapshotGame(game, players...)
+		game.Seed(ev.Seed)
+		if err = game.Start(players); err != nil {
+			return err
+		}
+		evs, err := targetEvents([]events.GameEvent{&ev}, game, players...)
+		if err != nil {
+			snap.restore()
+			return fmt.Errorf("GameID: %s Start failure: %w", gameid, err)
+		}
+		if err = pool.commitGame(ctx, snap, evs...); err != nil {
+			return fmt.Errorf("GameID: %s Start failure: %w", gameid, err)
+		}
+		pool.publish(&ev, game, players...)
+		return nil
+	})
+}
+
+// attachKillDictionary loads the game's KillDictionary from mongo, unless it is already loaded. After a restart
+// the words in use are recovered from the players.
+func (pool *GamePool) attachKillDictionary(ctx context.Context, game *Game, players []*Player) error {
+	if game.dict != nil {
+		return nil
 	}
-	if game.Status != Starting {
-		return fmt.Errorf("The requested GameID: %s is not accepting players. State=%s", gameid, game.Status)
+	kd, err := LoadKillDictionary(ctx, pool.mongo, game.KillDictionary)
+	if err != nil {
+		return err
 	}
-	if game.GameCreator != creator {
-		return fmt.Errorf("GameID: %s cannot be started by non-creator. %s tried though", gameid, creator)
+	game.SetKillDictionary(kd, players...)
+	return nil
+}
+
+// Drain stops the pool taking commands, and waits for every game to finish the ones already queued. Commands sent
+// after this fail. Use on shutdown, once the server has stopped taking requests.
+func (pool *GamePool) Drain() {
+	pool.mu.Lock()
+	pool.draining = true
+	actors := make([]*gameActor, 0, len(pool.actors))
+	for _, a := range pool.actors {
+		actors = append(actors, a)
 	}
-	players, err := pool.players.GetAllPlayersInGame(game.GetID())
-	if err != nil {
-		return fmt.Errorf("GameID: %s Start failure. PlayerPool: %v", gameid, err)
+	pool.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, a := range actors {
+		wg.Add(1)
+		go func(a *gameActor) {
+			defer wg.Done()
+			a.drain()
+		}(a)
 	}
-	return game.Start(players)
+	wg.Wait()
 }
 
+// QueueDepth reports how many commands the game has waiting or running. Zero for games that don't exist
+func (pool *GamePool) QueueDepth(gameid string) int {
+	pool.mu.RLock()
+	a, exists := pool.actors[gameid]
+	pool.mu.RUnlock()
+	if !exists {
+		return 0
+	}
+	return a.queueDepth()
+}
+
+// addGameToMap adds the game, and starts its actor. The caller holds pool.mu
 func (pool *GamePool) addGameToMap(game *Game) error {
 	if _, exists := pool.games[game.GetID()]; exists {
-		return fmt.Errorf("duplicate ID on add: %s", game.GetID())
+		return errorf(ErrDuplicate, "duplicate ID on add: %s", game.GetID())
+	}
+	if pool.actors == nil {
+		pool.actors = make(map[string]*gameActor, 10)
 	}
 	pool.games[game.GetID()] = game
+	pool.actors[game.GetID()] = newGameActor(game)
 	return nil
 }
 
-// turn a bson array of bytes into an array of Game instances
-func bytesToGames(inBytes [][]byte) []*Game {
-    ret := make([]*Game, len(inBytes))
+// withGame runs the command on the game's actor and waits for the result. The command is handed the live game, and
+// is the only thing touching it until it returns, while other games' commands proceed in parallel. When the game
+// doesn't exist the command runs right away, with nil. A ctx that is done before the command's turn comes drops it,
+// and one done part way through has the store give up on it. Either way the game is left as it was.
+func (pool *GamePool) withGame(ctx context.Context, gameid string, cmd func(game *Game) error) error {
+	pool.mu.RLock()
+	a, exists := pool.actors[gameid]
+	pool.mu.RUnlock()
+	if !exists {
+		return cmd(nil)
+	}
+	return a.do(ctx, cmd)
+}
+
+// gameIDOf recovers the game from a player ID, which is the game ID and slack ID joined by a '+'
+func gameIDOf(playerid string) string {
+	if i := strings.LastIndex(playerid, "+"); i >= 0 {
+		return playerid[:i]
+	}
+	return playerid
+}
+
+// gameSnapshot holds copies of a game and its players from before a change, so that a change that can't be
+// persisted can be rolled back
+type gameSnapshot struct {
+	game    *Game
+	before  Game
+	players []*Player
+	saved   []Player
+}
+
+func snapshotGame(game *Game, players ...*Player) (snap gameSnapshot) {
+	snap = gameSnapshot{game: game, before: *game, players: players, saved: make([]Player, len(players))}
+	// Copying the game shares its map of used words, so the words are copied too. Otherwise a rolled back change
+	// would leave the words it drew spent
+	if game.usedWords != nil {
+		snap.before.usedWords = make(map[string]bool, len(game.usedWords))
+		for word := range game.usedWords {
+			snap.before.usedWords[word] = true
+		}
+	}
+	for i, p := range players {
+		snap.saved[i] = *p
+	}
+	return
+}
 
-    for i, b := range inBytes {
-		ret[i] = &Game{}
-        if err := ret[i].Decode(b); err != nil {
-			panic( "bytesToGames: " + err.Error())
+// changedPlayers lists the players that differ from the snapshot
+func (snap gameSnapshot) changedPlayers() (changed []*Player) {
+	for i, p := range snap.players {
+		if *p != snap.saved[i] {
+			changed = append(changed, p)
 		}
-    }
+	}
+	return
+}
 
-    return ret
+// rollback puts the game and its players back to the snapshot, in memory and then in mongo. Rewriting mongo is
+// best effort, since it follows a failed write. It goes ahead even when ctx is done, which may be why the write failed
+// Each keeps the version it is at now, so the rewrite only lands where this change was written. Anything that was
+// changed by someone else is left to them
+func (snap gameSnapshot) rollback(ctx context.Context, pool *GamePool) {
+	changed := snap.changedPlayers()
+	snap.restore()
+	ctx = persistence.Detach(ctx)
+	pool.store.Update(ctx, snap.game)
+	pool.players.UpdatePlayers(ctx, changed...)
 }
 
-func (pool *GamePool) persistGame(game *Game) error {
-	if mongoErr := pool.mongo.WriteCollection("games", game); mongoErr != nil {
-		return mongoErr
+// restore puts the game and its players back to the snapshot in memory only, which is all a change that was never
+// written needs. Each keeps the version it is at now
+func (snap gameSnapshot) restore() {
+	version := snap.game.Version
+	*snap.game = snap.before
+	snap.game.Version = version
+	for i, p := range snap.players {
+		version = p.Version
+		*p = snap.saved[i]
+		p.Version = version
 	}
+}
 
+// abandon rolls back a change that couldn't be committed. After a conflict the pool is behind the store as well, so
+// the game is then reloaded, ready for the change to be tried again
+func (pool *GamePool) abandon(ctx context.Context, snap gameSnapshot, cause error) {
+	snap.rollback(ctx, pool)
+	if errors.Is(cause, ErrConflict) {
+		// Best effort. If the game can't be reloaded now, the next change to it conflicts and tries again
+		pool.refresh(ctx, snap.game)
+	}
+}
+
+// refresh reloads a game and its players from the store. Players are reloaded in place, so the PlayerPool keeps
+// handing out the same ones. Like rollback, it goes ahead even when ctx is done
+func (pool *GamePool) refresh(ctx context.Context, game *Game) error {
+	ctx = persistence.Detach(ctx)
+	stored, err := pool.store.FindID(ctx, game.GetID())
+	if err != nil {
+		return err
+	}
+	players, err := pool.players.GetAllPlayersInGame(game.GetID())
+	if err != nil {
+		return err
+	}
+	for _, p := range players {
+		storedPlayer, err := pool.playerStore.FindID(ctx, p.GetID())
+		if err != nil {
+			return err
+		}
+		*p = *storedPlayer
+	}
+	// The words in use may have changed along with the players
+	dict, rnd := game.dict, game.rnd
+	*game = *stored
+	game.rnd = rnd
+	if dict != nil {
+		game.SetKillDictionary(dict, players...)
+	}
+	return nil
+}
+
+// commitGame persists the game, and any of its players that changed, since the snapshot was taken, followed by the
+// events that record the change. The events are stamped first with the time and the next places in the game's log,
+// so the log follows the order the game's actor made its changes in. If anything fails to persist, the whole change
+// is rolled back. Games and players are versioned in the store, so one changed elsewhere since the pool read it isn't
+// written over: the commit fails with ErrConflict instead, and the game is reloaded so that trying again can succeed.
+func (pool *GamePool) commitGame(ctx context.Context, snap gameSnapshot, evs ...events.GameEvent) error {
+	now := time.Now()
+	for _, ev := range evs {
+		snap.game.EventSeq++
+		ev.Stamp(now, snap.game.EventSeq)
+	}
+	changed := snap.changedPlayers()
+	if err := pool.store.Update(ctx, snap.game); err != nil {
+		pool.abandon(ctx, snap, err)
+		return err
+	}
+	if err := pool.players.UpdatePlayers(ctx, changed...); err != nil {
+		pool.abandon(ctx, snap, err)
+		return fmt.Errorf("PlayerPool: %w", err)
+	}
+	for i, ev := range evs {
+		if err := pool.events.Save(ctx, ev); err != nil {
+			pool.uncommit(ctx, snap, evs[:i]...)
+			return fmt.Errorf("Mongodb issue on event write: %w", err)
+		}
+	}
 	return nil
 }
 
+// uncommit backs out a committed change when the step that follows it fails. The events are deleted from the log, and
+// the game and its players are rolled back. Like rollback, it is best effort, and goes ahead even when ctx is done
+func (pool *GamePool) uncommit(ctx context.Context, snap gameSnapshot, evs ...events.GameEvent) {
+	for _, ev := range evs {
+		pool.events.Delete(persistence.Detach(ctx), ev.GetID())
+	}
+	snap.rollback(ctx, pool)
+}
+
+// targetEvents adds to evs the current target of each player that has one
+// Errors:
+// -- a target can't be recorded, such as one without a kill word
+func targetEvents(evs []events.GameEvent, game *Game, players ...*Player) ([]events.GameEvent, error) {
+	for _, p := range players {
+		if p.Target == "" {
+			continue
+		}
+		ev, err := events.NewTargetAssignedEvent(game.GetID(), p.GetID(), p.Target, p.KillWord)
+		if err != nil {
+			return evs, fmt.Errorf("Target of %s not recorded: %w", p.GetID(), err)
+		}
+		evs = append(evs, &ev)
+	}
+	return evs, nil
+}
+
+// completedEvents adds to evs the winner of the game, once it has finished
+// Errors:
+// -- the game finished without a winner
+func completedEvents(evs []events.GameEvent, game *Game) ([]events.GameEvent, error) {
+	if game.Status != Finished {
+		return evs, nil
+	}
+	ev, err := events.NewGameCompletedEvent(game.GetID(), game.Winner, game.StartTime)
+	if err != nil {
+		return evs, fmt.Errorf("Completion not recorded: %w", err)
+	}
+	return append(evs, &ev), nil
+}
+
 // Get all of the players for a given gameid. 
 func (pool *GamePool) playersInGame(gameid string) (playersInGame []*Player, err error) {
 	return pool.players.GetAllPlayersInGame(gameid)
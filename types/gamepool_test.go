@@ -1,8 +1,11 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
 	"time"
 
@@ -46,6 +49,26 @@ func TestNewGamePoolWithEmptyPreexistingList(t *testing.T) {
 	require.NotNil(t, target)
 }
 
+func TestNewGamePool_RestoreFails(t *testing.T) {
+	t.Run("Store unavailable", func(t *testing.T) {
+		mm := &persistence.MockMongoSession{ConnectMode: "positive", QueryMode: "fail"}
+		target, err := NewGamePool(context.Background(), mm, &PlayerPool{})
+		require.Nil(t, target)
+		require.True(t, errors.Is(err, ErrStoreUnavailable))
+		require.Contains(t, err.Error(), "NewGamePool:")
+	})
+	t.Run("Duplicate IDs", func(t *testing.T) {
+		mm := &persistence.MockMongoSession{ConnectMode: "positive", QueryMode: "positive"}
+		mm.FetchResults = []persistence.Persistable{
+			&Game{ID: "Twin", GameCreator: slack.SlackID("UONE"), Status: Starting},
+			&Game{ID: "Twin", GameCreator: slack.SlackID("UTWO"), Status: Starting},
+		}
+		target, err := NewGamePool(context.Background(), mm, &PlayerPool{})
+		require.Nil(t, target)
+		require.Error(t, err)
+	})
+}
+
 func TestGetGameFunc(t *testing.T) {
 	// Setup
 	target, _ := getGamePoolWithMockMongo(t, nil)
@@ -80,7 +103,7 @@ func TestGetGameFunc(t *testing.T) {
 }
 
 func TestAddGame(t *testing.T) {
-	target, _ := getGamePoolWithMockMongo(t, nil)
+	target, mm := getGamePoolWithMockMongo(t, nil)
 	t.Run("Positive", func(t *testing.T) {
 		addGameToPool(t, target, "add1", "Utest", "dict", "youshallnot", 0)
 	})
@@ -98,42 +121,188 @@ func TestAddGame(t *testing.T) {
 			GameCreator:    "Ucreator",
 			KillDictionary: "dict",
 		})
-		err := target.AddGame(&badEvent)
+		err := target.AddGame(context.Background(), &badEvent)
 		require.Errorf(t, err, "Missing ID should throw")
 		require.Contains(t, err.Error(), "missing ID")
 	})
+	t.Run("Game fails to persist", func(t *testing.T) {
+		mm.WriteMode = "fail"
+		addGameToPool(t, target, "unsaved", "Utest", "dict", "youshallnot", 0, "Mock error on write")
+		mm.WriteMode = "positive"
+		_, exists := target.GetGame("unsaved")
+		require.False(t, exists, "A game that can't be saved stays out of the pool")
+		require.Zero(t, target.QueueDepth("unsaved"))
+		addGameToPool(t, target, "unsaved", "Utest", "dict", "youshallnot", 0)
+		_, exists = target.GetGame("unsaved")
+		require.True(t, exists, "Trying again can succeed")
+	})
 }
 
 func TestAddPlayerToGame(t *testing.T) {
 	myGameID := "playeradderer"
 	mockPP := &MockPlayerPool{}
-	target, _ := getGamePoolWithMockMongo(t, mockPP)
+	target, mm := getGamePoolWithMockMongo(t, mockPP)
 	require.NotNil(t, target)
 	gm := addGameToPool(t, target, myGameID, "Uplayervacuum", "a file", "pass", 1)
 
 	t.Run("Positive", func(t *testing.T) {
-		err := target.AddPlayerToGame(myGameID, events.PlayerAddedEvent{ ID: "yo"})
+		err := target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "yo"})
 		require.NoError(t, err, "Positive tests throw no errors")
 		require.Equal(t, gm.StartPlayers, 2, "StartingPlayer count should increment on player add")
 	})
 	t.Run("Missing game", func(t *testing.T) {
-		err := target.AddPlayerToGame("Who, me?", events.PlayerAddedEvent{})
+		err := target.AddPlayerToGame(context.Background(), "Who, me?", events.PlayerAddedEvent{})
 		require.Error(t, err, "Should get an error on the game id check failure")
 		require.Contains(t, err.Error(), "GameID: Who, me? doesn't exist", "Tell us why it broke")
+		require.True(t, errors.Is(err, ErrNotFound))
 	})
 	t.Run("Duplicate player", func(t *testing.T){
 		mockPP.AddPlayerError = "mock error: duplicate ID"
-		err := target.AddPlayerToGame(myGameID, events.PlayerAddedEvent{ ID: "whatev"})
+		mockPP.ErrorKind = ErrDuplicate
+		defer func() { mockPP.ErrorKind = nil }()
+		err := target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "whatev"})
 		require.Error(t, err, "Should get an error on the game id check failure")
 		expectedErr := fmt.Sprintf("PlayerPool: attempt to add duplicate player: %s in game: %s", "whatev", myGameID)
 		require.Contains(t, err.Error(), expectedErr, "Tell us why it broke")
+		require.True(t, errors.Is(err, ErrDuplicate), "Duplicates are told apart by kind, not message")
 	})
 	t.Run("PlayerPool issue (not duplicate)", func(t *testing.T){
 		mockPP.AddPlayerError = "mock error: bad bad stuff happened"
-		err := target.AddPlayerToGame(myGameID, events.PlayerAddedEvent{ ID: "whatev"})
+		err := target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "whatev"})
 		require.Error(t, err, "Should get an error on the game id check failure")
 		require.Contains(t, err.Error(), "PlayerPool: ", "Tell us where it broke")
 		require.Contains(t, err.Error(), mockPP.AddPlayerError, "Tell us what broke")
+		require.Equal(t, 2, gm.StartPlayers, "Player count is rolled back when the player can't be added")
+		mockPP.AddPlayerError = ""
+	})
+	t.Run("Game persisted", func(t *testing.T){
+		mm.Updated = nil
+		require.NoError(t, target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "saved"}))
+		require.Equal(t, []string{ GamesCollection + "/" + myGameID }, mm.Updated)
+	})
+	t.Run("Game fails to persist", func(t *testing.T){
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		err := target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "unsaved"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "AddPlayer failure: Mock error on update")
+		require.Equal(t, 3, gm.StartPlayers, "Player count is unchanged when the game can't be saved")
+	})
+	t.Run("Event logged", func(t *testing.T){
+		mm.Written = nil
+		require.NoError(t, target.AddPlayerToGame(context.Background(), myGameID, events.PlayerAddedEvent{ ID: "logged"}))
+		require.Equal(t, []string{ EventsCollection + "/logged" }, mm.Written)
+	})
+}
+
+// TestAddPlayerToGame_BackedOut checks a join the PlayerPool turns down leaves nothing in the event log for replay to
+// trip over
+func TestAddPlayerToGame_BackedOut(t *testing.T) {
+	store := persistence.NewMemorySession()
+	target := newGamePool(t, store, &MockPlayerPool{ AddPlayerError: "mock error: no room" })
+	addGameToPool(t, target, "racy", "UdaStarter", "wordz", "MickJ", 0)
+	err := target.AddPlayerToGame(context.Background(), "racy", events.NewPlayerAddedInline("racy", "ULATE", "", ""))
+	require.Error(t, err)
+	logged, err := NewEventRepository(store).Find(context.Background(), AllEvents().ForGame("racy"))
+	require.NoError(t, err)
+	require.Empty(t, logged, "The PlayerAddedEvent is backed out")
+	game, _ := target.GetGame("racy")
+	require.Zero(t, game.EventSeq, "The game's log is where it was")
+}
+
+// TestAddPlayerToGame_Rejoin checks a player who left can't join the same game again, since their join is still in
+// the event log
+func TestAddPlayerToGame_Rejoin(t *testing.T) {
+	ctx := context.Background()
+	store := persistence.NewMemorySession()
+	target := newGamePool(t, store, newPlayerPool(t, store))
+	game := addGameToPool(t, target, "revolving", "UdaStarter", "wordz", "MickJ", 0)
+	require.NoError(t, target.AddPlayerToGame(ctx, "revolving", events.NewPlayerAddedInline("revolving", "UBACK", "", "")))
+	require.NoError(t, target.RemovePlayerFromGame(ctx, "revolving", events.NewPlayerRemovedInline("revolving", "UBACK")))
+	err := target.AddPlayerToGame(ctx, "revolving", events.NewPlayerAddedInline("revolving", "UBACK", "", ""))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrDuplicate))
+	require.Equal(t, 0, game.StartPlayers)
+}
+
+func TestRemovePlayerFromGame(t *testing.T) {
+	myGameID := "shrinking"
+	pp := newPlayerPool(t, persistence.NewMockMongoSession())
+	target, mm := getGamePoolWithMockMongo(t, pp)
+	game := addGameToPool(t, target, myGameID, "UdaStarter", "wordz", "MickJ", 0)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, target.AddPlayerToGame(context.Background(), myGameID, events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Uquit%d", i), "", "")))
+	}
+
+	t.Run("Positive", func(t *testing.T) {
+		require.NoError(t, target.RemovePlayerFromGame(context.Background(), myGameID, events.NewPlayerRemovedInline(myGameID, "Uquit0")))
+		require.Equal(t, 2, game.StartPlayers)
+		_, err := pp.GetPlayer(myGameID, slack.SlackID("Uquit0"))
+		require.Error(t, err, "Removed players leave the pool")
+	})
+	t.Run("Not in game", func(t *testing.T) {
+		err := target.RemovePlayerFromGame(context.Background(), myGameID, events.NewPlayerRemovedInline(myGameID, "Uquit0"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Player shrinking+Uquit0 is not in game shrinking")
+	})
+	t.Run("Game fails to persist", func(t *testing.T) {
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		err := target.RemovePlayerFromGame(context.Background(), myGameID, events.NewPlayerRemovedInline(myGameID, "Uquit1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "RemovePlayer failure: Mock error on update")
+		require.Equal(t, 2, game.StartPlayers)
+	})
+	t.Run("PlayerPool issue", func(t *testing.T) {
+		mockPP := &MockPlayerPool{ playersToReturn: makePlayerList(t, "mocked", 2), RemovePlayerError: "mock error: stuck" }
+		mockTarget, _ := getGamePoolWithMockMongo(t, mockPP)
+		gm := addGameToPool(t, mockTarget, "mocked", "Umock", "wordz", "MickJ", 2)
+		err := mockTarget.RemovePlayerFromGame(context.Background(), "mocked", events.NewPlayerRemovedInline("mocked", "Uname0"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "PlayerPool: mock error: stuck")
+		require.Equal(t, 2, gm.StartPlayers, "Player count is rolled back")
+	})
+	t.Run("Game already started", func(t *testing.T) {
+		game.Status = Playing
+		defer func() { game.Status = Starting }()
+		err := target.RemovePlayerFromGame(context.Background(), myGameID, events.NewPlayerRemovedInline(myGameID, "Uquit1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not accepting players")
+		require.True(t, errors.Is(err, ErrInvalidState))
+	})
+}
+
+func TestAbortGame(t *testing.T) {
+	myCreator := slack.NewInline("UdaStarter")
+	target, mm := getGamePoolWithMockMongo(t, nil)
+	game := addGameToPool(t, target, "doomed", myCreator.ToString(), "wordz", "MickJ", 0)
+
+	t.Run("Non-creator", func(t *testing.T) {
+		err := target.AbortGame(context.Background(), "doomed", events.NewGameAbortedInline("doomed", slack.NewInline("UNOTME")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot be aborted by non-creator")
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+	})
+	t.Run("Missing game", func(t *testing.T) {
+		err := target.AbortGame(context.Background(), "Who, me?", events.NewGameAbortedInline("Who, me?", myCreator))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "GameID: Who, me? doesn't exist")
+	})
+	t.Run("Game fails to persist", func(t *testing.T) {
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		err := target.AbortGame(context.Background(), "doomed", events.NewGameAbortedInline("doomed", myCreator))
+		require.Error(t, err)
+		require.Equal(t, Starting, game.Status)
+	})
+	t.Run("Positive", func(t *testing.T) {
+		require.NoError(t, target.AbortGame(context.Background(), "doomed", events.NewGameAbortedInline("doomed", myCreator)))
+		require.Equal(t, Aborted, game.Status)
+	})
+	t.Run("Already aborted", func(t *testing.T) {
+		err := target.AbortGame(context.Background(), "doomed", events.NewGameAbortedInline("doomed", myCreator))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Game can't be aborted. Current state is aborted")
 	})
 }
 
@@ -145,18 +314,18 @@ func TestCanAddPlayer(t *testing.T) {
 	gm.Status = Playing
 
 	t.Run("Positive", func(t *testing.T) {
-		result, err := target.CanAddPlayers("good")
+		result, err := target.CanAddPlayers(context.Background(), "good")
 		require.True(t, result, "Game should be accepting players")
 		require.NoError(t, err, "Error should not be set for true return value")
 	})
 	t.Run("Game not found", func(t *testing.T) {
-		result, err := target.CanAddPlayers("missingGame")
+		result, err := target.CanAddPlayers(context.Background(), "missingGame")
 		require.False(t, result, "Missing game should not be accepting players")
 		require.Error(t, err, "Error should be set for false return value")
 		require.Contains(t, err.Error(), "missingGame doesn't exist", "Error message should mention missing gameid")
 	})
 	t.Run("Game state not Starting", func(t *testing.T) {
-		result, err := target.CanAddPlayers("playingGame")
+		result, err := target.CanAddPlayers(context.Background(), "playingGame")
 		require.False(t, result, "Game not in Starting state should not be accepting players")
 		require.Error(t, err, "Error should be set for false return value")
 		require.Contains(t, err.Error(), "playingGame is not accepting players. State=playing", "Error message should mention incorrect state")
@@ -189,6 +358,236 @@ func TestReconstitutePool(t *testing.T) {
 	})
 }
 
+func TestReportKill(t *testing.T) {
+	myGameID := "hitlist"
+	myCreator := slack.NewInline("UdaStarter")
+	pp := &PlayerPool{}
+	target, mm := getGamePoolWithMockMongo(t, pp)
+	mm.CollectionResults = map[string][]persistence.Persistable{ CollectionName: mockKillWords(t, "wordz", 20) }
+	game := addGameToPool(t, target, myGameID, myCreator.ToString(), "wordz", "MickJ", 0)
+	for i := 0; i < 5; i++ {
+		ev := events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Uhit%d", i), "", "")
+		require.NoError(t, target.AddPlayerToGame(context.Background(), myGameID, ev))
+	}
+	t.Run("Not in play", func(t *testing.T) {
+		err := target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit0"))
+		require.Error(t, err, "Can't kill anyone before the game starts")
+		require.Contains(t, err.Error(), "is not in play. State=starting")
+		require.True(t, errors.Is(err, ErrInvalidState))
+	})
+	require.NoError(t, target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator)))
+
+	t.Run("Positive", func(t *testing.T) {
+		victim, _ := pp.GetPlayer(myGameID, slack.SlackID("Uhit0"))
+		victimTarget, victimWord := victim.Target, victim.KillWord
+		err := target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit0"))
+		require.NoError(t, err)
+		require.Equal(t, Dead, victim.Status)
+		assassin, err := pp.GetPlayerByID(victim.KilledBy)
+		require.NoError(t, err, "Victim should record their assassin")
+		require.Equal(t, victimTarget, assassin.Target)
+		require.NotEqual(t, victimWord, assassin.KillWord, "Assassin draws a fresh word for the new target")
+		require.NotEqual(t, victim.KilledWith, assassin.KillWord, "Assassin draws a fresh word for the new target")
+		require.Equal(t, 1, assassin.Kills)
+		require.Equal(t, 4, game.RemainPlayers)
+	})
+	t.Run("Already dead", func(t *testing.T) {
+		err := target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit0"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already dead")
+		require.True(t, errors.Is(err, ErrDuplicate))
+	})
+	t.Run("Kill fails to persist", func(t *testing.T) {
+		victim, _ := pp.GetPlayer(myGameID, slack.SlackID("Uhit1"))
+		before := *victim
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		err := target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ReportKill failure: Mock error on update")
+		require.Equal(t, before, *victim, "Victim is rolled back when the kill can't be saved")
+		require.Equal(t, 4, game.RemainPlayers)
+		require.Equal(t, Playing, game.Status)
+	})
+	t.Run("Kill persisted", func(t *testing.T) {
+		mm.Updated = nil
+		mm.Written = nil
+		require.NoError(t, target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit1")))
+		require.Equal(t, []string{ GamesCollection + "/" + myGameID }, mm.Updated)
+		require.Equal(t, 3, game.RemainPlayers)
+		victim, _ := pp.GetPlayer(myGameID, slack.SlackID("Uhit1"))
+		assassin, _ := pp.GetPlayerByID(victim.KilledBy)
+		require.Equal(t, []string{ EventsCollection + "/" + victim.GetID() + "+killed", EventsCollection + "/" + assassin.GetID() + "+targets+" + assassin.Target }, mm.Written,
+			"The kill is recorded, followed by the assassin's new target")
+	})
+	t.Run("Final kill", func(t *testing.T) {
+		require.NoError(t, target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Uhit2")))
+		mm.Written = nil
+		for _, p := range []string{"Uhit3", "Uhit4"} {
+			if victim, _ := pp.GetPlayer(myGameID, slack.SlackID(p)); victim.IsAlive() {
+				require.NoError(t, target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, p)))
+				break
+			}
+		}
+		require.Equal(t, Finished, game.Status)
+		require.Len(t, mm.Written, 2, "The kill is recorded, followed by the game's completion")
+		require.Equal(t, EventsCollection + "/" + myGameID + "+completed", mm.Written[1])
+	})
+	t.Run("Missing game", func(t *testing.T) {
+		err := target.ReportKill(context.Background(), "Who, me?", events.NewKillReportedInline("Who, me?", "Uhit1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "GameID: Who, me? doesn't exist")
+	})
+	t.Run("PlayerPool issue", func(t *testing.T) {
+		mockPP := &MockPlayerPool{ GetPlayerError: "mock error: bad bad stuff happened" }
+		mockTarget, _ := getGamePoolWithMockMongo(t, mockPP)
+		gm := addGameToPool(t, mockTarget, "mocked", "Umock", "wordz", "MickJ", 5)
+		gm.Status = Playing
+		err := mockTarget.ReportKill(context.Background(), "mocked", events.NewKillReportedInline("mocked", "Uhit1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "PlayerPool: ", "Tell us where it broke")
+		require.Contains(t, err.Error(), mockPP.GetPlayerError, "Tell us what broke")
+	})
+}
+
+// TestGamePool_ConflictingKillReports has two pools share a store, as two servers would, and report kills along the
+// same chain. The pool that is behind must not write over the kill it hasn't seen
+func TestGamePool_ConflictingKillReports(t *testing.T) {
+	ctx := context.Background()
+	myGameID := "contested"
+	myCreator := slack.NewInline("UdaStarter")
+	store := persistence.NewMemorySession()
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	NewKillDictionary(ctx, store, "wordz", words...)
+	herePlayers := newPlayerPool(t, store)
+	here := newGamePool(t, store, herePlayers)
+	addGameToPool(t, here, myGameID, myCreator.ToString(), "wordz", "MickJ", 0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, here.AddPlayerToGame(ctx, myGameID, events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Uhit%d", i), "", "")))
+	}
+	require.NoError(t, here.StartGame(ctx, myGameID, startEvent(myGameID, myCreator)))
+	elsewherePlayers := newPlayerPool(t, store)
+	elsewhere := newGamePool(t, store, elsewherePlayers)
+
+	victim, _ := herePlayers.GetPlayer(myGameID, slack.SlackID("Uhit0"))
+	next, _ := herePlayers.GetPlayerByID(victim.Target)
+	nextTarget := next.Target
+	require.NoError(t, here.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, "Uhit0")))
+
+	// Elsewhere still has the first victim alive and hunting next
+	err := elsewhere.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, next.SlackID.ToString()))
+	require.True(t, errors.Is(err, ErrConflict), "Expected a conflict. Instead got %v", err)
+	reloaded, _ := elsewhere.GetPlayer(ctx, victim.GetID())
+	require.Equal(t, Dead, reloaded.Status, "The pool catches up with the store after a conflict")
+	game, _ := elsewhere.GetGame(myGameID)
+	require.Equal(t, 4, game.RemainPlayers)
+
+	require.NoError(t, elsewhere.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, next.SlackID.ToString())),
+		"Trying again goes through")
+	restarted := newGamePool(t, store, newPlayerPool(t, store))
+	game, _ = restarted.GetGame(myGameID)
+	require.Equal(t, 3, game.RemainPlayers, "Neither kill is lost")
+	killed, _ := restarted.GetPlayer(ctx, next.GetID())
+	require.Equal(t, Dead, killed.Status)
+	require.Equal(t, victim.KilledBy, killed.KilledBy, "The first assassin inherited next, and so made the second kill")
+	assassin, _ := restarted.GetPlayer(ctx, victim.KilledBy)
+	require.Equal(t, nextTarget, assassin.Target)
+	require.Equal(t, 2, assassin.Kills)
+}
+
+func TestGamePool_RestartKeepsState(t *testing.T) {
+	myGameID := "survivor"
+	myCreator := slack.NewInline("UdaStarter")
+	target, mm := getGamePoolWithMockMongo(t, nil)
+	mm.CollectionResults = map[string][]persistence.Persistable{ CollectionName: mockKillWords(t, "wordz", 20) }
+	game := addGameToPool(t, target, myGameID, myCreator.ToString(), "wordz", "MickJ", 0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, target.AddPlayerToGame(context.Background(), myGameID, events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Usurv%d", i), "", "")))
+	}
+	require.NoError(t, target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator)))
+	require.NoError(t, target.ReportKill(context.Background(), myGameID, events.NewKillReportedInline(myGameID, "Usurv0")))
+
+	restarted, _ := getGamePoolWithMockMongo(t, nil, game)
+	actual, exists := restarted.GetGame(myGameID)
+	require.True(t, exists)
+	require.Equal(t, game.Status, actual.Status)
+	require.Equal(t, game.StartPlayers, actual.StartPlayers)
+	require.Equal(t, game.RemainPlayers, actual.RemainPlayers)
+	require.Equal(t, game.StartTime.Unix(), actual.StartTime.Unix())
+	require.Equal(t, game.GetStatusReport(), actual.GetStatusReport())
+}
+
+// TestGamePool_RestartKeepsSpentWords restores a game from its snapshots, and checks that a word dealt to a player who
+// has since died isn't dealt again
+func TestGamePool_RestartKeepsSpentWords(t *testing.T) {
+	ctx := context.Background()
+	myGameID := "thrifty"
+	myCreator := slack.NewInline("UdaStarter")
+	store := persistence.NewMemorySession()
+	words := make([]string, RequiredWords(5))
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	NewKillDictionary(ctx, store, "wordz", words...)
+	players := newPlayerPool(t, store)
+	pool := newGamePool(t, store, players)
+	addGameToPool(t, pool, myGameID, myCreator.ToString(), "wordz", "MickJ", 0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, pool.AddPlayerToGame(ctx, myGameID, events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Uthrift%d", i), "", "")))
+	}
+	require.NoError(t, pool.StartGame(ctx, myGameID, startEvent(myGameID, myCreator)))
+	victim, _ := players.GetPlayer(myGameID, slack.SlackID("Uthrift0"))
+	spent := victim.KillWord
+	require.NoError(t, pool.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, "Uthrift0")))
+
+	restartedPlayers := newPlayerPool(t, store)
+	restarted := newGamePool(t, store, restartedPlayers)
+	dead, _ := restarted.GetPlayer(ctx, victim.GetID())
+	require.Equal(t, spent, dead.KillWord, "The dead keep their last word")
+	next, _ := restartedPlayers.GetPlayerByID(dead.KilledBy)
+	nextVictim, _ := restartedPlayers.GetPlayerByID(next.Target)
+	require.NoError(t, restarted.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, nextVictim.SlackID.ToString())))
+	game, _ := restarted.GetGame(myGameID)
+	require.True(t, game.usedWords[spent], "The dead player's word stays spent after a restart")
+	assassin, _ := restarted.GetPlayer(ctx, next.GetID())
+	require.NotEqual(t, spent, assassin.KillWord)
+}
+
+// TestGamePool_FailedKillKeepsWords fails a kill on the store, and then plays the game out with a dictionary that has
+// no words to spare. Rolling back the failed kill has to put back the word it drew
+func TestGamePool_FailedKillKeepsWords(t *testing.T) {
+	ctx := context.Background()
+	myGameID := "frugal"
+	myCreator := slack.NewInline("UdaStarter")
+	target, mm := getGamePoolWithMockMongo(t, nil)
+	mm.CollectionResults = map[string][]persistence.Persistable{ CollectionName: mockKillWords(t, "wordz", RequiredWords(5)) }
+	addGameToPool(t, target, myGameID, myCreator.ToString(), "wordz", "MickJ", 0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, target.AddPlayerToGame(ctx, myGameID, events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Ufrugal%d", i), "", "")))
+	}
+	require.NoError(t, target.StartGame(ctx, myGameID, startEvent(myGameID, myCreator)))
+	hunter, err := target.players.GetPlayerByID(myGameID + "+Ufrugal0")
+	require.NoError(t, err)
+
+	victim, _ := target.players.GetPlayerByID(hunter.Target)
+	mm.ConnectMode = "no connect"
+	err = target.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, victim.SlackID.ToString()))
+	require.True(t, errors.Is(err, ErrStoreUnavailable), "Expected the store to be unavailable. Instead got %v", err)
+	mm.ConnectMode = "positive"
+
+	for i := 0; i < 4; i++ {
+		victim, _ = target.players.GetPlayerByID(hunter.Target)
+		require.NoError(t, target.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, victim.SlackID.ToString())),
+			"Kill %d", i+1)
+	}
+	game, _ := target.GetGame(myGameID)
+	require.Equal(t, Finished, game.Status)
+	require.Equal(t, hunter.GetID(), game.Winner)
+}
+
 func TestStartGame(t *testing.T) {
 	// Setup: create a game, some players, a playerpool (mock) and finally the gamepool
 	myGameID := "add1"
@@ -206,49 +605,92 @@ func TestStartGame(t *testing.T) {
 	}
 	players := makePlayerList(t, myGameID, 6)
 	mockPP := &MockPlayerPool{ playersToReturn: players }
-	target, _ := getGamePoolWithMockMongo(t, mockPP, myGame)
+	target, mm := getGamePoolWithMockMongo(t, mockPP, myGame)
+	mm.CollectionResults = map[string][]persistence.Persistable{ CollectionName: mockKillWords(t, "wordz", 20) }
 
 	t.Run("Positive", func(t *testing.T) {
 		// Need to grab the reconsituted instance after restore from mock mongo
-		targetGame, ok := target.GetGame(myGameID)
+		targetGame, ok := target.games[myGameID]
 		require.True(t, ok, "Couldn't find reconstituted game instance for ID: %s", myGameID)
-		err := target.StartGame(myGameID, myCreator)
+		err := target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator))
 		require.NoError(t, err)
 		require.Equal(t, Playing, targetGame.Status, "Once started, the game should have the correct status")
+		for _, p := range players {
+			require.Contains(t, p.KillWord, "word", "Kill words should come from the game's dictionary")
+		}
 		// return status to reuse
 		targetGame.Status = Starting
 	})
+	t.Run("Missing dictionary", func(t *testing.T) {
+		noDictGame := addGameToPool(t, target, "noDict", "UdaStarter", "who needs words", "MickJ", 6)
+		mockPP.playersToReturn = makePlayerList(t, noDictGame.ID, 6)
+		err := target.StartGame(context.Background(), noDictGame.ID, startEvent(noDictGame.ID, myCreator))
+		require.Error(t, err, "Should get an error when the dictionary can't be loaded")
+		require.Contains(t, err.Error(), "KillDictionary who needs words not found", "Tell us why it broke")
+		require.Equal(t, Starting, noDictGame.Status, "Failed start leaves the game as it was")
+		mockPP.playersToReturn = players
+	})
+	t.Run("Targets recorded", func(t *testing.T) {
+		targetGame := target.games[myGameID]
+		mm.Written = nil
+		require.NoError(t, target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator)))
+		require.Len(t, mm.Written, 7, "The start is recorded, then every player's first target")
+		require.Equal(t, EventsCollection + "/" + myGameID + "+started", mm.Written[0])
+		for _, p := range players {
+			require.Contains(t, mm.Written, EventsCollection + "/" + p.GetID() + "+targets+" + p.Target)
+		}
+		targetGame.Status = Starting
+	})
+	t.Run("Game fails to persist", func(t *testing.T) {
+		targetGame := target.games[myGameID]
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		err := target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Start failure: Mock error on update")
+		require.Equal(t, Starting, targetGame.Status, "Failed save leaves the game as it was")
+	})
+	t.Run("Players fail to persist", func(t *testing.T) {
+		failPP := &MockPlayerPool{ playersToReturn: players, UpdatePlayersError: "mock error: players stuck" }
+		failTarget, failMM := getGamePoolWithMockMongo(t, failPP, myGame)
+		failMM.CollectionResults = mm.CollectionResults
+		err := failTarget.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator))
+		require.Error(t, err, "Should get an error when the assignments can't be saved")
+		require.Contains(t, err.Error(), "Start failure: PlayerPool: mock error: players stuck")
+	})
 	t.Run("Blank slackid", func(t *testing.T) {
-		err := target.StartGame("", myCreator)
+		err := target.StartGame(context.Background(), "", startEvent("", myCreator))
 		require.Error(t, err, "Should get an error on a blank slack id")
 		require.Contains(t, err.Error(), "requires a non-empty game ID and creator ID", "Tell us why it broke")
 	})
 	t.Run("Blank creator", func(t *testing.T) {
-		err := target.StartGame("whatev", "")
+		err := target.StartGame(context.Background(), "whatev", startEvent("whatev", ""))
 		require.Error(t, err, "Should get an error on a blank creator")
 		require.Contains(t, err.Error(), "requires a non-empty game ID and creator ID", "Tell us why it broke")
 	})
 	t.Run("Missing game", func(t *testing.T) {
-		err := target.StartGame("Who, me?", myCreator)
+		err := target.StartGame(context.Background(), "Who, me?", startEvent("Who, me?", myCreator))
 		require.Error(t, err, "Should get an error on the game id check failure")
 		require.Contains(t, err.Error(), "GameID: Who, me? doesn't exist", "Tell us why it broke")
+		require.True(t, errors.Is(err, ErrNotFound))
 	})
 	t.Run("Wrong game state", func(t *testing.T) {
 		myStartedGame := addGameToPool(t, target, "startedGame", "UGAMEBREAKER", "wordz", "MickJ", 6)
 		myStartedGame.Status = Playing
-		err := target.StartGame(myStartedGame.ID, myStartedGame.GameCreator)
+		err := target.StartGame(context.Background(), myStartedGame.ID, startEvent(myStartedGame.ID, myStartedGame.GameCreator))
 		require.Error(t, err, "Should get an error on starting a game not in the Starting state")
 		require.Contains(t, err.Error(), "GameID: startedGame is not accepting players", "Tell us why it broke")
 	})
 	t.Run("Wrong creator", func(t *testing.T) {
 		someoneElsesGame := addGameToPool(t, target, "lockDown", "UGAMEBREAKER", "wordz", "MickJ", 6)
-		err := target.StartGame(someoneElsesGame.ID, slack.NewInline("UNOTME"))
+		err := target.StartGame(context.Background(), someoneElsesGame.ID, startEvent(someoneElsesGame.ID, slack.NewInline("UNOTME")))
 		require.Error(t, err, "Should get an error on starting a game with the wrong creator ID")
 		require.Contains(t, err.Error(), "GameID: lockDown cannot be started by non-creator", "Tell us why it broke")
+		require.True(t, errors.Is(err, ErrNotAuthorized))
 	})
 	t.Run("PlayerPool issue", func(t *testing.T){
 		mockPP.GetPlayerError = "mock error: bad bad stuff happened"
-		err := target.StartGame(myGameID, myCreator)
+		err := target.StartGame(context.Background(), myGameID, startEvent(myGameID, myCreator))
 		require.Error(t, err, "Should get an error when PlayerPool gets the player list")
 		require.Contains(t, err.Error(), "PlayerPool: ", "Tell us where it broke")
 		require.Contains(t, err.Error(), mockPP.GetPlayerError, "Tell us what broke")
@@ -258,14 +700,222 @@ func TestStartGame(t *testing.T) {
 
 }
 
+func TestGamePool_Concurrent(t *testing.T) {
+	myCreator := slack.NewInline("UdaStarter")
+	target, mm := getGamePoolWithMockMongo(t, nil)
+	mm.CollectionResults = map[string][]persistence.Persistable{ CollectionName: mockKillWords(t, "wordz", 50) }
+	games := []string{"busy0", "busy1", "busy2"}
+	for _, id := range games {
+		addGameToPool(t, target, id, myCreator.ToString(), "wordz", "MickJ", 0)
+	}
+
+	// Join, start and kill in every game at once, with a reader looking on throughout
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				for _, g := range target.GetGamesList() {
+					target.GetPlayer(context.Background(), g.GetID() + "+Uplayer0")
+				}
+			}
+		}
+	}()
+	for _, id := range games {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			var joins sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				joins.Add(1)
+				go func(i int) {
+					defer joins.Done()
+					target.AddPlayerToGame(context.Background(), id, events.NewPlayerAddedInline(id, fmt.Sprintf("Uplayer%d", i), "", ""))
+				}(i)
+			}
+			joins.Wait()
+			if err := target.StartGame(context.Background(), id, startEvent(id, myCreator)); err != nil {
+				t.Errorf("StartGame %s: %v", id, err)
+				return
+			}
+			var kills sync.WaitGroup
+			for i := 0; i < 9; i++ {
+				kills.Add(1)
+				go func(i int) {
+					defer kills.Done()
+					target.ReportKill(context.Background(), id, events.NewKillReportedInline(id, fmt.Sprintf("Uplayer%d", i)))
+				}(i)
+			}
+			kills.Wait()
+		}(id)
+	}
+	wg.Wait()
+	close(done)
+
+	for _, id := range games {
+		game, exists := target.GetGame(id)
+		require.True(t, exists)
+		require.Equal(t, 10, game.StartPlayers, "No joins lost in %s", id)
+		require.Equal(t, Finished, game.Status)
+		require.Equal(t, id + "+Uplayer9", game.Winner)
+	}
+}
+
+func TestGamePool_Drain(t *testing.T) {
+	target, _ := getGamePoolWithMockMongo(t, nil)
+	addGameToPool(t, target, "draining", "UdaStarter", "wordz", "MickJ", 0)
+	require.Equal(t, 0, target.QueueDepth("draining"))
+	require.Equal(t, 0, target.QueueDepth("Who, me?"))
+
+	// Hold the game's actor up, so a join is still waiting when the drain starts
+	release := make(chan struct{})
+	held, err := target.actors["draining"].send(context.Background(), func(*Game) error { <-release; return nil })
+	require.NoError(t, err)
+	joined := make(chan error)
+	go func() {
+		joined <- target.AddPlayerToGame(context.Background(), "draining", events.NewPlayerAddedInline("draining", "ULate", "", ""))
+	}()
+	for target.QueueDepth("draining") < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	drained := make(chan struct{})
+	go func() {
+		target.Drain()
+		close(drained)
+	}()
+	close(release)
+	<-held
+	<-drained
+	require.NoError(t, <-joined, "Commands queued before the drain still run")
+	require.Equal(t, 1, target.games["draining"].StartPlayers)
+
+	err = target.AddPlayerToGame(context.Background(), "draining", events.NewPlayerAddedInline("draining", "UTooLate", "", ""))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is shutting down")
+	err = target.AddGame(context.Background(), &Game{ID: "afterhours"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GamePool is shutting down")
+	require.True(t, errors.Is(err, ErrStoreUnavailable))
+}
+
+func TestGamePool_GetPlayers(t *testing.T) {
+	target, _ := getGamePoolWithMockMongo(t, nil)
+	addGameToPool(t, target, "roster", "UdaStarter", "wordz", "MickJ", 0)
+	for _, id := range []string{"Uzed", "Uabe", "Umid"} {
+		require.NoError(t, target.AddPlayerToGame(context.Background(), "roster", events.NewPlayerAddedInline("roster", id, "", "")))
+	}
+
+	players, err := target.GetPlayers(context.Background(), "roster")
+	require.NoError(t, err)
+	require.Len(t, players, 3)
+	require.Equal(t, "roster+Uabe", players[0].GetID(), "Players come sorted by ID")
+	require.Equal(t, "roster+Uzed", players[2].GetID())
+	players[0].Name = "changed"
+	again, _ := target.GetPlayers(context.Background(), "roster")
+	require.Empty(t, again[0].Name, "Changes to the copies don't affect the pool")
+
+	_, err = target.GetPlayers(context.Background(), "Who, me?")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGamePool_News(t *testing.T) {
+	ctx := context.Background()
+	store := persistence.NewMemorySession()
+	for _, kw := range mockKillWords(t, "wordz", 20) {
+		require.NoError(t, store.WriteCollection(ctx, CollectionName, kw))
+	}
+	target := newGamePool(t, store, newPlayerPool(t, store))
+	var news []GameNews
+	var joined bool
+	target.Subscribe(func(n GameNews) {
+		if _, ok := n.Event.(*events.GameCreatedEvent); ok {
+			// Listeners can't call back into the pool, so look in its map
+			target.mu.RLock()
+			_, joined = target.games[n.Game.ID]
+			target.mu.RUnlock()
+		}
+		news = append(news, n)
+	})
+
+	game := NewGameFromEvent(events.NewGameCreatedInline("newsy", "UdaStarter", "wordz", "MickJ"))
+	game.Channel = "Cnewsroom"
+	require.NoError(t, target.AddGame(ctx, &game))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, target.AddPlayerToGame(ctx, "newsy", events.NewPlayerAddedInline("newsy", fmt.Sprintf("Uhit%d", i), "", "")))
+	}
+	require.Error(t, target.AddPlayerToGame(ctx, "newsy", events.NewPlayerAddedInline("newsy", "Uhit0", "", "")))
+	require.NoError(t, target.StartGame(ctx, "newsy", startEvent("newsy", slack.SlackID("UdaStarter"))))
+	require.NoError(t, target.ReportKill(ctx, "newsy", events.NewKillReportedInline("newsy", "Uhit0")))
+
+	require.Len(t, news, 8, "Created, 5 joined, started and a kill. The failed join made no news")
+	require.IsType(t, &events.GameCreatedEvent{}, news[0].Event)
+	require.Equal(t, "Cnewsroom", news[0].Game.Channel)
+	require.True(t, joined, "A game is only news once it has joined the pool")
+	require.Equal(t, 3, news[3].Game.StartPlayers, "Each join comes with the count as it was then")
+	require.Equal(t, "newsy+Uhit2", news[3].Players[0].GetID())
+	require.IsType(t, &events.GameStartedEvent{}, news[6].Event)
+	require.Len(t, news[6].Players, 5)
+
+	kill := news[7]
+	require.Equal(t, 4, kill.Game.RemainPlayers)
+	victim, found := kill.Player("newsy+Uhit0")
+	require.True(t, found, "The victim is in the news")
+	require.Equal(t, Dead, victim.Status)
+	assassin, found := kill.Player(victim.KilledBy)
+	require.True(t, found, "So is their assassin")
+	require.Equal(t, victim.KilledWith, news[6].Players[indexOf(news[6].Players, assassin.GetID())].KillWord)
+	require.Len(t, kill.Players, 2)
+}
+
+func TestGamePool_EventsForChanges(t *testing.T) {
+	game := NewGameFromEvent(events.NewGameCreatedInline("recorded", "UdaStarter", "wordz", "MickJ"))
+	started := []events.GameEvent{&events.GameStartedEvent{}}
+	players := makePlayerList(t, game.ID, 3)
+	players[0].SetTarget(players[1].GetID(), "word000")
+	players[1].SetTarget(players[0].GetID(), "word001")
+
+	evs, err := targetEvents(started, &game, players...)
+	require.NoError(t, err)
+	require.Len(t, evs, 3, "The events so far, then a target for each player that has one")
+	evs, err = completedEvents(evs, &game)
+	require.NoError(t, err)
+	require.Len(t, evs, 3, "Nothing to record until the game finishes")
+
+	players[2].SetTarget(players[0].GetID(), "")
+	_, err = targetEvents(started, &game, players...)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing KillWord field")
+
+	game.Status = Finished
+	_, err = completedEvents(started, &game)
+	require.Error(t, err, "A finished game with no winner can't be recorded")
+	game.Winner = players[0].GetID()
+	evs, err = completedEvents(started, &game)
+	require.NoError(t, err)
+	require.IsType(t, &events.GameCompletedEvent{}, evs[1])
+}
+
 //** Helper functions **//
 
+// indexOf finds a player in a list
+func indexOf(players []Player, playerid string) int {
+	for i := range players {
+		if players[i].GetID() == playerid {
+			return i
+		}
+	}
+	return -1
+}
+
 // addGameToPool creates and adds a game to the GamePool. If an error is expected, it validates that it contains
 // the optional passed in string. Otherwise, validates no error
 func addGameToPool(t *testing.T, pool *GamePool, id, creator, dict, pass string, numPlayers int, expectError ...string) *Game {
 	g1 := NewGameFromEvent(events.NewGameCreatedInline(id, creator, dict, pass))
 	g1.StartPlayers = numPlayers
-	err := pool.AddGame(&g1)
+	err := pool.AddGame(context.Background(), &g1)
 	if len(expectError) > 0 {
 		require.Error(t, err, "Wanted to see error adding to the test pool")
 		require.Contains(t, err.Error(), expectError[0], "Wanted to see error adding to the test pool")
@@ -275,6 +925,11 @@ func addGameToPool(t *testing.T, pool *GamePool, id, creator, dict, pass string,
 	return &g1
 }
 
+// startEvent builds a GameStartedEvent without validation, so blank values can be tested at the pool
+func startEvent(gameid string, creator slack.SlackID) events.GameStartedEvent {
+	return events.GameStartedEvent{ ID: gameid + "+started", GameID: gameid, StartedBy: creator, Seed: 1 }
+}
+
 // getGamePoolWithMockMongo creates a GamePool with a preset mock mongo set for all positive mock behaviors
 // uses either the passed in PlayerPoolAbstraction, or if nil, creates a default instance of PlayerPool
 func getGamePoolWithMockMongo(t *testing.T, pp PlayerPoolAbstraction, existingGames... persistence.Persistable) (target *GamePool, mm *persistence.MockMongoSession) {
@@ -289,8 +944,26 @@ func getGamePoolWithMockMongo(t *testing.T, pp PlayerPoolAbstraction, existingGa
 	// on reconstitution from the mongo layer for existing games
 	// ** warning: games will be new instances
 	mm.FetchResults = existingGames
-	target = NewGamePool(mm, pp)
-	return target, nil
+	target = newGamePool(t, mm, pp)
+	return target, mm
+}
+
+// newGamePool is NewGamePool for a store the test expects to restore from cleanly
+func newGamePool(t *testing.T, m persistence.MongoAbstraction, pp PlayerPoolAbstraction) *GamePool {
+	pool, err := NewGamePool(context.Background(), m, pp)
+	require.NoError(t, err)
+	return pool
+}
+
+// mockKillWords generates a set of persistable words for a dictionary, suitable for loading into the mock mongo
+func mockKillWords(t *testing.T, dictID string, numWords int) []persistence.Persistable {
+	words := make([]persistence.Persistable, numWords)
+	for i := 0; i < numWords; i++ {
+		kw, err := NewKillWord(dictID, fmt.Sprintf("word%03d", i))
+		require.NoError(t, err, "Issue creating KillWord for mock dictionary")
+		words[i] = &kw
+	}
+	return words
 }
 
 func makePlayerList(t * testing.T, gameid string, numPlayers int) []*Player {
@@ -1,14 +1,22 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 
 	mongo "wordassassin/persistence"
 )
 
+// DictionarySummary describes a persisted dictionary without the words
+type DictionarySummary struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
 // KillDictionary represents a collection of valid words to use within a game of wordassassin
 type KillDictionary struct {
-	mongo mongo.MongoAbstraction
+	repo  *DictionaryRepository
 	ID    string
 	words []string
 }
@@ -21,12 +29,12 @@ const (
 // NewKillDictionary creates an unique instance
 // Unique ID enforced by persisted
 // Input list is scrubbed to allow valid values only (single word, more than 4 letters)
-func NewKillDictionary(m mongo.MongoAbstraction, id string, words ...string) KillDictionary {
-	dict := KillDictionary{m, id, make([]string, 0)}
+func NewKillDictionary(ctx context.Context, m mongo.MongoAbstraction, id string, words ...string) KillDictionary {
+	dict := KillDictionary{NewDictionaryRepository(m), id, make([]string, 0)}
 	// TODO: validate each word and remove the bad ones
 	// TODO: write each word throough the AddWord method, to leverage scrubbing rules
 	for _, word := range words {
-		if err := dict.AddWord(word); err != nil {
+		if err := dict.AddWord(ctx, word); err != nil {
 			// eat errors for now, since it only indicates an illegal word
 		}
 	}
@@ -37,15 +45,15 @@ func NewKillDictionary(m mongo.MongoAbstraction, id string, words ...string) Kil
 // Filters out words that do not meet the acceptable criteria:
 // - Word must be 4 or more characters
 // Returns an error on unsuccessful addition
-func (kd *KillDictionary) AddWord(word string) error {
+func (kd *KillDictionary) AddWord(ctx context.Context, word string) error {
 	// create a mongo friendly object to persist. Validate kw format as a side effect.
 	kw, err := NewKillWord(kd.ID, word)
 	if err != nil {
-		return fmt.Errorf("AddWord: %v", err)
+		return fmt.Errorf("AddWord: %w", err)
 	}
 
 	// attempt mongo write
-	if err = kd.mongo.WriteCollection(CollectionName, &kw); err != nil {
+	if err = kd.repo.Save(ctx, &kw); err != nil {
 		return err
 	}
 	// add to kd array
@@ -54,17 +62,119 @@ func (kd *KillDictionary) AddWord(word string) error {
 	return nil
 }
 
+// AddWords adds each of the words to the dictionary, carrying on past any that can't be added. It stops once ctx is
+// done, leaving the rest unadded and unrejected; callers check ctx.Err() to tell
+// Returns the number of words added, along with the reason for each word that was rejected
+func (kd *KillDictionary) AddWords(ctx context.Context, words ...string) (added int, rejected []string) {
+	for _, word := range words {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := kd.AddWord(ctx, word); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			rejected = append(rejected, fmt.Sprintf("%s: %v", word, err))
+			continue
+		}
+		added++
+	}
+	return
+}
+
+// RemoveWord takes a word out of the dictionary and its persisted form
+// Errors:
+//   word is not in the dictionary
+//   mongo issue
+func (kd *KillDictionary) RemoveWord(ctx context.Context, word string) error {
+	index := -1
+	for i, w := range kd.words {
+		if w == word {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errorf(ErrNotFound, "RemoveWord: %s is not in KillDictionary %s", word, kd.ID)
+	}
+	kw := KillWord{ID: fmt.Sprintf("%s+%s", kd.ID, word), DictID: kd.ID, Word: word}
+	if err := kd.repo.Delete(ctx, &kw); err != nil {
+		return fmt.Errorf("RemoveWord: %w", err)
+	}
+	kd.words = append(kd.words[:index], kd.words[index+1:]...)
+	return nil
+}
+
+// Delete removes every word in the dictionary from mongo, which removes the dictionary itself
+func (kd *KillDictionary) Delete(ctx context.Context) error {
+	for len(kd.words) > 0 {
+		if err := kd.RemoveWord(ctx, kd.words[0]); err != nil {
+			return fmt.Errorf("Delete: %w", err)
+		}
+	}
+	return nil
+}
+
 // Count returns the number of available words in the dictionary
 func (kd *KillDictionary) Count() int {
 	return len(kd.words)
 }
 
-// GetKillWord selects a word at random from the dictionary
-func (kd *KillDictionary) GetKillWord() string {
-	panic("GetKillWord - Not implemented")
+// GetKillWord selects a word at random from the dictionary, skipping any word already marked as used. The random
+// source is supplied by the caller so that draws can be made deterministic. A nil source uses the global one.
+// Errors:
+//   every word in the dictionary is already used
+func (kd *KillDictionary) GetKillWord(rnd *rand.Rand, used map[string]bool) (string, error) {
+	available := make([]string, 0, len(kd.words))
+	for _, word := range kd.words {
+		if !used[word] {
+			available = append(available, word)
+		}
+	}
+	if len(available) == 0 {
+		return "", errorf(ErrInvalidState, "KillDictionary %s has no unused words left (%d words total)", kd.ID, len(kd.words))
+	}
+	if rnd == nil {
+		return available[rand.Intn(len(available))], nil
+	}
+	return available[rnd.Intn(len(available))], nil
+}
+
+// LoadKillDictionary creates an instance populated with the words persisted for the given ID
+// Errors:
+//   mongo issue
+//   no words found for the ID
+func LoadKillDictionary(ctx context.Context, m mongo.MongoAbstraction, id string) (*KillDictionary, error) {
+	kd := &KillDictionary{repo: NewDictionaryRepository(m), ID: id}
+	if err := kd.RestoreFromMongo(ctx); err != nil {
+		return nil, err
+	}
+	if kd.Count() == 0 {
+		return nil, errorf(ErrNotFound, "KillDictionary %s not found", id)
+	}
+	return kd, nil
+}
+
+// RestoreFromMongo replaces the in memory word list with the words persisted for this dictionary's ID. Words are
+// kept in sorted order so that seeded draws don't depend on the order mongo hands them back.
+func (kd *KillDictionary) RestoreFromMongo(ctx context.Context) error {
+	kws, err := kd.repo.Find(ctx, kd.ID)
+	if err != nil {
+		return fmt.Errorf("RestoreFromMongo: %w", err)
+	}
+	words := make([]string, len(kws))
+	for i, kw := range kws {
+		words[i] = kw.Word
+	}
+	kd.words = words
+	return nil
 }
 
-// RestoreFromMongo finds the dictionary
-// func (kd *KillDictionary) RestoreFromMongo() string {
-// 	panic("Not implemented")
-// }
+// ListKillDictionaries summarizes all of the dictionaries persisted in mongo, sorted by ID
+func ListKillDictionaries(ctx context.Context, m mongo.MongoAbstraction) ([]DictionarySummary, error) {
+	result, err := NewDictionaryRepository(m).Summaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListKillDictionaries: %w", err)
+	}
+	return result, nil
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"math/rand"
 	"reflect"
 	"testing"
 
@@ -30,20 +32,20 @@ func TestKillDictionary_NewKillDictionary(t *testing.T) {
 				id:   "kd1",
 				word: wordList,
 			},
-			want: KillDictionary{mockMongo, "kd1", wordList},
+			want: KillDictionary{NewDictionaryRepository(mockMongo), "kd1", wordList},
 		},
 		{name: "Filter short words",
 			args: args{
 				id:   "filterme",
 				word: []string{"valid1", "valid2", "no", "valid3"},
 			},
-			want: KillDictionary{mockMongo, "filterme", []string{"valid1", "valid2", "valid3"}},
+			want: KillDictionary{NewDictionaryRepository(mockMongo), "filterme", []string{"valid1", "valid2", "valid3"}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewKillDictionary(mockMongo, tt.args.id, tt.args.word...); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("KillDictionary.NewKillDictionary() = %v, want %v", got, tt.want)
+			if got := NewKillDictionary(context.Background(), mockMongo, tt.args.id, tt.args.word...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KillDictionary.NewKillDictionary(context.Background()) = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -51,7 +53,7 @@ func TestKillDictionary_NewKillDictionary(t *testing.T) {
 
 func TestKillDictionary_AddWord(t *testing.T) {
 	mockMongo := dao.NewMockMongoSession()
-	target := NewKillDictionary(mockMongo, "target1", "pre-existing")
+	target := NewKillDictionary(context.Background(), mockMongo, "target1", "pre-existing")
 	type args struct {
 		word string
 	}
@@ -85,7 +87,7 @@ func TestKillDictionary_AddWord(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockMongo.SetMongoControlsFromArgs(tt.mongoCtrl)
-			got := target.AddWord(tt.args.word)
+			got := target.AddWord(context.Background(), tt.args.word)
 			if tt.err {
 				require.NotNil(t, got, "Expected error, didn't get one")
 				require.Contains(t, got.Error(), tt.msg, "Err msg did not contain required keyword")
@@ -95,3 +97,121 @@ func TestKillDictionary_AddWord(t *testing.T) {
 		})
 	}
 }
+
+func TestKillDictionary_GetKillWord(t *testing.T) {
+	mockMongo := dao.NewMockMongoSession()
+	target := NewKillDictionary(context.Background(), mockMongo, "drawme", "alpha", "bravo", "charlie")
+	t.Run("Skips used words", func(t *testing.T) {
+		used := map[string]bool{"alpha": true, "charlie": true}
+		for i := 0; i < 10; i++ {
+			got, err := target.GetKillWord(rand.New(rand.NewSource(int64(i))), used)
+			require.NoError(t, err)
+			require.Equal(t, "bravo", got, "Only one word left to give")
+		}
+	})
+	t.Run("Seeded draws repeat", func(t *testing.T) {
+		first, _ := target.GetKillWord(rand.New(rand.NewSource(13)), nil)
+		second, _ := target.GetKillWord(rand.New(rand.NewSource(13)), nil)
+		require.Equal(t, first, second)
+	})
+	t.Run("Global source", func(t *testing.T) {
+		got, err := target.GetKillWord(nil, nil)
+		require.NoError(t, err)
+		require.Contains(t, []string{"alpha", "bravo", "charlie"}, got)
+	})
+	t.Run("Exhausted", func(t *testing.T) {
+		used := map[string]bool{"alpha": true, "bravo": true, "charlie": true}
+		_, err := target.GetKillWord(nil, used)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "KillDictionary drawme has no unused words left")
+	})
+}
+
+func TestKillDictionary_LoadKillDictionary(t *testing.T) {
+	mockMongo := dao.NewMockMongoSession()
+	stored := []dao.Persistable{}
+	for _, w := range []string{"zebra", "aardvark", "mongoose"} {
+		kw, _ := NewKillWord("zoo", w)
+		stored = append(stored, &kw)
+	}
+	other, _ := NewKillWord("farm", "chicken")
+	stored = append(stored, &other)
+	mockMongo.CollectionResults = map[string][]dao.Persistable{CollectionName: stored}
+
+	t.Run("Positive", func(t *testing.T) {
+		got, err := LoadKillDictionary(context.Background(), mockMongo, "zoo")
+		require.NoError(t, err)
+		require.Equal(t, 3, got.Count(), "Only words for the requested dictionary are loaded")
+		require.Equal(t, []string{"aardvark", "mongoose", "zebra"}, got.words, "Words are kept sorted")
+	})
+	t.Run("Not found", func(t *testing.T) {
+		_, err := LoadKillDictionary(context.Background(), mockMongo, "aquarium")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "KillDictionary aquarium not found")
+	})
+	t.Run("Mongo issue", func(t *testing.T) {
+		mockMongo.QueryMode = "fail"
+		defer func() { mockMongo.QueryMode = "positive" }()
+		_, err := LoadKillDictionary(context.Background(), mockMongo, "zoo")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "RestoreFromMongo: Mock error on get")
+	})
+}
+
+func TestKillDictionary_AddWords(t *testing.T) {
+	mockMongo := dao.NewMockMongoSession()
+	target := NewKillDictionary(context.Background(), mockMongo, "bulk")
+	added, rejected := target.AddWords(context.Background(), "plenty", "no", "enough", "xx")
+	require.Equal(t, 2, added)
+	require.Equal(t, 2, target.Count())
+	require.Len(t, rejected, 2, "Each bad word is reported")
+	require.Contains(t, rejected[0], "no: ")
+	require.Contains(t, rejected[0], "minimum")
+}
+
+func TestKillDictionary_RemoveWord(t *testing.T) {
+	mockMongo := dao.NewMockMongoSession()
+	target := NewKillDictionary(context.Background(), mockMongo, "shrinking", "alpha", "bravo", "charlie")
+	t.Run("Positive", func(t *testing.T) {
+		require.NoError(t, target.RemoveWord(context.Background(), "bravo"))
+		require.Equal(t, []string{"alpha", "charlie"}, target.words)
+	})
+	t.Run("Not in dictionary", func(t *testing.T) {
+		err := target.RemoveWord(context.Background(), "bravo")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bravo is not in KillDictionary shrinking")
+	})
+	t.Run("Mongo issue", func(t *testing.T) {
+		mockMongo.WriteMode = "fail"
+		defer func() { mockMongo.WriteMode = "positive" }()
+		err := target.RemoveWord(context.Background(), "alpha")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Mock error on delete")
+		require.Equal(t, 2, target.Count(), "A failed delete leaves the word in place")
+	})
+	t.Run("Delete all", func(t *testing.T) {
+		require.NoError(t, target.Delete(context.Background()))
+		require.Equal(t, 0, target.Count())
+	})
+}
+
+func TestKillDictionary_ListKillDictionaries(t *testing.T) {
+	mockMongo := dao.NewMockMongoSession()
+	stored := []dao.Persistable{}
+	for _, id := range []string{"zoo", "farm", "zoo", "zoo"} {
+		kw, _ := NewKillWord(id, "critter")
+		stored = append(stored, &kw)
+	}
+	mockMongo.CollectionResults = map[string][]dao.Persistable{CollectionName: stored}
+	t.Run("Positive", func(t *testing.T) {
+		got, err := ListKillDictionaries(context.Background(), mockMongo)
+		require.NoError(t, err)
+		require.Equal(t, []DictionarySummary{{ID: "farm", Count: 1}, {ID: "zoo", Count: 3}}, got)
+	})
+	t.Run("Mongo issue", func(t *testing.T) {
+		mockMongo.QueryMode = "fail"
+		_, err := ListKillDictionaries(context.Background(), mockMongo)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ListKillDictionaries: Mock error on get")
+	})
+}
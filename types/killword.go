@@ -8,9 +8,11 @@ import (
 
 // KillWord is a data structure used to persist dictionary words
 type KillWord struct {
-	ID     string `json:"id" bson:"_id"`
-	DictID string
-	Word   string
+	ID         string `json:"id" bson:"_id"`
+	DictID     string
+	Word       string
+	Category   string `json:"category,omitempty" bson:",omitempty"`
+	Difficulty int    `json:"difficulty,omitempty" bson:",omitempty"`
 }
 
 const (
@@ -22,16 +24,16 @@ const (
 func NewKillWord(dictID, word string) (response KillWord, err error) {
 	// validate word (length only so far)
 	if len(word) < KillWordMinCharLength {
-		err = fmt.Errorf("%s does not meet the minimum char length %d", word, KillWordMinCharLength)
+		err = errorf(ErrInvalidArgument, "%s does not meet the minimum char length %d", word, KillWordMinCharLength)
 		return
 	}
 	// validate KillDictionary (non-empty string for now)
 	if len(dictID) < 1 {
-		err = fmt.Errorf("A blank ID is not a valid KillDictionary")
+		err = errorf(ErrInvalidArgument, "A blank ID is not a valid KillDictionary")
 		return
 	}
 	id := fmt.Sprintf("%s+%s", dictID, word)
-	response = KillWord{id, dictID, word}
+	response = KillWord{ID: id, DictID: dictID, Word: word}
 	return
 }
 
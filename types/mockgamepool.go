@@ -1,10 +1,9 @@
 package types
 
 import (
-	"fmt"
+	"context"
 
 	events "wordassassin/types/events"
-	"wordassassin/slack"
 )
 
 // AddGameCall persists params from AddGame
@@ -18,49 +17,97 @@ type PlayerAddedCall struct {
 	Event			events.PlayerAddedEvent
 }
 
+// KillReportedCall persists params from ReportKill
+type KillReportedCall struct {
+	GameID			string
+	Event			events.KillReportedEvent
+}
+
+// GameStartedCall persists params from StartGame
+type GameStartedCall struct {
+	GameID			string
+	Event			events.GameStartedEvent
+}
+
+// PlayerRemovedCall persists params from RemovePlayerFromGame
+type PlayerRemovedCall struct {
+	GameID			string
+	Event			events.PlayerRemovedEvent
+}
+
+// GameAbortedCall persists params from AbortGame
+type GameAbortedCall struct {
+	GameID			string
+	Event			events.GameAbortedEvent
+}
+
 // MockGamePool provides a test mock for GamePool dependencies
 type MockGamePool struct {
 	GamesToReturn   []*Game
+	PlayersToReturn []*Player
+	AbortGameError  string
 	AddGameError    string
 	AddPlayerError  string
 	CanAddError     string
 	GetGameError    string
+	RemovePlayerError string
+	ReportKillError string
+	ReportKillWinner string
 	StartGameError  string
+	QueueDepthToReturn int
+	ErrorKind       error // the kind of error the ...Error knobs fail with, if any
 	GameAdded	 	AddGameCall
 	PlayerAdded 	PlayerAddedCall
+	KillReported	KillReportedCall
+	GameStarted		GameStartedCall
+	PlayerRemoved	PlayerRemovedCall
+	GameAborted		GameAbortedCall
+	Listeners		[]GameListener
+}
+
+// AbortGame mock
+func (mgp *MockGamePool) AbortGame(ctx context.Context, gameid string, ev events.GameAbortedEvent) error {
+	mgp.GameAborted = GameAbortedCall {
+		GameID: gameid,
+		Event: ev,
+	}
+	if mgp.AbortGameError != "" {
+		return mgp.fail(mgp.AbortGameError)
+	}
+	return nil
 }
 
 // AddGame mock
-func (mgp *MockGamePool) AddGame(game *Game) error {
+func (mgp *MockGamePool) AddGame(ctx context.Context, game *Game) error {
 	mgp.GameAdded = AddGameCall {
 		Added: game,
 	}
 	if mgp.AddGameError != "" {
-		return fmt.Errorf(mgp.AddGameError)
+		return mgp.fail(mgp.AddGameError)
 	}
 	return nil
 }
 
 // AddPlayerToGame mock
-func (mgp *MockGamePool) AddPlayerToGame(gameid string, ev events.PlayerAddedEvent) error {
+func (mgp *MockGamePool) AddPlayerToGame(ctx context.Context, gameid string, ev events.PlayerAddedEvent) error {
 	mgp.PlayerAdded = PlayerAddedCall {
 		GameID: gameid,
 		Event: ev,
 	}
 	if mgp.AddPlayerError != "" {
-		return fmt.Errorf(mgp.AddPlayerError)
+		return mgp.fail(mgp.AddPlayerError)
 	}
 	return nil
 }
 
 // CanAddPlayers mock
 // return options true, nil or false, err from CanAddError
-func (mgp *MockGamePool) CanAddPlayers(gameid string) (result bool, err error) {
+func (mgp *MockGamePool) CanAddPlayers(ctx context.Context, gameid string) (result bool, err error) {
 	if mgp.CanAddError == "" {
 		result = true
 	} else {
 		result = false
-		err = fmt.Errorf(mgp.CanAddError)
+		err = mgp.fail(mgp.CanAddError)
 	}
 	return
 }
@@ -79,15 +126,80 @@ func (mgp *MockGamePool) GetGame(id string) (*Game, bool) {
 	return &result, true
 }
 
+// GetPlayer mock. Finds the player in PlayersToReturn
+func (mgp *MockGamePool) GetPlayer(ctx context.Context, playerid string) (*Player, error) {
+	for _, p := range mgp.PlayersToReturn {
+		if playerid == p.GetID() { return p, nil }
+	}
+	return nil, errorf(ErrNotFound, "missing ID: %s", playerid)
+}
+
+// GetPlayers mock. Finds the players in PlayersToReturn that are in the game
+func (mgp *MockGamePool) GetPlayers(ctx context.Context, gameid string) (result []*Player, err error) {
+	for _, p := range mgp.PlayersToReturn {
+		if gameid == p.GameID { result = append(result, p) }
+	}
+	return
+}
+
 // GetGamesList mock
 func (mgp *MockGamePool) GetGamesList() []*Game {
 	return mgp.GamesToReturn
 }
 
+// QueueDepth mock. Returns QueueDepthToReturn
+func (mgp *MockGamePool) QueueDepth(gameid string) int {
+	return mgp.QueueDepthToReturn
+}
+
+// RemovePlayerFromGame mock
+func (mgp *MockGamePool) RemovePlayerFromGame(ctx context.Context, gameid string, ev events.PlayerRemovedEvent) error {
+	mgp.PlayerRemoved = PlayerRemovedCall {
+		GameID: gameid,
+		Event: ev,
+	}
+	if mgp.RemovePlayerError != "" {
+		return mgp.fail(mgp.RemovePlayerError)
+	}
+	return nil
+}
+
+// ReportKill mock. Finishes the matching preset game when ReportKillWinner is set
+func (mgp *MockGamePool) ReportKill(ctx context.Context, gameid string, ev events.KillReportedEvent) error {
+	mgp.KillReported = KillReportedCall {
+		GameID: gameid,
+		Event: ev,
+	}
+	if mgp.ReportKillError != "" {
+		return mgp.fail(mgp.ReportKillError)
+	}
+	// Simulate the final kill by finishing the matching preset game
+	if mgp.ReportKillWinner != "" {
+		for _, g := range mgp.GamesToReturn {
+			if gameid == g.GetID() { g.Finish(mgp.ReportKillWinner) }
+		}
+	}
+	return nil
+}
+
 // StartGame mock
-func (mgp *MockGamePool) StartGame(gameid string, slackid slack.SlackID) (err error) {
+func (mgp *MockGamePool) StartGame(ctx context.Context, gameid string, ev events.GameStartedEvent) (err error) {
+	mgp.GameStarted = GameStartedCall {
+		GameID: gameid,
+		Event:	ev,
+	}
 	if mgp.StartGameError != "" {
-		return fmt.Errorf(mgp.StartGameError)
+		return mgp.fail(mgp.StartGameError)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Subscribe mock. Keeps the listener in Listeners, but never publishes to it
+func (mgp *MockGamePool) Subscribe(l GameListener) {
+	mgp.Listeners = append(mgp.Listeners, l)
+}
+
+// fail makes the error for one of the ...Error knobs, of ErrorKind
+func (mgp *MockGamePool) fail(msg string) error {
+	return errorf(mgp.ErrorKind, "%s", msg)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"testing"
 	"github.com/stretchr/testify/require"
 
@@ -11,9 +12,9 @@ import (
 func TestMockAddGame(t *testing.T) {
 	mgp := MockGamePool{}
 	dummy := &Game{}
-	require.NoError(t, mgp.AddGame(dummy), "Mock.AddGame should not error when AddGameError is unset")
+	require.NoError(t, mgp.AddGame(context.Background(), dummy), "Mock.AddGame should not error when AddGameError is unset")
 	mgp.AddGameError = "mock error"
-	actual := mgp.AddGame(dummy)
+	actual := mgp.AddGame(context.Background(), dummy)
 	require.Error(t, actual, "Mock.AddGame should error when AddError is set")
 	require.Equal(t, actual.Error(), mgp.AddGameError, "Error message should passthrough unchanged")
 }
@@ -21,19 +22,19 @@ func TestMockAddGame(t *testing.T) {
 func TestMockAddPlayerToGame(t *testing.T) {
 	mgp := MockGamePool{}
 	dummy := events.PlayerAddedEvent{}
-	require.NoError(t, mgp.AddPlayerToGame("game", dummy), "Mock.AddPlayerToGame should not error when AddPlayerError is unset")
+	require.NoError(t, mgp.AddPlayerToGame(context.Background(), "game", dummy), "Mock.AddPlayerToGame should not error when AddPlayerError is unset")
 	mgp.AddPlayerError = "mock error"
-	actual := mgp.AddPlayerToGame("game", dummy)
+	actual := mgp.AddPlayerToGame(context.Background(), "game", dummy)
 	require.Error(t, actual, "Mock.AddPlayerToGame should error when AddPlayerError is set")
 	require.Equal(t, actual.Error(), mgp.AddPlayerError, "Error message should passthrough unchanged")
 }
 func TestCanAddPlayers(t *testing.T) {
 	mgp := MockGamePool{}
-	actual, err := mgp.CanAddPlayers("game")
+	actual, err := mgp.CanAddPlayers(context.Background(), "game")
 	require.True(t, actual, "Mock.CanAddPlayers should return true when CanAddPlayers is unset")
 	require.NoError(t, err, "Mock.CanAddPlayers should not error when CanAddPlayers is unset")
 	mgp.CanAddError = "mock error"
-	actual, err = mgp.CanAddPlayers("game")
+	actual, err = mgp.CanAddPlayers(context.Background(), "game")
 	require.False(t, actual, "Mock.CanAddPlayers should return false when CanAddPlayers is set")
 	require.Error(t, err, "Mock.CanAddPlayers should not error when CanAddPlayers is unset")
 	require.Equal(t, err.Error(), mgp.CanAddError, "Error message should passthrough unchanged")
@@ -69,15 +70,50 @@ func TestMockGetGamesList(t *testing.T) {
 	require.Equal(t, len(actual), 2, "Mock.GetGamesList should return the two preset games")
 }
 
+func TestMockReportKill(t *testing.T) {
+	mgp := MockGamePool{}
+	dummy := events.NewKillReportedInline("game", "UDead")
+	require.NoError(t, mgp.ReportKill(context.Background(), "game", dummy), "Mock.ReportKill should not error when ReportKillError is unset")
+	require.Equal(t, "game", mgp.KillReported.GameID, "Mock.ReportKill should record its arguments")
+	mgp.ReportKillError = "mock error"
+	actual := mgp.ReportKill(context.Background(), "game", dummy)
+	require.Error(t, actual, "Mock.ReportKill should error when ReportKillError is set")
+	require.Equal(t, actual.Error(), mgp.ReportKillError, "Error message should passthrough unchanged")
+}
+
 func TestMockStartGame(t *testing.T) {
 	mgp := MockGamePool{}
 	mySlackID, sErr := slack.New("UDuh")
 	require.NoError(t, sErr, "Badness when creating the slack ID")
-	require.NoError(t, mgp.StartGame("duh_game", mySlackID), "Mock.StartGame should not error when StartGameError is unset")
+	ev := events.NewGameStartedInline("duh_game", mySlackID, 7)
+	require.NoError(t, mgp.StartGame(context.Background(), "duh_game", ev), "Mock.StartGame should not error when StartGameError is unset")
+	require.Equal(t, ev, mgp.GameStarted.Event, "Mock.StartGame should record its arguments")
 	mgp.StartGameError = "mock error"
-	actual := mgp.StartGame("duh_game", mySlackID)
+	actual := mgp.StartGame(context.Background(), "duh_game", ev)
 	require.Error(t, actual, "Mock.StartGame should error when StartError is set")
 	require.Equal(t, actual.Error(), mgp.StartGameError, "Error message should passthrough unchanged")
 }
 
 
+
+func TestMockAbortGame(t *testing.T) {
+	mgp := MockGamePool{}
+	ev := events.NewGameAbortedInline("duh_game", slack.NewInline("UDuh"))
+	require.NoError(t, mgp.AbortGame(context.Background(), "duh_game", ev), "Mock.AbortGame should not error when AbortGameError is unset")
+	require.Equal(t, ev, mgp.GameAborted.Event, "Mock.AbortGame should record its arguments")
+	mgp.AbortGameError = "mock error"
+	actual := mgp.AbortGame(context.Background(), "duh_game", ev)
+	require.Error(t, actual, "Mock.AbortGame should error when AbortGameError is set")
+	require.Equal(t, actual.Error(), mgp.AbortGameError, "Error message should passthrough unchanged")
+}
+
+func TestMockRemovePlayerFromGame(t *testing.T) {
+	mgp := MockGamePool{}
+	ev := events.NewPlayerRemovedInline("duh_game", "UDuh")
+	require.NoError(t, mgp.RemovePlayerFromGame(context.Background(), "duh_game", ev), "Mock.RemovePlayerFromGame should not error when RemovePlayerError is unset")
+	require.Equal(t, ev, mgp.PlayerRemoved.Event, "Mock.RemovePlayerFromGame should record its arguments")
+	mgp.RemovePlayerError = "mock error"
+	actual := mgp.RemovePlayerFromGame(context.Background(), "duh_game", ev)
+	require.Error(t, actual, "Mock.RemovePlayerFromGame should error when RemovePlayerError is set")
+	require.Equal(t, actual.Error(), mgp.RemovePlayerError, "Error message should passthrough unchanged")
+}
@@ -1,34 +1,63 @@
 package types
 
-import (
-	"fmt"
-)
+import "context"
 
 // MockPlayerPool provides a test mock for PlayerPool dependencies
 type MockPlayerPool struct {
-	playersToReturn []*Player
-	AddPlayerError  string
-	GetPlayerError  string
+	playersToReturn    []*Player
+	AddPlayerError     string
+	GetPlayerError     string
+	RemovePlayerError  string
+	UpdatePlayersError string
+	ErrorKind          error // the kind of error the ...Error knobs fail with, if any
 }
 
 // AddPlayer mock
-func (mpp MockPlayerPool) AddPlayer(player *Player) error {
+func (mpp MockPlayerPool) AddPlayer(ctx context.Context, player *Player) error {
 	if mpp.AddPlayerError != "" {
-		return fmt.Errorf(mpp.AddPlayerError)
+		return mpp.fail(mpp.AddPlayerError)
 	}
 	return nil
 }
 
 // GetPlayerByID mock
 func (mpp MockPlayerPool) GetPlayerByID(searchid string) (*Player, error) {
-	// TBD
-	return nil, fmt.Errorf("Not implemented")
+	if mpp.GetPlayerError != "" {
+		return nil, mpp.fail(mpp.GetPlayerError)
+	}
+	for _, p := range mpp.playersToReturn {
+		if p.GetID() == searchid {
+			return p, nil
+		}
+	}
+	return nil, errorf(ErrNotFound, "missing ID: %s", searchid)
 }
 
 // GetAllPlayersInGame mock
 func (mpp MockPlayerPool) GetAllPlayersInGame(gameid string) ([]*Player, error) {
 	if mpp.GetPlayerError != "" {
-		return nil, fmt.Errorf(mpp.GetPlayerError)
+		return nil, mpp.fail(mpp.GetPlayerError)
 	}
 	return mpp.playersToReturn, nil
 }
+
+// RemovePlayer mock
+func (mpp MockPlayerPool) RemovePlayer(ctx context.Context, player *Player) error {
+	if mpp.RemovePlayerError != "" {
+		return mpp.fail(mpp.RemovePlayerError)
+	}
+	return nil
+}
+
+// UpdatePlayers mock
+func (mpp MockPlayerPool) UpdatePlayers(ctx context.Context, players ...*Player) error {
+	if mpp.UpdatePlayersError != "" {
+		return mpp.fail(mpp.UpdatePlayersError)
+	}
+	return nil
+}
+
+// fail makes the error for one of the ...Error knobs, of ErrorKind
+func (mpp MockPlayerPool) fail(msg string) error {
+	return errorf(mpp.ErrorKind, "%s", msg)
+}
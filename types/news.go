@@ -0,0 +1,66 @@
+package types
+
+import (
+	events "wordassassin/types/events"
+)
+
+// GameNews is a change a game has taken: the event that made it, along with copies of the game and of the players
+// it touched, as they were once the change was made. For a kill, that's the victim and their assassin
+type GameNews struct {
+	Event   events.GameEvent
+	Game    Game
+	Players []Player
+}
+
+// Player finds one of the players the news touched
+func (n GameNews) Player(playerid string) (*Player, bool) {
+	for i := range n.Players {
+		if n.Players[i].GetID() == playerid {
+			return &n.Players[i], true
+		}
+	}
+	return nil, false
+}
+
+// GameListener is told the news of every game in a pool, once each change is committed. Each game's news comes in
+// the order the game took it. Listeners are called on the game's actor, so they mustn't block, nor call back into
+// the pool
+type GameListener func(news GameNews)
+
+// Subscribe adds a listener to the news of every game in the pool, published as GameNews once each change is
+// committed. Games rebuilt from the event log make no news
+func (pool *GamePool) Subscribe(l GameListener) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.listeners = append(pool.listeners, l)
+}
+
+// publish tells the listeners about a change to a game. Call it on the game's actor, once the change is committed
+func (pool *GamePool) publish(ev events.GameEvent, game *Game, players ...*Player) {
+	pool.mu.RLock()
+	listeners := pool.listeners
+	pool.mu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+	news := GameNews{Event: ev, Game: *game, Players: make([]Player, len(players))}
+	for i, p := range players {
+		news.Players[i] = *p
+	}
+	for _, l := range listeners {
+		l(news)
+	}
+}
+
+// createdEvent rebuilds the event a game was created from, for the news of its creation
+func (g *Game) createdEvent() *events.GameCreatedEvent {
+	return &events.GameCreatedEvent{
+		ID:             g.GetID(),
+		TimeCreated:    g.TimeCreated,
+		EventType:      "GameCreatedEvent",
+		GameCreator:    g.GameCreator,
+		KillDictionary: g.KillDictionary,
+		Passcode:       g.Passcode,
+		Channel:        g.Channel,
+	}
+}
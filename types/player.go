@@ -1,8 +1,14 @@
 package types
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"time"
 
+	bson "go.mongodb.org/mongo-driver/bson"
+
 	events "wordassassin/types/events"
 	"wordassassin/slack"
 )
@@ -22,6 +28,10 @@ type Player struct {
 	Kills		int			  `json:"kills" bson:"kills"`
 	Target		string		  `json:"target" bson:"target"`
 	KillWord	string		  `json:"killword" bson:"killword"`
+	KilledBy	string		  `json:"killedBy" bson:"killedby"`
+	KilledWith	string		  `json:"killedWith" bson:"killedwith"`
+	TokenHash	string		  `json:"-" bson:"tokenhash"`
+	Version		int64		  `json:"version" bson:"version"`
 }
 	
 // Constants for PlayerStatus
@@ -50,15 +60,64 @@ func NewPlayerFromEvent(ev events.PlayerAddedEvent) (p Player) {
 		Email:			ev.Email,
 		Status:			Alive,
 		Kills:			0,
+		TokenHash:		ev.TokenHash,
 	}
 	return
 }
 
+// Decode populates this instance from the supplied bson
+func (p *Player) Decode(raw []byte) error {
+	if err := bson.Unmarshal(raw, p); err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetID getter for ID field
 func (p *Player) GetID() string {
 	return p.ID
 }
 
+// GetVersion getter for Version field, which counts the updates persisted for this player
+func (p *Player) GetVersion() int64 {
+	return p.Version
+}
+
+// SetVersion setter for Version field. The persistence layer moves it on with each update
+func (p *Player) SetVersion(version int64) {
+	p.Version = version
+}
+
+// IsAlive reports whether the player is still in the hunt
+func (p *Player) IsAlive() bool {
+	return p.Status == Alive
+}
+
+// Authenticate reports whether the token is the secret this player was given when they joined. Players without a
+// token can't be authenticated at all.
+func (p *Player) Authenticate(token string) bool {
+	if p.TokenHash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(p.TokenHash), []byte(HashPlayerToken(token))) == 1
+}
+
+// NewPlayerToken generates the secret a player uses to prove who they are. Only the hash is kept.
+func NewPlayerToken() (token, hash string, err error) {
+	raw := make([]byte, 16)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(raw)
+	return token, HashPlayerToken(token), nil
+}
+
+// HashPlayerToken gives the form of a player token that is safe to persist
+func HashPlayerToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // SetTarget sets not just the target element but the kill word too. Bonus!
 func (p *Player) SetTarget(targetID string, killWord string) {
 	p.Target = targetID
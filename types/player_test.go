@@ -31,3 +31,22 @@ func TestPlayer_SetTarget(t *testing.T) {
 	require.Equal(t, expectedTarget, actual.Target)
 	require.Equal(t, expectedKillword, actual.KillWord)
 }
+
+func TestPlayer_Authenticate(t *testing.T) {
+	token, hash, err := NewPlayerToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, hash, "Only the hash should be kept")
+	other, _, err := NewPlayerToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other, "Tokens should be unique")
+
+	ev := events.NewPlayerAddedInline("a_game", "Uplayer", "The Big P", "playuh@game.org")
+	ev.TokenHash = hash
+	actual := NewPlayerFromEvent(ev)
+	require.True(t, actual.Authenticate(token))
+	require.False(t, actual.Authenticate(other), "Someone else's token")
+	require.False(t, actual.Authenticate(""), "Blank token")
+
+	legacy := NewPlayerFromEvent(events.NewPlayerAddedInline("a_game", "Uold", "", ""))
+	require.False(t, legacy.Authenticate(""), "Players without a token can never authenticate")
+}
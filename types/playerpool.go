@@ -1,16 +1,21 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"sync"
+
 	persistence "wordassassin/persistence"
 	"wordassassin/slack"
 )
 
 // PlayerPoolAbstraction provides abstraction for testing PlayerPool dependencies
 type PlayerPoolAbstraction interface {
-	AddPlayer(player *Player) error
+	AddPlayer(ctx context.Context, player *Player) error
 	GetPlayerByID(searchid string) (*Player, error)
 	GetAllPlayersInGame(gameid string) ([]*Player, error)
+	RemovePlayer(ctx context.Context, player *Player) error
+	UpdatePlayers(ctx context.Context, players ...*Player) error
 }
 
 const (
@@ -18,39 +23,127 @@ const (
 	PlayersCollection string = "players"
 )
 
-// PlayerPool manages the collection of players in a single game
-// TODO: restructure as mongo backed collection
+// PlayerPool manages the collection of players across all games. Every add and state change is written through to
+// the players collection. A zero value PlayerPool works too, but only in memory.
+// The pool is safe for concurrent use, but only the map is guarded. The players themselves belong to their game,
+// and GamePool serializes changes to them per game.
 type PlayerPool struct {
+	mu      sync.RWMutex // guards players
 	players map[string]*Player
-	mongo   *persistence.MongoAbstraction
+	repo    *PlayerRepository // nil when the pool is only in memory
+}
+
+// NewPlayerPool creates an instance backed by the persistence layer and reconstitutes any players already there
+// Errors:
+// -- the stored players can't be read or decoded
+// -- two stored players share an ID
+func NewPlayerPool(ctx context.Context, m persistence.MongoAbstraction) (*PlayerPool, error) {
+	result := &PlayerPool{
+		players: make(map[string]*Player, 10),
+		repo:    NewPlayerRepository(m),
+	}
+	existing, err := result.repo.Find(ctx, AllPlayers())
+	if err != nil {
+		return nil, fmt.Errorf("NewPlayerPool: %w", err)
+	}
+	if err = result.ReconstitutePool(existing); err != nil {
+		return nil, fmt.Errorf("NewPlayerPool: %w", err)
+	}
+	return result, nil
 }
 
-// AddPlayer adds a player to this pool. Enforces uniqueness of the Player.ID within the pool
+// AddPlayer adds a player to this pool and persists the addition. Enforces uniqueness of the Player.ID within the pool
 // Errors:
 //   Player.ID field is blank
 //   Player.ID already exists in the pool
-func (pool *PlayerPool) AddPlayer(player *Player) error {
+//   mongo issue on write. The player is not added
+func (pool *PlayerPool) AddPlayer(ctx context.Context, player *Player) error {
+	if player.GetID() == "" {
+		return errorf(ErrInvalidArgument, "missing ID for AddPlayer")
+	}
+	if _, exists := pool.lookup(player.GetID()); exists {
+		return errorf(ErrDuplicate, "duplicate ID on add: %s", player.GetID())
+	}
+	// The write happens outside the lock. Mongo settles a race between two adds of the same ID
+	if pool.repo != nil {
+		if err := pool.repo.Save(ctx, player); err != nil {
+			return err
+		}
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
 	// create the players map as a singleton
 	if pool.players == nil {
 		pool.players = make(map[string]*Player, 10)
 	}
-	if player.GetID() == "" {
-		return fmt.Errorf("missing ID for AddPlayer")
-	}
 	if _, exists := pool.players[player.GetID()]; exists {
-		return fmt.Errorf("duplicate ID on add: %s", player.GetID())
+		return errorf(ErrDuplicate, "duplicate ID on add: %s", player.GetID())
 	}
 	pool.players[player.GetID()] = player
 	return nil
 }
 
+// RemovePlayer takes a player out of this pool and deletes their persisted record
+// Errors:
+//   the player isn't in the pool
+//   mongo issue on delete. The player stays in the pool
+func (pool *PlayerPool) RemovePlayer(ctx context.Context, player *Player) error {
+	if _, exists := pool.lookup(player.GetID()); !exists {
+		return errorf(ErrNotFound, "missing ID for RemovePlayer: %s", player.GetID())
+	}
+	if pool.repo != nil {
+		if err := pool.repo.Delete(ctx, player.GetID()); err != nil {
+			return err
+		}
+	}
+	pool.mu.Lock()
+	delete(pool.players, player.GetID())
+	pool.mu.Unlock()
+	return nil
+}
+
+// UpdatePlayers persists the current state of players already in the pool
+// Errors:
+//   a player isn't in the pool
+//   mongo issue on update. Stops at the first failure
+func (pool *PlayerPool) UpdatePlayers(ctx context.Context, players ...*Player) error {
+	for _, player := range players {
+		if _, exists := pool.lookup(player.GetID()); !exists {
+			return errorf(ErrNotFound, "missing ID for UpdatePlayers: %s", player.GetID())
+		}
+		if pool.repo == nil {
+			continue
+		}
+		if err := pool.repo.Update(ctx, player); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReconstitutePool rebuilds the pool from an array of Players
+func (pool *PlayerPool) ReconstitutePool(players []*Player) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.players == nil {
+		pool.players = make(map[string]*Player, len(players))
+	}
+	for _, player := range players {
+		if _, exists := pool.players[player.GetID()]; exists {
+			return errorf(ErrDuplicate, "duplicate ID on add: %s", player.GetID())
+		}
+		pool.players[player.GetID()] = player
+	}
+	return nil
+}
+
 // GetPlayerByID fetches the player when the ID is known.
 // Errors:
 //   ID not found.
 func (pool *PlayerPool) GetPlayerByID(searchid string) (*Player, error) {
-	result, exists := pool.players[searchid]
+	result, exists := pool.lookup(searchid)
 	if !exists {
-		return nil, fmt.Errorf("missing ID: %s", searchid)
+		return nil, errorf(ErrNotFound, "missing ID: %s", searchid)
 	}
 	return result, nil
 }
@@ -65,18 +158,21 @@ func (pool *PlayerPool) GetPlayer(gameid string, slackid slack.SlackID) (*Player
 
 // GetAllPlayersInGame fetches all of the players for a given gameid.
 func (pool *PlayerPool) GetAllPlayersInGame(gameid string) (playersInGame []*Player, err error) {
-	/*
-		//TODO: restructure to use mongo fetch
-		query := bson.NewDocument(
-			bson.EC.String("gameid", gameid)
-		)
-		result := mongo.FetchAllFromCollection()
-		players = PlayerPool.bytesToPlayers(result)
-	*/
-	for _, v := range pool.players {
-		if v.GameID == gameid {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	// Match on the map key rather than Player.GameID, since players in other games may be mid-change
+	for id, v := range pool.players {
+		if gameIDOf(id) == gameid {
 			playersInGame = append(playersInGame, v)
 		}
 	}
 	return
 }
+
+// lookup finds a player under the read lock
+func (pool *PlayerPool) lookup(id string) (*Player, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	player, exists := pool.players[id]
+	return player, exists
+}
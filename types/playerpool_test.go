@@ -1,15 +1,19 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	persistence "wordassassin/persistence"
 )
 
 func TestPlayerPool(t *testing.T) {
 	// Setup
 	target := PlayerPool{}
-	require.NotNil(t, target)
+	require.NotNil(t, &target)
 	p1 := addPlayerToPool(t, &target, "game1", "UJoe", "Joe", "joe@wa.org")
 	p2 := addPlayerToPool(t, &target, "game2", "UJoe", "Joe", "joe@wa.org")
 	addPlayerToPool(t, &target, "game3", "UJoe", "Joe", "joe@wa.org")
@@ -18,14 +22,14 @@ func TestPlayerPool(t *testing.T) {
 
 	// Execute
 	t.Run("AddPlayer: Duplicate ID", func(t *testing.T) {
-		err := target.AddPlayer(&p1)
+		err := target.AddPlayer(context.Background(), &p1)
 		require.Error(t, err, "Should have thrown on duplicate")
 		require.Contains(t, err.Error(), "duplicate", "Error must mention the issue")
 	})
 	t.Run("AddPlayer: Missing ID", func(t *testing.T) {
 		p3 := p1
 		p3.ID = ""
-		err := target.AddPlayer(&p3)
+		err := target.AddPlayer(context.Background(), &p3)
 		require.Error(t, err, "Should have thrown on missing ID")
 		require.Contains(t, err.Error(), "missing", "Error must mention the issue")
 	})
@@ -68,6 +72,87 @@ func TestPlayerPool(t *testing.T) {
 
 }
 
+func TestNewPlayerPool_Reconstitute(t *testing.T) {
+	alive, _ := NewPlayer("restart", "UAlive", "Al", "al@wa.org")
+	alive.SetTarget("restart+UDead", "teapot")
+	alive.Kills = 1
+	dead, _ := NewPlayer("restart", "UDead", "Ded", "ded@wa.org")
+	dead.Status = Dead
+	dead.KilledBy = alive.GetID()
+	dead.KilledWith = "walrus"
+
+	mm := persistence.NewMockMongoSession()
+	mm.FetchResults = []persistence.Persistable{ &alive, &dead }
+	target, err := NewPlayerPool(context.Background(), mm)
+	require.NoError(t, err)
+
+	t.Run("Players restored with their state", func(t *testing.T) {
+		actual, err := target.GetPlayerByID(alive.GetID())
+		require.NoError(t, err)
+		require.Equal(t, "restart+UDead", actual.Target)
+		require.Equal(t, "teapot", actual.KillWord)
+		require.Equal(t, 1, actual.Kills)
+		actual, err = target.GetPlayerByID(dead.GetID())
+		require.NoError(t, err)
+		require.Equal(t, Dead, actual.Status)
+		require.Equal(t, "walrus", actual.KilledWith)
+		inGame, _ := target.GetAllPlayersInGame("restart")
+		require.Len(t, inGame, 2)
+	})
+	t.Run("Duplicates on restore", func(t *testing.T) {
+		mm.FetchResults = []persistence.Persistable{ &alive, &alive }
+		_, err := NewPlayerPool(context.Background(), mm)
+		require.True(t, errors.Is(err, ErrDuplicate))
+		require.Contains(t, err.Error(), "NewPlayerPool: duplicate ID on add: restart+UAlive")
+	})
+	t.Run("Mongo failure on restore", func(t *testing.T) {
+		mm.QueryMode = "fail"
+		defer func() { mm.QueryMode = "positive" }()
+		_, err := NewPlayerPool(context.Background(), mm)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "NewPlayerPool: ")
+	})
+}
+
+func TestPlayerPool_Persistence(t *testing.T) {
+	mm := persistence.NewMockMongoSession()
+	target, err := NewPlayerPool(context.Background(), mm)
+	require.NoError(t, err)
+	p1 := addPlayerToPool(t, target, "game1", "UJoe", "Joe", "joe@wa.org")
+
+	t.Run("AddPlayer: write failure", func(t *testing.T) {
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		addPlayerToPool(t, target, "game1", "UJim", "Jim", "jim@wa.org", "Mock error on write")
+		_, err := target.GetPlayerByID("game1+UJim")
+		require.Error(t, err, "Players that fail to persist stay out of the pool")
+	})
+	t.Run("AddPlayer: duplicate in mongo", func(t *testing.T) {
+		mm.WriteMode = "duplicate"
+		defer func() { mm.WriteMode = "positive" }()
+		addPlayerToPool(t, target, "game1", "UJosh", "Josh", "josh@wa.org", "duplicate")
+	})
+	t.Run("UpdatePlayers: positive", func(t *testing.T) {
+		actual, _ := target.GetPlayerByID(p1.GetID())
+		actual.Kills++
+		require.NoError(t, target.UpdatePlayers(context.Background(), actual))
+	})
+	t.Run("UpdatePlayers: not in pool", func(t *testing.T) {
+		stranger, _ := NewPlayer("game1", "UStranger", "Who", "who@wa.org")
+		err := target.UpdatePlayers(context.Background(), &stranger)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing ID for UpdatePlayers: game1+UStranger")
+	})
+	t.Run("UpdatePlayers: update failure", func(t *testing.T) {
+		mm.WriteMode = "fail"
+		defer func() { mm.WriteMode = "positive" }()
+		actual, _ := target.GetPlayerByID(p1.GetID())
+		err := target.UpdatePlayers(context.Background(), actual)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Mock error on update")
+	})
+}
+
 // *** Helpers *** //
 
 // addPlayerToPool creates and adds a player to the PlayerPool. If an error is expected, it validates that it contains
@@ -75,7 +160,7 @@ func TestPlayerPool(t *testing.T) {
 func addPlayerToPool(t *testing.T, pool *PlayerPool, gameid, slackid, name, email string, expectError ...string) Player {
 	player, pErr := NewPlayer(gameid, slackid, name, email)
 	require.NoErrorf(t, pErr, "Error on creation for addPlayerToPool: %v", pErr)
-	err := pool.AddPlayer(&player)
+	err := pool.AddPlayer(context.Background(), &player)
 	if len(expectError) > 0 {
 		require.Error(t, err, "Wanted to see error adding to the test pool")
 		require.Contains(t, err.Error(), expectError[0], "Wanted to see error adding to the test pool")
@@ -84,3 +169,10 @@ func addPlayerToPool(t *testing.T, pool *PlayerPool, gameid, slackid, name, emai
 	}
 	return player
 }
+
+// newPlayerPool creates a PlayerPool over the store, failing the test if the players in it can't be restored
+func newPlayerPool(t *testing.T, m persistence.MongoAbstraction) *PlayerPool {
+	pool, err := NewPlayerPool(context.Background(), m)
+	require.NoError(t, err)
+	return pool
+}
@@ -0,0 +1,265 @@
+package types
+
+import (
+	"context"
+	"fmt"
+
+	events "wordassassin/types/events"
+	persistence "wordassassin/persistence"
+)
+
+const (
+	// EventsCollection const for the mongo collection holding the event log
+	EventsCollection string = "events"
+)
+
+// Replayer rebuilds games and players from scratch by applying the event log in the order each game logged it.
+// Deals are rebuilt from the TargetAssignedEvents recorded along the way, rather than drawn again, so a replay doesn't
+// depend on the kill dictionaries as they are now. Games pick their dictionary up again when they next need a word.
+type Replayer struct {
+	events  *EventRepository
+	games   map[string]*Game
+	players map[string]*Player
+}
+
+// NewReplayer creates a Replayer reading from the given persistence layer
+func NewReplayer(m persistence.MongoAbstraction) *Replayer {
+	return &Replayer{
+		events:  NewEventRepository(m),
+		games:   make(map[string]*Game, 10),
+		players: make(map[string]*Player, 10),
+	}
+}
+
+// Replay reads every event and applies them in order, returning the rebuilt games and players
+// Errors:
+// -- mongo issue
+// -- an event can't be decoded, or has no decoder for its EventType
+// -- an event doesn't fit the state built so far (e.g. a player added to a missing game)
+func (r *Replayer) Replay(ctx context.Context) (games []*Game, players []*Player, err error) {
+	log, err := r.events.Find(ctx, AllEvents())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Replay: %w", err)
+	}
+	for _, ev := range log {
+		if err = r.apply(ev); err != nil {
+			return nil, nil, fmt.Errorf("Replay: event %s: %w", ev.GetID(), err)
+		}
+		// The game picks its log up from here
+		if game, exists := r.games[ev.GetGameID()]; exists && ev.GetSeq() > game.EventSeq {
+			game.EventSeq = ev.GetSeq()
+		}
+	}
+
+	for _, g := range r.games {
+		games = append(games, g)
+	}
+	for _, p := range r.players {
+		players = append(players, p)
+	}
+	return games, players, nil
+}
+
+// apply hands the event to the applier for its type
+func (r *Replayer) apply(ev events.GameEvent) error {
+	switch ev := ev.(type) {
+	case *events.GameCreatedEvent:
+		return r.applyGameCreated(ev)
+	case *events.PlayerAddedEvent:
+		return r.applyPlayerAdded(ev)
+	case *events.PlayerRemovedEvent:
+		return r.applyPlayerRemoved(ev)
+	case *events.GameStartedEvent:
+		return r.applyGameStarted(ev)
+	case *events.KillReportedEvent:
+		return r.applyKillReported(ev)
+	case *events.TargetAssignedEvent:
+		return r.applyTargetAssigned(ev)
+	case *events.GameCompletedEvent:
+		return r.applyGameCompleted(ev)
+	case *events.GameAbortedEvent:
+		return r.applyGameAborted(ev)
+	}
+	return fmt.Errorf("no applier for %T", ev)
+}
+
+// RebuildPools creates a GamePool and PlayerPool from the event log instead of the games and players snapshots.
+// Later changes are written through to the snapshots as usual, over whichever version of them is stored.
+func RebuildPools(ctx context.Context, m persistence.MongoAbstraction) (*GamePool, *PlayerPool, error) {
+	games, players, err := NewReplayer(m).Replay(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = adoptVersions(ctx, m, games, players); err != nil {
+		return nil, nil, err
+	}
+	pp := &PlayerPool{repo: NewPlayerRepository(m)}
+	if err = pp.ReconstitutePool(players); err != nil {
+		return nil, nil, err
+	}
+	gp := &GamePool{games: make(map[string]*Game, len(games)), actors: make(map[string]*gameActor, len(games)), mongo: m,
+		store: NewGameRepository(m), events: NewEventRepository(m), players: pp, playerStore: NewPlayerRepository(m)}
+	if err = gp.ReconstitutePool(games); err != nil {
+		return nil, nil, err
+	}
+	return gp, pp, nil
+}
+
+// adoptVersions gives replayed games and players the versions of their stored snapshots, which replay knows nothing
+// of. Those without a snapshot stay at version 0
+func adoptVersions(ctx context.Context, m persistence.MongoAbstraction, games []*Game, players []*Player) error {
+	stored, err := NewGameRepository(m).Find(ctx, AllGames())
+	if err != nil {
+		return fmt.Errorf("RebuildPools: %w", err)
+	}
+	gameVersions := make(map[string]int64, len(stored))
+	for _, g := range stored {
+		gameVersions[g.GetID()] = g.Version
+	}
+	for _, g := range games {
+		g.Version = gameVersions[g.GetID()]
+	}
+	storedPlayers, err := NewPlayerRepository(m).Find(ctx, AllPlayers())
+	if err != nil {
+		return fmt.Errorf("RebuildPools: %w", err)
+	}
+	playerVersions := make(map[string]int64, len(storedPlayers))
+	for _, p := range storedPlayers {
+		playerVersions[p.GetID()] = p.Version
+	}
+	for _, p := range players {
+		p.Version = playerVersions[p.GetID()]
+	}
+	return nil
+}
+
+func (r *Replayer) applyGameCreated(ev *events.GameCreatedEvent) error {
+	if _, exists := r.games[ev.ID]; exists {
+		return fmt.Errorf("duplicate game %s", ev.ID)
+	}
+	game := NewGameFromEvent(*ev)
+	r.games[game.GetID()] = &game
+	return nil
+}
+
+func (r *Replayer) applyPlayerAdded(ev *events.PlayerAddedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	if game.Status != Starting {
+		return fmt.Errorf("game %s is not accepting players. State=%s", game.GetID(), game.Status)
+	}
+	if _, exists := r.players[ev.ID]; exists {
+		return fmt.Errorf("duplicate player %s", ev.ID)
+	}
+	player := NewPlayerFromEvent(*ev)
+	r.players[player.GetID()] = &player
+	game.StartPlayers++
+	return nil
+}
+
+func (r *Replayer) applyPlayerRemoved(ev *events.PlayerRemovedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	if game.Status != Starting {
+		return fmt.Errorf("game %s has already started. State=%s", game.GetID(), game.Status)
+	}
+	if _, exists := r.players[ev.PlayerID]; !exists {
+		return fmt.Errorf("player %s is not in game %s", ev.PlayerID, game.GetID())
+	}
+	delete(r.players, ev.PlayerID)
+	game.StartPlayers--
+	return nil
+}
+
+// applyGameStarted puts the game in play. The targets dealt at the start follow in TargetAssignedEvents
+func (r *Replayer) applyGameStarted(ev *events.GameStartedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	if game.Status != Starting {
+		return fmt.Errorf("game %s has already started. State=%s", game.GetID(), game.Status)
+	}
+	if players := r.playersInGame(game.GetID()); len(players) != game.StartPlayers {
+		return fmt.Errorf("game %s has %d players, but counted %d joining", game.GetID(), len(players), game.StartPlayers)
+	}
+	game.Status = Playing
+	game.StartTime = ev.TimeCreated
+	game.RemainPlayers = game.StartPlayers
+	return nil
+}
+
+// applyKillReported records the death. The assassin's word for their new target follows in a TargetAssignedEvent
+func (r *Replayer) applyKillReported(ev *events.KillReportedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	victim, assassin, err := game.findKill(ev.PlayerID, r.playersInGame(game.GetID()))
+	if err != nil {
+		return err
+	}
+	game.applyKill(victim, assassin, "")
+	if game.Status == Finished {
+		game.FinishTime = ev.TimeCreated
+	}
+	return nil
+}
+
+func (r *Replayer) applyGameCompleted(ev *events.GameCompletedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	if game.Status != Finished || game.Winner != ev.WinnerID {
+		return fmt.Errorf("game %s completed with winner %s, but replay has status %s and winner %q", game.GetID(), ev.WinnerID, game.Status, game.Winner)
+	}
+	return nil
+}
+
+func (r *Replayer) applyTargetAssigned(ev *events.TargetAssignedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	killer, exists := r.players[ev.KillerID]
+	if !exists || !killer.IsAlive() {
+		return fmt.Errorf("player %s is not alive in game %s", ev.KillerID, game.GetID())
+	}
+	killer.SetTarget(ev.TargetID, ev.KillWord)
+	game.markWordUsed(ev.KillWord)
+	return nil
+}
+
+func (r *Replayer) applyGameAborted(ev *events.GameAbortedEvent) error {
+	game, err := r.game(ev.GameID)
+	if err != nil {
+		return err
+	}
+	if err = game.Abort(); err != nil {
+		return err
+	}
+	game.FinishTime = ev.TimeCreated
+	return nil
+}
+
+func (r *Replayer) game(gameid string) (*Game, error) {
+	game, exists := r.games[gameid]
+	if !exists {
+		return nil, fmt.Errorf("game %s has not been created", gameid)
+	}
+	return game, nil
+}
+
+func (r *Replayer) playersInGame(gameid string) (result []*Player) {
+	for _, p := range r.players {
+		if p.GameID == gameid {
+			result = append(result, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,272 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	persistence "wordassassin/persistence"
+	events "wordassassin/types/events"
+	"wordassassin/slack"
+)
+
+func TestReplay_MatchesLiveGame(t *testing.T) {
+	ctx := context.Background()
+	myGameID := "replayed"
+	myCreator := slack.NewInline("UdaStarter")
+	store := persistence.NewMemorySession()
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	NewKillDictionary(ctx, store, "wordz", words...)
+
+	// Play a game live. The pool logs everything but the game's creation, which is logged here as the handler would
+	pp := newPlayerPool(t, store)
+	live := newGamePool(t, store, pp)
+	eventLog := NewEventRepository(store)
+	created := events.NewGameCreatedInline(myGameID, myCreator.ToString(), "wordz", "MickJ")
+	liveGame := NewGameFromEvent(created)
+	require.NoError(t, live.AddGame(ctx, &liveGame))
+	require.NoError(t, eventLog.Save(ctx, &created))
+	for i := 0; i < 5; i++ {
+		ev := events.NewPlayerAddedInline(myGameID, fmt.Sprintf("Urep%d", i), "", "")
+		require.NoError(t, live.AddPlayerToGame(ctx, myGameID, ev))
+	}
+	started := events.NewGameStartedInline(myGameID, myCreator, 2112)
+	require.NoError(t, live.StartGame(ctx, myGameID, started))
+	for _, victim := range []string{"Urep3", "Urep0"} {
+		require.NoError(t, live.ReportKill(ctx, myGameID, events.NewKillReportedInline(myGameID, victim)))
+	}
+	log, err := eventLog.Find(ctx, AllEvents())
+	require.NoError(t, err)
+
+	// Replay from a shuffled log, with the events spread out in time, and the dictionary gone
+	replayed := make([]persistence.Persistable, len(log))
+	for i, ev := range log {
+		stamp := created.TimeCreated.Add(time.Duration(i) * time.Second)
+		switch e := ev.(type) {
+		case *events.PlayerAddedEvent:
+			e.TimeCreated = stamp
+		case *events.GameStartedEvent:
+			e.TimeCreated = stamp
+		case *events.TargetAssignedEvent:
+			e.TimeCreated = stamp
+		case *events.KillReportedEvent:
+			e.TimeCreated = stamp
+		}
+		replayed[(i+1)%len(log)] = ev
+	}
+	replayMM := persistence.NewMockMongoSession()
+	replayMM.CollectionResults = map[string][]persistence.Persistable{ EventsCollection: replayed }
+	games, players, err := NewReplayer(replayMM).Replay(ctx)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	require.Len(t, players, 5)
+
+	require.Equal(t, liveGame.Status, games[0].Status)
+	require.Equal(t, liveGame.StartPlayers, games[0].StartPlayers)
+	require.Equal(t, liveGame.RemainPlayers, games[0].RemainPlayers)
+	for _, p := range players {
+		livePlayer, err := pp.GetPlayerByID(p.GetID())
+		require.NoError(t, err)
+		require.Equal(t, livePlayer.Status, p.Status, "Status for %s", p.GetID())
+		require.Equal(t, livePlayer.Target, p.Target, "Target for %s", p.GetID())
+		require.Equal(t, livePlayer.KillWord, p.KillWord, "KillWord for %s", p.GetID())
+		require.Equal(t, livePlayer.KilledWith, p.KilledWith, "KilledWith for %s", p.GetID())
+		require.Equal(t, livePlayer.Kills, p.Kills, "Kills for %s", p.GetID())
+		require.Equal(t, livePlayer.KilledBy, p.KilledBy, "KilledBy for %s", p.GetID())
+	}
+}
+
+func TestReplay_LifecycleEvents(t *testing.T) {
+	myCreator := slack.NewInline("UdaStarter")
+	base := time.Now()
+	stamp := func(i int) time.Time { return base.Add(time.Duration(i) * time.Second) }
+	created := events.NewGameCreatedInline("lifecycle", myCreator.ToString(), "wordz", "MickJ")
+	created.TimeCreated = stamp(0)
+	log := []persistence.Persistable{ &created }
+	for i := 0; i < 6; i++ {
+		ev := events.NewPlayerAddedInline("lifecycle", fmt.Sprintf("Ulife%d", i), "", "")
+		ev.TimeCreated = stamp(1)
+		log = append(log, &ev)
+	}
+	removed := events.NewPlayerRemovedInline("lifecycle", "Ulife5")
+	removed.TimeCreated = stamp(2)
+	started := events.NewGameStartedInline("lifecycle", myCreator, 5150)
+	started.TimeCreated = stamp(3)
+	// An assignment made after a restart, when the seed no longer explains the word
+	reassigned := events.NewTargetAssignedInline("lifecycle", "lifecycle+Ulife0", "lifecycle+Ulife1", "override")
+	reassigned.TimeCreated = stamp(4)
+	aborted := events.NewGameAbortedInline("lifecycle", myCreator)
+	aborted.TimeCreated = stamp(5)
+	log = append(log, &removed, &started, &reassigned, &aborted)
+
+	mm := persistence.NewMockMongoSession()
+	mm.CollectionResults = map[string][]persistence.Persistable{
+		CollectionName:   mockKillWords(t, "wordz", 20),
+		EventsCollection: log,
+	}
+	games, players, err := NewReplayer(mm).Replay(context.Background())
+	require.NoError(t, err)
+	require.Len(t, players, 5, "Removed player is gone")
+	require.Equal(t, 5, games[0].StartPlayers)
+	require.Equal(t, Aborted, games[0].Status)
+	require.Equal(t, stamp(5).Unix(), games[0].FinishTime.Unix())
+	for _, p := range players {
+		if p.GetID() == "lifecycle+Ulife0" {
+			require.Equal(t, "lifecycle+Ulife1", p.Target)
+			require.Equal(t, "override", p.KillWord)
+		}
+	}
+}
+
+func TestReplay_Errors(t *testing.T) {
+	created := events.NewGameCreatedInline("broken", "UdaStarter", "wordz", "MickJ")
+	orphan := events.NewPlayerAddedInline("nogame", "UOrphan", "", "")
+	orphan.TimeCreated = created.TimeCreated.Add(time.Second)
+	unknown := events.GameCompletedEvent{ID: "mystery", TimeCreated: created.TimeCreated, EventType: "MysteryEvent"}
+	notOver := events.GameCompletedEvent{ID: "broken+completed", TimeCreated: created.TimeCreated.Add(time.Second),
+		EventType: "GameCompletedEvent", GameID: "broken", WinnerID: "broken+UNobody"}
+
+	tests := []struct {
+		name    string
+		log     []persistence.Persistable
+		errText string
+	}{
+		{"Player for missing game", []persistence.Persistable{ &created, &orphan }, "game nogame has not been created"},
+		{"Unknown event type", []persistence.Persistable{ &unknown }, `no decoder for EventType "MysteryEvent"`},
+		{"Duplicate game", []persistence.Persistable{ &created, &created }, "duplicate game broken"},
+		{"Completed before finishing", []persistence.Persistable{ &created, &notOver }, "replay has status starting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := persistence.NewMockMongoSession()
+			mm.CollectionResults = map[string][]persistence.Persistable{ EventsCollection: tt.log }
+			_, _, err := NewReplayer(mm).Replay(context.Background())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.errText)
+		})
+	}
+	t.Run("Mongo failure", func(t *testing.T) {
+		mm := persistence.NewMockMongoSession()
+		mm.QueryMode = "fail"
+		_, _, err := NewReplayer(mm).Replay(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Replay:")
+	})
+}
+
+func TestRebuildPools(t *testing.T) {
+	created := events.NewGameCreatedInline("rebuilt", "UdaStarter", "wordz", "MickJ")
+	added := events.NewPlayerAddedInline("rebuilt", "UJoiner", "", "")
+	added.TimeCreated = created.TimeCreated.Add(time.Second)
+	mm := persistence.NewMockMongoSession()
+	mm.CollectionResults = map[string][]persistence.Persistable{ EventsCollection: { &added, &created } }
+
+	gp, pp, err := RebuildPools(context.Background(), mm)
+	require.NoError(t, err)
+	game, exists := gp.GetGame("rebuilt")
+	require.True(t, exists)
+	require.Equal(t, 1, game.StartPlayers)
+	_, err = pp.GetPlayerByID("rebuilt+UJoiner")
+	require.NoError(t, err)
+	require.NoError(t, gp.AddPlayerToGame(context.Background(), "rebuilt", events.NewPlayerAddedInline("rebuilt", "ULate", "", "")), "Rebuilt pools keep working")
+}
+
+// TestRebuildPools_KillsReportedTogether has two kills in one game reported at once, with the later report applied
+// first. Replay has to follow the order the pool applied them in
+func TestRebuildPools_KillsReportedTogether(t *testing.T) {
+	ctx := context.Background()
+	myCreator := slack.NewInline("UdaStarter")
+	store := persistence.NewMemorySession()
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	NewKillDictionary(ctx, store, "wordz", words...)
+	pp := newPlayerPool(t, store)
+	live := newGamePool(t, store, pp)
+	log := NewEventRepository(store)
+	created := events.NewGameCreatedInline("g1", myCreator.ToString(), "wordz", "MickJ")
+	game := NewGameFromEvent(created)
+	require.NoError(t, live.AddGame(ctx, &game))
+	require.NoError(t, log.Save(ctx, &created))
+	for i := 0; i < 6; i++ {
+		added := events.NewPlayerAddedInline("g1", fmt.Sprintf("UAAA%d", i), "", "")
+		require.NoError(t, live.AddPlayerToGame(ctx, "g1", added))
+	}
+	started := events.NewGameStartedInline("g1", myCreator, 1)
+	require.NoError(t, live.StartGame(ctx, "g1", started))
+
+	// y is reported dead first, but the kill of y's target z reaches the game first
+	y, _ := pp.GetPlayerByID("g1+UAAA0")
+	z, _ := pp.GetPlayerByID(y.Target)
+	first := events.NewKillReportedInline("g1", y.SlackID.ToString())
+	second := events.NewKillReportedInline("g1", z.SlackID.ToString())
+	second.TimeCreated = first.TimeCreated.Add(time.Second)
+	require.NoError(t, live.ReportKill(ctx, "g1", second))
+	require.NoError(t, live.ReportKill(ctx, "g1", first))
+
+	gp, rebuilt, err := RebuildPools(ctx, store)
+	require.NoError(t, err)
+	rebuiltGame, _ := gp.GetGame("g1")
+	require.Equal(t, 4, rebuiltGame.RemainPlayers)
+	for _, id := range []string{y.GetID(), z.GetID()} {
+		livePlayer, _ := pp.GetPlayerByID(id)
+		p, err := rebuilt.GetPlayerByID(id)
+		require.NoError(t, err)
+		require.Equal(t, Dead, p.Status)
+		require.Equal(t, livePlayer.KilledBy, p.KilledBy, "KilledBy for %s", id)
+	}
+}
+
+// TestRebuildPools_CommandsInterleaved has commands reach the game in a different order from the one their events were
+// made in: a join made after the start but applied before it, and a kill applied before an abort made ahead of it.
+// Replay has to follow the order the game applied them in
+func TestRebuildPools_CommandsInterleaved(t *testing.T) {
+	ctx := context.Background()
+	myCreator := slack.NewInline("UdaStarter")
+	store := persistence.NewMemorySession()
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%03d", i)
+	}
+	NewKillDictionary(ctx, store, "wordz", words...)
+	pp := newPlayerPool(t, store)
+	live := newGamePool(t, store, pp)
+	created := events.NewGameCreatedInline("g1", myCreator.ToString(), "wordz", "MickJ")
+	game := NewGameFromEvent(created)
+	require.NoError(t, live.AddGame(ctx, &game))
+	require.NoError(t, NewEventRepository(store).Save(ctx, &created))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, live.AddPlayerToGame(ctx, "g1", events.NewPlayerAddedInline("g1", fmt.Sprintf("UAAA%d", i), "", "")))
+	}
+
+	started := events.NewGameStartedInline("g1", myCreator, 1)
+	late := events.NewPlayerAddedInline("g1", "ULATE", "", "")
+	late.TimeCreated = started.TimeCreated.Add(time.Second)
+	require.NoError(t, live.AddPlayerToGame(ctx, "g1", late))
+	require.NoError(t, live.StartGame(ctx, "g1", started))
+
+	aborted := events.NewGameAbortedInline("g1", myCreator)
+	killed := events.NewKillReportedInline("g1", "UAAA0")
+	killed.TimeCreated = aborted.TimeCreated.Add(time.Second)
+	require.NoError(t, live.ReportKill(ctx, "g1", killed))
+	require.NoError(t, live.AbortGame(ctx, "g1", aborted))
+
+	gp, rebuilt, err := RebuildPools(ctx, store)
+	require.NoError(t, err)
+	liveGame, _ := live.GetGame("g1")
+	rebuiltGame, _ := gp.GetGame("g1")
+	require.Equal(t, Aborted, rebuiltGame.Status)
+	require.Equal(t, 6, rebuiltGame.StartPlayers, "The late join counts")
+	require.Equal(t, 5, rebuiltGame.RemainPlayers, "The kill counts")
+	require.Equal(t, liveGame.EventSeq, rebuiltGame.EventSeq, "The rebuilt game carries on the log where it left off")
+	victim, err := rebuilt.GetPlayer("g1", "UAAA0")
+	require.NoError(t, err)
+	require.Equal(t, Dead, victim.Status)
+}
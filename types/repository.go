@@ -0,0 +1,428 @@
+package types
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	bson "go.mongodb.org/mongo-driver/bson"
+
+	persistence "wordassassin/persistence"
+	events "wordassassin/types/events"
+)
+
+// The repositories give typed access to each collection, so the rest of the package deals in games, players,
+// events and words rather than the raw bson the persistence layer hands back. A record that can't be decoded fails
+// the call with ErrUndecodable. Queries are sent to the store as filters and checked again on the way back, so a
+// store that can't apply a filter, such as the mock, still gives the same results.
+
+// GameQuery picks out games. Start from AllGames and narrow it down
+type GameQuery struct {
+	filter bson.M
+	keep   []func(*Game) bool
+}
+
+// AllGames matches every game
+func AllGames() GameQuery {
+	return GameQuery{filter: bson.M{}}
+}
+
+// WithStatus narrows the query to games in the given state
+func (q GameQuery) WithStatus(status GameStatus) GameQuery {
+	filter := copyFilter(q.filter)
+	filter["status"] = status
+	return GameQuery{filter, append(q.keep[:len(q.keep):len(q.keep)], func(g *Game) bool { return g.Status == status })}
+}
+
+// GameRepository reads and writes the games collection
+type GameRepository struct {
+	mongo persistence.MongoAbstraction
+}
+
+// NewGameRepository creates a repository over the given persistence layer
+func NewGameRepository(m persistence.MongoAbstraction) *GameRepository {
+	return &GameRepository{mongo: m}
+}
+
+// FindID fetches a single game
+// Errors:
+// -- no game with that ID
+// -- the record can't be decoded
+// -- mongo issue
+func (r *GameRepository) FindID(ctx context.Context, id string) (*Game, error) {
+	raw, err := r.mongo.FetchIDFromCollection(ctx, GamesCollection, id)
+	if err != nil {
+		return nil, err
+	}
+	game := &Game{}
+	if err = game.Decode(raw); err != nil {
+		return nil, undecodable(GamesCollection, raw, err)
+	}
+	return game, nil
+}
+
+// Find fetches the games that match the query, in the order the store keeps them
+func (r *GameRepository) Find(ctx context.Context, q GameQuery) ([]*Game, error) {
+	raws, err := r.mongo.FetchFromCollection(ctx, GamesCollection, q.filter)
+	if err != nil {
+		return nil, err
+	}
+	games := make([]*Game, 0, len(raws))
+next:
+	for _, raw := range raws {
+		game := &Game{}
+		if err = game.Decode(raw); err != nil {
+			return nil, undecodable(GamesCollection, raw, err)
+		}
+		for _, keep := range q.keep {
+			if !keep(game) {
+				continue next
+			}
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// Save writes a new game. Fails with ErrDuplicate if the ID is taken
+func (r *GameRepository) Save(ctx context.Context, game *Game) error {
+	return r.mongo.WriteCollection(ctx, GamesCollection, game)
+}
+
+// Update replaces a game already saved. Fails with ErrNotFound if it isn't
+func (r *GameRepository) Update(ctx context.Context, game *Game) error {
+	return r.mongo.UpdateCollection(ctx, GamesCollection, game)
+}
+
+// Delete removes a game. Fails with ErrNotFound if it isn't there
+func (r *GameRepository) Delete(ctx context.Context, id string) error {
+	return r.mongo.DeleteFromCollection(ctx, GamesCollection, id)
+}
+
+// PlayerQuery picks out players. Start from AllPlayers and narrow it down
+type PlayerQuery struct {
+	filter bson.M
+	keep   []func(*Player) bool
+}
+
+// AllPlayers matches every player in every game
+func AllPlayers() PlayerQuery {
+	return PlayerQuery{filter: bson.M{}}
+}
+
+// InGame narrows the query to the players in one game
+func (q PlayerQuery) InGame(gameid string) PlayerQuery {
+	filter := copyFilter(q.filter)
+	filter["gameid"] = gameid
+	return PlayerQuery{filter, append(q.keep[:len(q.keep):len(q.keep)], func(p *Player) bool { return p.GameID == gameid })}
+}
+
+// Alive narrows the query to the players still in the hunt
+func (q PlayerQuery) Alive() PlayerQuery {
+	filter := copyFilter(q.filter)
+	filter["status"] = Alive
+	return PlayerQuery{filter, append(q.keep[:len(q.keep):len(q.keep)], (*Player).IsAlive)}
+}
+
+// PlayerRepository reads and writes the players collection
+type PlayerRepository struct {
+	mongo persistence.MongoAbstraction
+}
+
+// NewPlayerRepository creates a repository over the given persistence layer
+func NewPlayerRepository(m persistence.MongoAbstraction) *PlayerRepository {
+	return &PlayerRepository{mongo: m}
+}
+
+// FindID fetches a single player
+// Errors:
+// -- no player with that ID
+// -- the record can't be decoded
+// -- mongo issue
+func (r *PlayerRepository) FindID(ctx context.Context, id string) (*Player, error) {
+	raw, err := r.mongo.FetchIDFromCollection(ctx, PlayersCollection, id)
+	if err != nil {
+		return nil, err
+	}
+	player := &Player{}
+	if err = player.Decode(raw); err != nil {
+		return nil, undecodable(PlayersCollection, raw, err)
+	}
+	return player, nil
+}
+
+// Find fetches the players that match the query, in the order the store keeps them
+func (r *PlayerRepository) Find(ctx context.Context, q PlayerQuery) ([]*Player, error) {
+	raws, err := r.mongo.FetchFromCollection(ctx, PlayersCollection, q.filter)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]*Player, 0, len(raws))
+next:
+	for _, raw := range raws {
+		player := &Player{}
+		if err = player.Decode(raw); err != nil {
+			return nil, undecodable(PlayersCollection, raw, err)
+		}
+		for _, keep := range q.keep {
+			if !keep(player) {
+				continue next
+			}
+		}
+		players = append(players, player)
+	}
+	return players, nil
+}
+
+// Save writes a new player. Fails with ErrDuplicate if the ID is taken
+func (r *PlayerRepository) Save(ctx context.Context, player *Player) error {
+	return r.mongo.WriteCollection(ctx, PlayersCollection, player)
+}
+
+// Update replaces a player already saved. Fails with ErrNotFound if they aren't
+func (r *PlayerRepository) Update(ctx context.Context, player *Player) error {
+	return r.mongo.UpdateCollection(ctx, PlayersCollection, player)
+}
+
+// Delete removes a player. Fails with ErrNotFound if they aren't there
+func (r *PlayerRepository) Delete(ctx context.Context, id string) error {
+	return r.mongo.DeleteFromCollection(ctx, PlayersCollection, id)
+}
+
+// eventHeader holds the fields common to every event, enough to order the log, pick a decoder and match a query.
+// A GameCreatedEvent has no GameID; its ID is the game's
+type eventHeader struct {
+	ID          string    `bson:"_id"`
+	TimeCreated time.Time `bson:"timecreated"`
+	Seq         int64     `bson:"seq"`
+	EventType   string    `bson:"eventtype"`
+	GameID      string    `bson:"gameid"`
+}
+
+func (h eventHeader) gameID() string {
+	if h.EventType == "GameCreatedEvent" {
+		return h.ID
+	}
+	return h.GameID
+}
+
+// decodableEvent is an event that can be read back from the store
+type decodableEvent interface {
+	events.GameEvent
+	Decode(raw []byte) error
+}
+
+// eventDecoders makes an empty event for each EventType, ready to decode into
+var eventDecoders = map[string]func() decodableEvent{
+	"GameCreatedEvent":    func() decodableEvent { return &events.GameCreatedEvent{} },
+	"PlayerAddedEvent":    func() decodableEvent { return &events.PlayerAddedEvent{} },
+	"PlayerRemovedEvent":  func() decodableEvent { return &events.PlayerRemovedEvent{} },
+	"GameStartedEvent":    func() decodableEvent { return &events.GameStartedEvent{} },
+	"KillReportedEvent":   func() decodableEvent { return &events.KillReportedEvent{} },
+	"TargetAssignedEvent": func() decodableEvent { return &events.TargetAssignedEvent{} },
+	"GameCompletedEvent":  func() decodableEvent { return &events.GameCompletedEvent{} },
+	"GameAbortedEvent":    func() decodableEvent { return &events.GameAbortedEvent{} },
+}
+
+// EventQuery picks out events. Start from AllEvents and narrow it down
+type EventQuery struct {
+	filter bson.M
+	keep   []func(eventHeader) bool
+}
+
+// AllEvents matches the whole event log
+func AllEvents() EventQuery {
+	return EventQuery{filter: bson.M{}}
+}
+
+// ForGame narrows the query to the events for one game. The game's GameCreatedEvent can't be told apart by a filter,
+// so this one is only applied on the way back
+func (q EventQuery) ForGame(gameid string) EventQuery {
+	return EventQuery{copyFilter(q.filter), append(q.keep[:len(q.keep):len(q.keep)], func(h eventHeader) bool { return h.gameID() == gameid })}
+}
+
+// OfType narrows the query to events with the given EventType, such as "KillReportedEvent"
+func (q EventQuery) OfType(eventType string) EventQuery {
+	filter := copyFilter(q.filter)
+	filter["eventtype"] = eventType
+	return EventQuery{filter, append(q.keep[:len(q.keep):len(q.keep)], func(h eventHeader) bool { return h.EventType == eventType })}
+}
+
+// EventRepository reads and writes the event log
+type EventRepository struct {
+	mongo persistence.MongoAbstraction
+}
+
+// NewEventRepository creates a repository over the given persistence layer
+func NewEventRepository(m persistence.MongoAbstraction) *EventRepository {
+	return &EventRepository{mongo: m}
+}
+
+// Find fetches the events that match the query, each decoded into its own type, in the order they happened. Each
+// game's events are in Seq order, which is the order its actor applied them in. The games are interleaved by time,
+// which is only kept to the millisecond, so events of different games logged together may come in either order
+// Errors:
+// -- an event can't be decoded, or has no decoder for its EventType
+// -- mongo issue
+func (r *EventRepository) Find(ctx context.Context, q EventQuery) ([]events.GameEvent, error) {
+	raws, err := r.mongo.FetchFromCollection(ctx, EventsCollection, q.filter)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]eventHeader, 0, len(raws))
+	found := make([]events.GameEvent, 0, len(raws))
+next:
+	for _, raw := range raws {
+		var h eventHeader
+		if err = bson.Unmarshal(raw, &h); err != nil {
+			return nil, undecodable(EventsCollection, raw, err)
+		}
+		for _, keep := range q.keep {
+			if !keep(h) {
+				continue next
+			}
+		}
+		decoder, exists := eventDecoders[h.EventType]
+		if !exists {
+			return nil, errorf(ErrUndecodable, "%s: no decoder for EventType %q (event %s)", EventsCollection, h.EventType, h.ID)
+		}
+		ev := decoder()
+		if err = ev.Decode(raw); err != nil {
+			return nil, undecodable(EventsCollection, raw, err)
+		}
+		headers = append(headers, h)
+		found = append(found, ev)
+	}
+	order := make([]int, len(found))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return headers[order[i]].TimeCreated.Before(headers[order[j]].TimeCreated)
+	})
+	// Within the places each game's events take by time, put them in Seq order
+	places := make(map[string][]int)
+	for i, o := range order {
+		places[headers[o].gameID()] = append(places[headers[o].gameID()], i)
+	}
+	for _, slots := range places {
+		inGame := make([]int, len(slots))
+		for i, slot := range slots {
+			inGame[i] = order[slot]
+		}
+		sort.SliceStable(inGame, func(i, j int) bool { return headers[inGame[i]].Seq < headers[inGame[j]].Seq })
+		for i, slot := range slots {
+			order[slot] = inGame[i]
+		}
+	}
+	result := make([]events.GameEvent, len(found))
+	for i, o := range order {
+		result[i] = found[o]
+	}
+	return result, nil
+}
+
+// Save appends an event to the log. Fails with ErrDuplicate if the ID is taken, which is how the same thing is kept
+// from happening twice
+func (r *EventRepository) Save(ctx context.Context, ev events.GameEvent) error {
+	return r.mongo.WriteCollection(ctx, EventsCollection, ev)
+}
+
+// Delete backs an event out of the log. Fails with ErrNotFound if it isn't there
+func (r *EventRepository) Delete(ctx context.Context, id string) error {
+	return r.mongo.DeleteFromCollection(ctx, EventsCollection, id)
+}
+
+// DictionaryRepository reads and writes the words of every KillDictionary
+type DictionaryRepository struct {
+	mongo persistence.MongoAbstraction
+}
+
+// NewDictionaryRepository creates a repository over the given persistence layer
+func NewDictionaryRepository(m persistence.MongoAbstraction) *DictionaryRepository {
+	return &DictionaryRepository{mongo: m}
+}
+
+// Find fetches every word in one dictionary, sorted by word. A dictionary that doesn't exist has no words
+func (r *DictionaryRepository) Find(ctx context.Context, dictid string) ([]KillWord, error) {
+	raws, err := r.mongo.FetchFromCollection(ctx, CollectionName, bson.M{"dictid": dictid})
+	if err != nil {
+		return nil, err
+	}
+	words := make([]KillWord, 0, len(raws))
+	for _, raw := range raws {
+		var kw KillWord
+		if err = kw.Decode(raw); err != nil {
+			return nil, undecodable(CollectionName, raw, err)
+		}
+		if kw.DictID == dictid {
+			words = append(words, kw)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i].Word < words[j].Word })
+	return words, nil
+}
+
+// Summaries counts the words in every dictionary, sorted by dictionary ID
+func (r *DictionaryRepository) Summaries(ctx context.Context) ([]DictionarySummary, error) {
+	raws, err := r.mongo.FetchAllFromCollection(ctx, CollectionName)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, raw := range raws {
+		var kw KillWord
+		if err = kw.Decode(raw); err != nil {
+			return nil, undecodable(CollectionName, raw, err)
+		}
+		counts[kw.DictID]++
+	}
+	result := make([]DictionarySummary, 0, len(counts))
+	for id, count := range counts {
+		result = append(result, DictionarySummary{ID: id, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// Save writes a new word. Fails with ErrDuplicate if the dictionary already has it
+func (r *DictionaryRepository) Save(ctx context.Context, kw *KillWord) error {
+	return r.mongo.WriteCollection(ctx, CollectionName, kw)
+}
+
+// SaveMany writes a batch of new words. Words that can't be written are reported by their index in the batch, and
+// the rest are still written
+func (r *DictionaryRepository) SaveMany(ctx context.Context, words []*KillWord) (map[int]error, error) {
+	batch := make([]persistence.Persistable, len(words))
+	for i, kw := range words {
+		batch[i] = kw
+	}
+	return r.mongo.WriteManyToCollection(ctx, CollectionName, batch)
+}
+
+// Update replaces a word already saved, such as to change its category. Fails with ErrNotFound if it isn't
+func (r *DictionaryRepository) Update(ctx context.Context, kw *KillWord) error {
+	return r.mongo.UpdateCollection(ctx, CollectionName, kw)
+}
+
+// Delete removes a word. Fails with ErrNotFound if it isn't there
+func (r *DictionaryRepository) Delete(ctx context.Context, kw *KillWord) error {
+	return r.mongo.DeleteFromCollection(ctx, CollectionName, kw.GetID())
+}
+
+// copyFilter copies a query filter, so narrowing one query never changes another built from the same start
+func copyFilter(filter bson.M) bson.M {
+	result := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		result[k] = v
+	}
+	return result
+}
+
+// undecodable reports a record that doesn't decode, naming it by its _id when that much can be read
+func undecodable(coll string, raw []byte, err error) error {
+	if id, ok := bson.Raw(raw).Lookup("_id").StringValueOK(); ok {
+		return errorf(ErrUndecodable, "%s: can't decode %s: %w", coll, id, err)
+	}
+	return errorf(ErrUndecodable, "%s: can't decode record: %w", coll, err)
+}
@@ -0,0 +1,193 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	persistence "wordassassin/persistence"
+	"wordassassin/slack"
+	events "wordassassin/types/events"
+)
+
+func TestGameRepository(t *testing.T) {
+	ctx := context.Background()
+	m := persistence.NewMemorySession()
+	repo := NewGameRepository(m)
+	for _, id := range []string{"g1", "g2", "g3"} {
+		game := NewGameFromEvent(events.NewGameCreatedInline(id, "UdaStarter", "wordz", "MickJ"))
+		require.NoError(t, repo.Save(ctx, &game))
+	}
+	game := NewGameFromEvent(events.NewGameCreatedInline("g1", "UdaStarter", "wordz", "MickJ"))
+	require.True(t, errors.Is(repo.Save(ctx, &game), ErrDuplicate))
+
+	game.Status = Playing
+	require.NoError(t, repo.Update(ctx, &game))
+	found, err := repo.FindID(ctx, "g1")
+	require.NoError(t, err)
+	require.Equal(t, Playing, found.Status)
+
+	playing, err := repo.Find(ctx, AllGames().WithStatus(Playing))
+	require.NoError(t, err)
+	require.Len(t, playing, 1)
+	require.Equal(t, "g1", playing[0].GetID())
+
+	require.NoError(t, repo.Delete(ctx, "g2"))
+	all, err := repo.Find(ctx, AllGames())
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	_, err = repo.FindID(ctx, "g2")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestPlayerRepository_Queries(t *testing.T) {
+	ctx := context.Background()
+	m := persistence.NewMemorySession()
+	repo := NewPlayerRepository(m)
+	for _, p := range append(makePlayerList(t, "hunt", 3), makePlayerList(t, "other", 2)...) {
+		require.NoError(t, repo.Save(ctx, p))
+	}
+	victim, err := repo.FindID(ctx, "hunt+Uname1")
+	require.NoError(t, err)
+	victim.Status = Dead
+	require.NoError(t, repo.Update(ctx, victim))
+
+	inGame := AllPlayers().InGame("hunt")
+	found, err := repo.Find(ctx, inGame)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	found, err = repo.Find(ctx, inGame.Alive())
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	found, err = repo.Find(ctx, inGame)
+	require.NoError(t, err)
+	require.Len(t, found, 3, "Narrowing a query leaves the one it started from alone")
+	found, err = repo.Find(ctx, AllPlayers().Alive())
+	require.NoError(t, err)
+	require.Len(t, found, 4)
+
+	// The mock ignores filters, so the same query has to be applied on the way back
+	mm := persistence.NewMockMongoSession()
+	mm.CollectionResults = map[string][]persistence.Persistable{PlayersCollection: {victim, makePlayerList(t, "hunt", 1)[0], makePlayerList(t, "other", 1)[0]}}
+	found, err = NewPlayerRepository(mm).Find(ctx, inGame.Alive())
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "hunt+Uname0", found[0].GetID())
+}
+
+func TestEventRepository(t *testing.T) {
+	ctx := context.Background()
+	m := persistence.NewMemorySession()
+	repo := NewEventRepository(m)
+	created := events.NewGameCreatedInline("logged", "UdaStarter", "wordz", "MickJ")
+	added := events.NewPlayerAddedInline("logged", "UJoiner", "", "")
+	elsewhere := events.NewPlayerAddedInline("elsewhere", "UJoiner", "", "")
+	killed := events.NewKillReportedInline("logged", "UJoiner")
+	// Written out of order, and with a tie in time that only the game's Seq can settle
+	added.Stamp(created.TimeCreated.Add(time.Second), 1)
+	killed.Stamp(added.TimeCreated, 2)
+	elsewhere.Stamp(created.TimeCreated.Add(-time.Second), 1)
+	for _, ev := range []events.GameEvent{&killed, &added, &elsewhere, &created} {
+		require.NoError(t, repo.Save(ctx, ev))
+	}
+	require.True(t, errors.Is(repo.Save(ctx, &added), ErrDuplicate))
+
+	found, err := repo.Find(ctx, AllEvents())
+	require.NoError(t, err)
+	requireEventIDs(t, found, elsewhere.ID, created.ID, added.ID, killed.ID)
+	require.IsType(t, &events.GameCreatedEvent{}, found[1], "Events come back as their own types")
+	require.Equal(t, slack.SlackID("UdaStarter"), found[1].(*events.GameCreatedEvent).GameCreator)
+
+	// A clock that went back still leaves the game's events in Seq order
+	killed.Stamp(created.TimeCreated.Add(-time.Minute), 2)
+	require.NoError(t, repo.Delete(ctx, killed.ID))
+	require.NoError(t, repo.Save(ctx, &killed))
+	found, err = repo.Find(ctx, AllEvents().ForGame("logged"))
+	require.NoError(t, err)
+	requireEventIDs(t, found, created.ID, added.ID, killed.ID)
+
+	found, err = repo.Find(ctx, AllEvents().ForGame("logged"))
+	require.NoError(t, err)
+	requireEventIDs(t, found, created.ID, added.ID, killed.ID)
+	found, err = repo.Find(ctx, AllEvents().ForGame("logged").OfType("PlayerAddedEvent"))
+	require.NoError(t, err)
+	requireEventIDs(t, found, added.ID)
+
+	require.NoError(t, repo.Delete(ctx, killed.ID))
+	found, err = repo.Find(ctx, AllEvents().OfType("KillReportedEvent"))
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
+
+func TestDictionaryRepository(t *testing.T) {
+	ctx := context.Background()
+	m := persistence.NewMemorySession()
+	repo := NewDictionaryRepository(m)
+	var batch []*KillWord
+	for _, w := range []string{"zebra", "apple", "mango"} {
+		kw, err := NewKillWord("fruity", w)
+		require.NoError(t, err)
+		batch = append(batch, &kw)
+	}
+	failed, err := repo.SaveMany(ctx, batch)
+	require.NoError(t, err)
+	require.Empty(t, failed)
+	other, _ := NewKillWord("other", "other")
+	require.NoError(t, repo.Save(ctx, &other))
+
+	batch[2].Category = "fruit"
+	require.NoError(t, repo.Update(ctx, batch[2]))
+	words, err := repo.Find(ctx, "fruity")
+	require.NoError(t, err)
+	require.Len(t, words, 3)
+	require.Equal(t, "apple", words[0].Word, "Words come back sorted")
+	require.Equal(t, "fruit", words[1].Category)
+
+	require.NoError(t, repo.Delete(ctx, batch[0]))
+	summaries, err := repo.Summaries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []DictionarySummary{{ID: "fruity", Count: 2}, {ID: "other", Count: 1}}, summaries)
+}
+
+func TestRepository_Undecodable(t *testing.T) {
+	ctx := context.Background()
+	m := persistence.NewMemorySession()
+	// A record whose status is the wrong type for a game or a player
+	for _, coll := range []string{GamesCollection, PlayersCollection} {
+		require.NoError(t, m.WriteCollection(ctx, coll, mangledRecord{ID: "mangled", Status: []int{1}}))
+	}
+	require.NoError(t, m.WriteCollection(ctx, EventsCollection, mangledRecord{ID: "mystery", EventType: "MysteryEvent"}))
+
+	_, err := NewGameRepository(m).Find(ctx, AllGames())
+	require.True(t, errors.Is(err, ErrUndecodable), "Got %v", err)
+	require.Contains(t, err.Error(), "games: can't decode mangled")
+	_, err = NewGameRepository(m).FindID(ctx, "mangled")
+	require.True(t, errors.Is(err, ErrUndecodable), "Got %v", err)
+	_, err = NewPlayerRepository(m).Find(ctx, AllPlayers())
+	require.True(t, errors.Is(err, ErrUndecodable), "Got %v", err)
+	_, err = NewEventRepository(m).Find(ctx, AllEvents())
+	require.True(t, errors.Is(err, ErrUndecodable), "Got %v", err)
+	require.Contains(t, err.Error(), `no decoder for EventType "MysteryEvent"`)
+}
+
+/*** Helper functions ***/
+
+// mangledRecord is persisted in place of a real game, player or event, to check that it's turned away
+type mangledRecord struct {
+	ID        string `bson:"_id"`
+	Status    []int  `bson:"status,omitempty"`
+	EventType string `bson:"eventtype,omitempty"`
+}
+
+func (r mangledRecord) GetID() string { return r.ID }
+
+func requireEventIDs(t *testing.T, evs []events.GameEvent, ids ...string) {
+	got := make([]string, len(evs))
+	for i, ev := range evs {
+		got[i] = ev.GetID()
+	}
+	require.Equal(t, ids, got)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"time"
+
+	types "wordassassin/types"
+)
+
+// GameView is the public face of a game, as API clients see it. The passcode stays private
+type GameView struct {
+	ID             string     `json:"id"`
+	Creator        string     `json:"creator"`
+	KillDictionary string     `json:"killDictionary"`
+	Status         string     `json:"status"`
+	StartPlayers   int        `json:"startPlayers"`
+	RemainPlayers  int        `json:"remainPlayers"`
+	Winner         string     `json:"winner,omitempty"`
+	Channel        string     `json:"channel,omitempty"`
+	TimeCreated    time.Time  `json:"timeCreated"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	FinishTime     *time.Time `json:"finishTime,omitempty"`
+	QueuedCommands int        `json:"queuedCommands"`
+}
+
+// TargetAssignment is who a player is hunting, and the word that has to be said to take them out
+type TargetAssignment struct {
+	GameID     string `json:"gameId"`
+	SlackID    string `json:"slackId"`
+	TargetID   string `json:"targetId"`
+	TargetName string `json:"targetName"`
+	KillWord   string `json:"killWord"`
+}
+
+// newGameView builds the view of a game. Start and finish times only show once they've happened
+func newGameView(game *types.Game, queued int) (v GameView) {
+	v = GameView{
+		ID:             game.GetID(),
+		Creator:        game.GameCreator.ToString(),
+		KillDictionary: game.KillDictionary,
+		Status:         game.GetStatus(),
+		StartPlayers:   game.StartPlayers,
+		RemainPlayers:  game.RemainPlayers,
+		Winner:         game.Winner,
+		Channel:        game.Channel,
+		TimeCreated:    game.TimeCreated,
+		QueuedCommands: queued,
+	}
+	// A game aborted before it started has no start time to show
+	if game.Status != types.Starting && game.StartTime.After(time.Unix(0, 0)) {
+		start := game.StartTime
+		v.StartTime = &start
+	}
+	if game.Status == types.Finished || game.Status == types.Aborted {
+		finish := game.FinishTime
+		v.FinishTime = &finish
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	types "wordassassin/types"
+	events "wordassassin/types/events"
+)
+
+func TestNewGameView_Times(t *testing.T) {
+	started := time.Date(2112, time.November, 13, 16, 20, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		status     types.GameStatus
+		start      bool
+		showStart  bool
+		showFinish bool
+	}{
+		{"Starting", types.Starting, false, false, false},
+		{"Aborted while starting", types.Aborted, false, false, true},
+		{"Playing", types.Playing, true, true, false},
+		{"Finished", types.Finished, true, true, true},
+		{"Aborted while playing", types.Aborted, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := types.NewGameFromEvent(events.NewGameCreatedInline("timely", "UdaStarter", "wordz", "MickJ"))
+			if tt.start {
+				game.StartTime = started
+			}
+			if tt.showFinish {
+				game.FinishTime = started.Add(time.Hour)
+			}
+			game.Status = tt.status
+			view := newGameView(&game, 0)
+			require.Equal(t, tt.showStart, view.StartTime != nil, "StartTime shown")
+			require.Equal(t, tt.showFinish, view.FinishTime != nil, "FinishTime shown")
+			raw, err := json.Marshal(view)
+			require.NoError(t, err)
+			if !tt.showStart {
+				require.NotContains(t, string(raw), "startTime")
+			} else {
+				require.Equal(t, started, *view.StartTime)
+			}
+		})
+	}
+}